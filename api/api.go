@@ -0,0 +1,27 @@
+// Package api holds the wire contract shared by the service and its client: headers, error responses
+// and their stable codes. It must stay free of server code, the client SDK imports it
+package api
+
+import "context"
+
+// RequestIdHeader carries request id between the caller, the service and its logs
+const RequestIdHeader = "X-Request-ID"
+
+// APIKeyHeader carries static API key of the caller. Bearer tokens come in the Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// MediaJSON is the media type every endpoint supports
+const MediaJSON = "application/json"
+
+type requestIdKey struct{}
+
+// WithRequestId returns copy of ctx carrying request id
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// RequestId returns request id stored by WithRequestId
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -0,0 +1,43 @@
+package api
+
+import "fmt"
+
+// ProblemContentType is the media type of error responses, see RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// Stable error codes. Clients match on them, so existing codes must never change
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeValidation       = "validation_failed"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeReference        = "reference_violation"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeNotAcceptable    = "not_acceptable"
+	CodeInternal         = "internal_error"
+	CodeUnavailable      = "unavailable"
+)
+
+// FieldError describes a single invalid field of the request
+type FieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+func (f FieldError) Error() string {
+	return fmt.Sprintf("'%s' %s", f.Field, f.Detail)
+}
+
+// Problem is the error response body
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// keyPrefix marks API keys of the service, so leaked keys are easy to find by secret scanners
+const keyPrefix = "mcd_"
+
+// keyBytes is the entropy of generated keys. Keys are random, so a plain sha-256 is enough to store them
+const keyBytes = 32
+
+// GenerateKey returns a new API key and its hash. Only the hash is meant to be stored
+func GenerateKey() (key, hash string, err error) {
+	var b = make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashKey(key), nil
+}
+
+// HashKey returns hex encoded sha-256 of the key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
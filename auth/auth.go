@@ -0,0 +1,108 @@
+// Package auth authenticates callers of the service by static API keys and JWT bearer tokens
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"strings"
+)
+
+// ErrUnauthenticated means the credentials are missing, malformed, unknown or expired
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// authentication methods of Principal
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// namespaces of subjects. Roles are granted to namespaced subjects, so an api key named after the sub claim
+// of a token does not get the roles of the token and the other way round
+const (
+	subjectAPIKey = "apikey:"
+	subjectJWT    = "jwt:"
+)
+
+// APIKeySubject returns subject of the api key named name
+func APIKeySubject(name string) string {
+	return subjectAPIKey + name
+}
+
+// JWTSubject returns subject of tokens issued by iss with the sub claim sub
+func JWTSubject(iss, sub string) string {
+	return subjectJWT + iss + "/" + sub
+}
+
+// ValidSubject returns error in case subject is not namespaced by an authentication method
+func ValidSubject(subject string) error {
+	name, isKey := strings.CutPrefix(subject, subjectAPIKey)
+	token, isJWT := strings.CutPrefix(subject, subjectJWT)
+	if (isKey && name != "") || (isJWT && strings.Contains(token, "/") && !strings.HasSuffix(token, "/")) {
+		return nil
+	}
+	return fmt.Errorf("subject [%s] is expected to be %s<name> or %s<iss>/<sub>", subject, subjectAPIKey, subjectJWT)
+}
+
+// Principal is the authenticated caller. Subject is APIKeySubject of the key name for API keys
+// and JWTSubject of the iss and sub claims for tokens
+type Principal struct {
+	Subject string
+	Method  string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns principal stored by WithPrincipal. ok is false for anonymous requests
+func FromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator checks credentials of callers. Bearer tokens are rejected in case jwt is nil
+type Authenticator struct {
+	keys controllers.APIKeyRepo
+	jwt  *JWTVerifier
+}
+
+// New returns Authenticator of API keys stored in keys and tokens verified by jwt
+func New(keys controllers.APIKeyRepo, jwt *JWTVerifier) (*Authenticator, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("api key repo must be set")
+	}
+	return &Authenticator{keys: keys, jwt: jwt}, nil
+}
+
+// APIKey authenticates the caller by the key. Errors other than ErrUnauthenticated come from the repo
+func (a *Authenticator) APIKey(ctx context.Context, key string, l *slog.Logger) (Principal, error) {
+	if key == "" {
+		return Principal{}, fmt.Errorf("empty api key: %w", ErrUnauthenticated)
+	}
+	k, err := a.keys.APIKeyGetByHash(ctx, HashKey(key), l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return Principal{}, fmt.Errorf("unknown or revoked api key: %w", ErrUnauthenticated)
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{Subject: APIKeySubject(k.Name), Method: MethodAPIKey}, nil
+}
+
+// Bearer authenticates the caller by the JWT
+func (a *Authenticator) Bearer(token string) (Principal, error) {
+	if a.jwt == nil {
+		return Principal{}, fmt.Errorf("bearer tokens are not configured: %w", ErrUnauthenticated)
+	}
+	c, err := a.jwt.Verify(token)
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{Subject: JWTSubject(c.Issuer, c.Subject), Method: MethodJWT}, nil
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sign returns compact JWS of claims. key is a secret for HS256 and *rsa.PrivateKey for RS256
+func sign(t *testing.T, alg string, key any, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sum := sha256.Sum256([]byte(input))
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:]); err != nil {
+			t.Fatalf("failed to sign token: %s", err.Error())
+		}
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	type jwtTest struct {
+		Alg     string
+		Key     any
+		Claims  map[string]any
+		Token   string
+		Subject string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var now = time.Unix(1_700_000_000, 0)
+	var secret = []byte("0123456789abcdef0123456789abcdef")
+	var claims = func(edit func(map[string]any)) map[string]any {
+		c := map[string]any{"sub": "svc-1", "iss": "https://idp.local", "aud": "mis-catanddog", "exp": now.Unix() + 60}
+		if edit != nil {
+			edit(c)
+		}
+		return c
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %s", err.Error())
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pub, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("failed to parse rsa public key: %s", err.Error())
+	}
+
+	hs, err := NewJWTVerifier(JWTConfig{Alg: AlgHS256, Secret: secret, Issuer: "https://idp.local", Audience: "mis-catanddog", Leeway: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %s", err.Error())
+	}
+	rs, err := NewJWTVerifier(JWTConfig{Alg: AlgRS256, PublicKey: pub, Issuer: "https://idp.local", Audience: "mis-catanddog"})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %s", err.Error())
+	}
+	hs.now = func() time.Time { return now }
+	rs.now = func() time.Time { return now }
+
+	var arr = []jwtTest{
+		{Alg: AlgHS256, Key: secret, Claims: claims(nil), Subject: "svc-1", Message: "positive test [HS256] failed", Crit: true},
+		{Alg: AlgRS256, Key: rsaKey, Claims: claims(nil), Subject: "svc-1", Message: "positive test [RS256] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["aud"] = []string{"other", "mis-catanddog"} }), Subject: "svc-1", Message: "positive test [audience array] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["exp"] = now.Unix() - 3 }), Subject: "svc-1", Message: "positive test [expired within leeway] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["exp"] = now.Unix() - 10 }), Message: "negative test [expired] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { delete(c, "exp") }), Message: "negative test [no exp] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["nbf"] = now.Unix() + 60 }), Message: "negative test [not valid yet] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["iss"] = "https://evil.local" }), Message: "negative test [issuer] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { c["aud"] = "other" }), Message: "negative test [audience] failed", Crit: true},
+		{Alg: AlgHS256, Key: secret, Claims: claims(func(c map[string]any) { delete(c, "sub") }), Message: "negative test [no subject] failed", Crit: true},
+		{Alg: AlgHS256, Key: []byte("another secret of the same length"), Claims: claims(nil), Message: "negative test [wrong secret] failed", Crit: true},
+		{Alg: AlgRS256, Key: secret, Claims: claims(nil), Message: "negative test [HS256 token to RS256 verifier] failed", Crit: true},
+		{Alg: AlgHS256, Token: "abc.def", Message: "negative test [malformed] failed", Crit: true},
+		{Alg: AlgHS256, Token: base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.", Message: "negative test [alg none] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		var v = hs
+		if val.Alg == AlgRS256 {
+			v = rs
+		}
+		token := val.Token
+		if token == "" {
+			alg := val.Alg
+			if _, ok := val.Key.([]byte); ok {
+				alg = AlgHS256
+			}
+			token = sign(t, alg, val.Key, val.Claims)
+		}
+		c, err := v.Verify(token)
+		ok := err == nil && val.Subject != "" && c.Subject == val.Subject || err != nil && val.Subject == "" && errors.Is(err, ErrUnauthenticated)
+		if !ok {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %+v %v", val.Crit, val.Message, c, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+// keyRepo is APIKeyRepo of a single key
+type keyRepo struct {
+	key controllers.APIKey
+	err error
+}
+
+func (k keyRepo) APIKeyGetByHash(ctx context.Context, hash string, l *slog.Logger) (controllers.APIKey, error) {
+	if k.err != nil {
+		return controllers.APIKey{}, k.err
+	}
+	if hash != k.key.Hash {
+		return controllers.APIKey{}, fmt.Errorf("api_key: %w", controllers.ErrNotFound)
+	}
+	return k.key, nil
+}
+
+func (k keyRepo) APIKeyCreate(ctx context.Context, key controllers.APIKey, l *slog.Logger) (controllers.APIKey, error) {
+	return key, nil
+}
+
+func (k keyRepo) APIKeyRevoke(ctx context.Context, name string, l *slog.Logger) error {
+	return nil
+}
+
+func TestAuthenticator(t *testing.T) {
+	type authTest struct {
+		Repo    keyRepo
+		Key     string
+		Subject string
+		Err     error
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+	key, hash, err := GenerateKey()
+	if err != nil || !strings.HasPrefix(key, keyPrefix) || hash != HashKey(key) || strings.Contains(hash, key) {
+		t.Fatalf("GenerateKey: got %s %s %v", key, hash, err)
+	}
+	var repo = keyRepo{key: controllers.APIKey{Id: 1, Name: "billing", Hash: hash}}
+
+	var arr = []authTest{
+		{Repo: repo, Key: key, Subject: "apikey:billing", Message: "positive test [known key] failed", Crit: true},
+		{Repo: repo, Key: key + "x", Err: ErrUnauthenticated, Message: "negative test [unknown key] failed", Crit: true},
+		{Repo: repo, Key: "", Err: ErrUnauthenticated, Message: "negative test [empty key] failed", Crit: true},
+		{Repo: keyRepo{err: controllers.ErrUnavailable}, Key: key, Err: controllers.ErrUnavailable, Message: "negative test [repo unavailable] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		a, _ := New(val.Repo, nil)
+		p, err := a.APIKey(context.Background(), val.Key, l)
+		if !errors.Is(err, val.Err) || p.Subject != val.Subject || err == nil && p.Method != MethodAPIKey {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %+v %v", val.Crit, val.Message, p, err)
+		}
+	}
+	a, _ := New(repo, nil)
+	if _, err := a.Bearer("a.b.c"); !errors.Is(err, ErrUnauthenticated) {
+		fail = true
+		t.Logf("crit: true; negative test [bearer without jwt config] failed; got %v", err)
+	}
+
+	// a token subject named after the key is another subject
+	var secret = []byte("0123456789abcdef0123456789abcdef")
+	v, err := NewJWTVerifier(JWTConfig{Alg: AlgHS256, Secret: secret, Issuer: "https://idp.local", Audience: "mis-catanddog"})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %s", err.Error())
+	}
+	a, _ = New(repo, v)
+	token := sign(t, AlgHS256, secret, map[string]any{"sub": "billing", "iss": "https://idp.local", "aud": "mis-catanddog", "exp": time.Now().Unix() + 60})
+	if p, err := a.Bearer(token); err != nil || p.Subject != "jwt:https://idp.local/billing" || p.Method != MethodJWT {
+		fail = true
+		t.Logf("crit: true; positive test [bearer subject] failed; got %+v %v", p, err)
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestValidSubject(t *testing.T) {
+	type subjectTest struct {
+		Subject string
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []subjectTest{
+		{Subject: APIKeySubject("billing"), Message: "positive test [api key] failed", Crit: true},
+		{Subject: JWTSubject("https://idp.local", "svc-1"), Message: "positive test [token] failed", Crit: true},
+		{Subject: "billing", IsErr: true, Message: "negative test [no namespace] failed", Crit: true},
+		{Subject: "apikey:", IsErr: true, Message: "negative test [empty key name] failed", Crit: true},
+		{Subject: "jwt:svc-1", IsErr: true, Message: "negative test [token without issuer] failed", Crit: true},
+		{Subject: "jwt:https://idp.local/", IsErr: true, Message: "negative test [token without sub] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		err := ValidSubject(val.Subject)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
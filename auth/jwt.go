@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// hs256MinSecret is the shortest accepted HS256 secret, RFC 7518 requires a key of the hash size at least
+const hs256MinSecret = 32
+
+// JWTConfig configures verification of bearer tokens. Secret is used by HS256, PublicKey by RS256.
+// Leeway is the allowed clock skew of exp and nbf
+type JWTConfig struct {
+	Alg       string
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+	Issuer    string
+	Audience  string
+	Leeway    time.Duration
+}
+
+// audience is the aud claim, that is either a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// Claims are the registered claims the service relies on. Times are seconds since the epoch
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// JWTVerifier verifies signature and registered claims of compact JWS tokens
+type JWTVerifier struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+// NewJWTVerifier returns verifier of tokens signed with cfg.Alg. Issuer and audience are mandatory,
+// so tokens minted for other services are never accepted
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	switch cfg.Alg {
+	case AlgHS256:
+		if len(cfg.Secret) < hs256MinSecret {
+			return nil, fmt.Errorf("HS256 secret must be at least %d bytes", hs256MinSecret)
+		}
+	case AlgRS256:
+		if cfg.PublicKey == nil {
+			return nil, fmt.Errorf("RS256 requires a public key")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported jwt alg [%s]. Expected: %s, %s", cfg.Alg, AlgHS256, AlgRS256)
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("jwt issuer and audience must be set")
+	}
+	return &JWTVerifier{cfg: cfg, now: time.Now}, nil
+}
+
+// ParseRSAPublicKey reads PEM encoded PKIX or PKCS #1 public key or the key of a certificate
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block [%s]", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// Verify checks the signature and the claims of token. Every failure wraps ErrUnauthenticated
+func (v *JWTVerifier) Verify(token string) (Claims, error) {
+	var c Claims
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return c, fmt.Errorf("malformed token: %w", ErrUnauthenticated)
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return c, fmt.Errorf("malformed token header: %w", ErrUnauthenticated)
+	}
+	// alg of the token must match the configured one, otherwise an RS256 public key could be used as an HS256 secret
+	if header.Alg != v.cfg.Alg {
+		return c, fmt.Errorf("unexpected token alg [%s]: %w", header.Alg, ErrUnauthenticated)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return c, fmt.Errorf("malformed token signature: %w", ErrUnauthenticated)
+	}
+	if err := v.verifySignature(parts[0]+"."+parts[1], sig); err != nil {
+		return c, err
+	}
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return c, fmt.Errorf("malformed token claims: %w", ErrUnauthenticated)
+	}
+	return c, v.verifyClaims(c)
+}
+
+// verifySignature checks sig of the signing input
+func (v *JWTVerifier) verifySignature(input string, sig []byte) error {
+	sum := sha256.Sum256([]byte(input))
+	switch v.cfg.Alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, v.cfg.Secret)
+		mac.Write([]byte(input))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("invalid token signature: %w", ErrUnauthenticated)
+		}
+	case AlgRS256:
+		if err := rsa.VerifyPKCS1v15(v.cfg.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			return fmt.Errorf("invalid token signature: %w", ErrUnauthenticated)
+		}
+	}
+	return nil
+}
+
+// verifyClaims checks issuer, audience and validity period. exp is mandatory, tokens must not live forever
+func (v *JWTVerifier) verifyClaims(c Claims) error {
+	now := v.now()
+	if c.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("unexpected token issuer [%s]: %w", c.Issuer, ErrUnauthenticated)
+	}
+	if !slices.Contains(c.Audience, v.cfg.Audience) {
+		return fmt.Errorf("token is not issued for audience [%s]: %w", v.cfg.Audience, ErrUnauthenticated)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("token has no subject: %w", ErrUnauthenticated)
+	}
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("token has no expiration time: %w", ErrUnauthenticated)
+	}
+	if now.After(seconds(*c.ExpiresAt).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("token expired: %w", ErrUnauthenticated)
+	}
+	if c.NotBefore != nil && now.Before(seconds(*c.NotBefore).Add(-v.cfg.Leeway)) {
+		return fmt.Errorf("token is not valid yet: %w", ErrUnauthenticated)
+	}
+	return nil
+}
+
+// decodeSegment decodes base64url JSON segment of the token into v
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// seconds converts NumericDate of RFC 7519 to time
+func seconds(s float64) time.Time {
+	return time.Unix(0, int64(s*float64(time.Second)))
+}
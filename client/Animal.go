@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// animalPath is the url of animals
+const animalPath = "/animal"
+
+// related records returned inline with animals
+const (
+	ExpandOwner = "owner" // fills Animal.Owner
+	ExpandType  = "type"  // fills Animal.Type
+)
+
+// animalQuery returns q with expand values added
+func animalQuery(q url.Values, expand []string) url.Values {
+	if q == nil {
+		q = url.Values{}
+	}
+	for _, val := range expand {
+		q.Add("expand", val)
+	}
+	return q
+}
+
+// animalDocId returns query addressing a single animal
+func animalDocId(docId int) url.Values {
+	return url.Values{"doc_id": {strconv.Itoa(docId)}}
+}
+
+// AnimalGet returns animal with docId. expand lists related records to fill, see ExpandOwner and ExpandType
+func (c *Client) AnimalGet(ctx context.Context, docId int, expand ...string) (controllers.Animal, error) {
+	var result controllers.Animal
+	err := c.do(ctx, http.MethodGet, animalPath, animalQuery(animalDocId(docId), expand), nil, &result)
+	return result, err
+}
+
+// AnimalGetByOwner returns animals of the human with ownerDocId, see AnimalGet for expand
+func (c *Client) AnimalGetByOwner(ctx context.Context, ownerDocId int, expand ...string) ([]controllers.Animal, error) {
+	var result []controllers.Animal
+	q := url.Values{"owner_doc_id": {strconv.Itoa(ownerDocId)}}
+	err := c.do(ctx, http.MethodGet, animalPath, animalQuery(q, expand), nil, &result)
+	return result, err
+}
+
+// AnimalList returns a page of animals, see AnimalGet for expand
+func (c *Client) AnimalList(ctx context.Context, o ListOptions, expand ...string) (Page[controllers.Animal], error) {
+	var result Page[controllers.Animal]
+	err := c.do(ctx, http.MethodGet, animalPath, animalQuery(o.values(), expand), nil, &result)
+	return result, err
+}
+
+// AnimalCreate creates a and returns the stored record
+func (c *Client) AnimalCreate(ctx context.Context, a controllers.Animal) (controllers.Animal, error) {
+	var result controllers.Animal
+	err := c.do(ctx, http.MethodPost, animalPath, nil, a, &result)
+	return result, err
+}
+
+// AnimalUpdate replaces animal with doc id a.DocId
+func (c *Client) AnimalUpdate(ctx context.Context, a controllers.Animal) (controllers.Animal, error) {
+	var result controllers.Animal
+	err := c.do(ctx, http.MethodPut, animalPath, animalDocId(a.DocId), a, &result)
+	return result, err
+}
+
+// AnimalPatch sets non-empty fields of patch to animal with docId
+func (c *Client) AnimalPatch(ctx context.Context, docId int, patch controllers.Animal) (controllers.Animal, error) {
+	var result controllers.Animal
+	err := c.do(ctx, http.MethodPatch, animalPath, animalDocId(docId), patch, &result)
+	return result, err
+}
+
+// AnimalDelete deletes animal with docId
+func (c *Client) AnimalDelete(ctx context.Context, docId int) error {
+	return c.do(ctx, http.MethodDelete, animalPath, animalDocId(docId), nil, nil)
+}
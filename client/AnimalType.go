@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// animalTypePath is the url of animal types
+const animalTypePath = "/animal_type"
+
+// animalTypeGet looks up a single animal type by param
+func (c *Client) animalTypeGet(ctx context.Context, param string, val string) (controllers.AnimalType, error) {
+	var result []controllers.AnimalType
+	if err := c.do(ctx, http.MethodGet, animalTypePath, url.Values{param: {val}}, nil, &result); err != nil {
+		return controllers.AnimalType{}, err
+	}
+	if len(result) != 1 {
+		return controllers.AnimalType{}, fmt.Errorf("GET %s: single animal type expected, got %d", animalTypePath, len(result))
+	}
+	return result[0], nil
+}
+
+// animalTypeBatch looks up animal types by every value of param. Results follow the order of vals
+func (c *Client) animalTypeBatch(ctx context.Context, param string, vals []string) ([]Result[controllers.AnimalType], error) {
+	if len(vals) == 1 {
+		item, err := c.animalTypeGet(ctx, param, vals[0])
+		// a miss of a single value is reported as 404, just like a miss of a batch item
+		if err != nil && !errors.Is(err, controllers.ErrNotFound) {
+			return nil, err
+		}
+		return []Result[controllers.AnimalType]{{Item: item, Err: err}}, nil
+	}
+
+	var items []batchItem[controllers.AnimalType]
+	if err := c.do(ctx, http.MethodGet, animalTypePath, url.Values{param: vals}, nil, &items); err != nil {
+		return nil, err
+	}
+	return batchResults(items), nil
+}
+
+// AnimalTypeGet returns animal type with id
+func (c *Client) AnimalTypeGet(ctx context.Context, id int) (controllers.AnimalType, error) {
+	return c.animalTypeGet(ctx, "id", strconv.Itoa(id))
+}
+
+// AnimalTypeGetByType returns animal type named typ
+func (c *Client) AnimalTypeGetByType(ctx context.Context, typ string) (controllers.AnimalType, error) {
+	return c.animalTypeGet(ctx, "type", typ)
+}
+
+// AnimalTypeGetByIds looks up animal types with a single request. Results follow the order of ids, misses are
+// reported per item. Returns error in case the whole request failed
+func (c *Client) AnimalTypeGetByIds(ctx context.Context, ids []int) ([]Result[controllers.AnimalType], error) {
+	var vals = make([]string, 0, len(ids))
+	for _, val := range ids {
+		vals = append(vals, strconv.Itoa(val))
+	}
+	return c.animalTypeBatch(ctx, "id", vals)
+}
+
+// AnimalTypeGetByTypes looks up animal types by names with a single request, see AnimalTypeGetByIds
+func (c *Client) AnimalTypeGetByTypes(ctx context.Context, types []string) ([]Result[controllers.AnimalType], error) {
+	return c.animalTypeBatch(ctx, "type", types)
+}
+
+// AnimalTypeList returns a page of animal types
+func (c *Client) AnimalTypeList(ctx context.Context, o ListOptions) (Page[controllers.AnimalType], error) {
+	var result Page[controllers.AnimalType]
+	err := c.do(ctx, http.MethodGet, animalTypePath, o.values(), nil, &result)
+	return result, err
+}
+
+// AnimalTypeCreate creates a and returns the stored record
+func (c *Client) AnimalTypeCreate(ctx context.Context, a controllers.AnimalType) (controllers.AnimalType, error) {
+	var result controllers.AnimalType
+	err := c.do(ctx, http.MethodPost, animalTypePath, nil, a, &result)
+	return result, err
+}
+
+// AnimalTypeUpdate replaces animal type with id a.Id
+func (c *Client) AnimalTypeUpdate(ctx context.Context, a controllers.AnimalType) (controllers.AnimalType, error) {
+	var result controllers.AnimalType
+	err := c.do(ctx, http.MethodPut, animalTypePath, url.Values{"id": {strconv.Itoa(a.Id)}}, a, &result)
+	return result, err
+}
+
+// AnimalTypeDelete deletes animal type with id
+func (c *Client) AnimalTypeDelete(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, animalTypePath, url.Values{"id": {strconv.Itoa(id)}}, nil, nil)
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// docTypePath is the url of doc types
+const docTypePath = "/doc_type"
+
+// docTypeGet looks up a single doc type by param
+func (c *Client) docTypeGet(ctx context.Context, param string, val string) (controllers.DocType, error) {
+	var result []controllers.DocType
+	if err := c.do(ctx, http.MethodGet, docTypePath, url.Values{param: {val}}, nil, &result); err != nil {
+		return controllers.DocType{}, err
+	}
+	if len(result) != 1 {
+		return controllers.DocType{}, fmt.Errorf("GET %s: single doc type expected, got %d", docTypePath, len(result))
+	}
+	return result[0], nil
+}
+
+// docTypeBatch looks up doc types by every value of param. Results follow the order of vals
+func (c *Client) docTypeBatch(ctx context.Context, param string, vals []string) ([]Result[controllers.DocType], error) {
+	if len(vals) == 1 {
+		item, err := c.docTypeGet(ctx, param, vals[0])
+		// a miss of a single value is reported as 404, just like a miss of a batch item
+		if err != nil && !errors.Is(err, controllers.ErrNotFound) {
+			return nil, err
+		}
+		return []Result[controllers.DocType]{{Item: item, Err: err}}, nil
+	}
+
+	var items []batchItem[controllers.DocType]
+	if err := c.do(ctx, http.MethodGet, docTypePath, url.Values{param: vals}, nil, &items); err != nil {
+		return nil, err
+	}
+	return batchResults(items), nil
+}
+
+// DocTypeGet returns doc type with id
+func (c *Client) DocTypeGet(ctx context.Context, id int) (controllers.DocType, error) {
+	return c.docTypeGet(ctx, "id", strconv.Itoa(id))
+}
+
+// DocTypeGetByDoc returns doc type named doc
+func (c *Client) DocTypeGetByDoc(ctx context.Context, doc string) (controllers.DocType, error) {
+	return c.docTypeGet(ctx, "doc", doc)
+}
+
+// DocTypeGetByIds looks up doc types with a single request. Results follow the order of ids, misses are
+// reported per item. Returns error in case the whole request failed
+func (c *Client) DocTypeGetByIds(ctx context.Context, ids []int) ([]Result[controllers.DocType], error) {
+	var vals = make([]string, 0, len(ids))
+	for _, val := range ids {
+		vals = append(vals, strconv.Itoa(val))
+	}
+	return c.docTypeBatch(ctx, "id", vals)
+}
+
+// DocTypeGetByDocs looks up doc types by names with a single request, see DocTypeGetByIds
+func (c *Client) DocTypeGetByDocs(ctx context.Context, docs []string) ([]Result[controllers.DocType], error) {
+	return c.docTypeBatch(ctx, "doc", docs)
+}
+
+// DocTypeList returns a page of doc types
+func (c *Client) DocTypeList(ctx context.Context, o ListOptions) (Page[controllers.DocType], error) {
+	var result Page[controllers.DocType]
+	err := c.do(ctx, http.MethodGet, docTypePath, o.values(), nil, &result)
+	return result, err
+}
+
+// DocTypeCreate creates d and returns the stored record
+func (c *Client) DocTypeCreate(ctx context.Context, d controllers.DocType) (controllers.DocType, error) {
+	var result controllers.DocType
+	err := c.do(ctx, http.MethodPost, docTypePath, nil, d, &result)
+	return result, err
+}
+
+// DocTypeUpdate replaces doc type with id d.Id
+func (c *Client) DocTypeUpdate(ctx context.Context, d controllers.DocType) (controllers.DocType, error) {
+	var result controllers.DocType
+	err := c.do(ctx, http.MethodPut, docTypePath, url.Values{"id": {strconv.Itoa(d.Id)}}, d, &result)
+	return result, err
+}
+
+// DocTypeDelete deletes doc type with id
+func (c *Client) DocTypeDelete(ctx context.Context, id int) error {
+	err := c.do(ctx, http.MethodDelete, docTypePath, url.Values{"id": {strconv.Itoa(id)}}, nil, nil)
+	return err
+}
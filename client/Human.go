@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// humanPath is the url of humans
+const humanPath = "/human"
+
+// humanDocId returns query addressing a single human
+func humanDocId(docId int) url.Values {
+	return url.Values{"doc_id": {strconv.Itoa(docId)}}
+}
+
+// HumanGet returns human with docId
+func (c *Client) HumanGet(ctx context.Context, docId int) (controllers.Human, error) {
+	var result controllers.Human
+	err := c.do(ctx, http.MethodGet, humanPath, humanDocId(docId), nil, &result)
+	return result, err
+}
+
+// HumanSearch returns humans matching f. Zero value fields are ignored, names are matched by prefix
+func (c *Client) HumanSearch(ctx context.Context, f controllers.HumanFilter) ([]controllers.Human, error) {
+	var result []controllers.Human
+	var q = url.Values{}
+	if f.DocType != 0 {
+		q.Set("doc_type", strconv.Itoa(f.DocType))
+	}
+	if f.FirstName != "" {
+		q.Set("first_name", f.FirstName)
+	}
+	if f.LastName != "" {
+		q.Set("last_name", f.LastName)
+	}
+	if f.BirthDate != "" {
+		q.Set("birth_date", f.BirthDate)
+	}
+	err := c.do(ctx, http.MethodGet, humanPath, q, nil, &result)
+	return result, err
+}
+
+// HumanList returns a page of humans
+func (c *Client) HumanList(ctx context.Context, o ListOptions) (Page[controllers.Human], error) {
+	var result Page[controllers.Human]
+	err := c.do(ctx, http.MethodGet, humanPath, o.values(), nil, &result)
+	return result, err
+}
+
+// HumanCreate creates h and returns the stored record
+func (c *Client) HumanCreate(ctx context.Context, h controllers.Human) (controllers.Human, error) {
+	var result controllers.Human
+	err := c.do(ctx, http.MethodPost, humanPath, nil, h, &result)
+	return result, err
+}
+
+// HumanUpdate replaces human with doc id h.DocId
+func (c *Client) HumanUpdate(ctx context.Context, h controllers.Human) (controllers.Human, error) {
+	var result controllers.Human
+	err := c.do(ctx, http.MethodPut, humanPath, humanDocId(h.DocId), h, &result)
+	return result, err
+}
+
+// HumanPatch sets non-empty fields of patch to human with docId
+func (c *Client) HumanPatch(ctx context.Context, docId int, patch controllers.Human) (controllers.Human, error) {
+	var result controllers.Human
+	err := c.do(ctx, http.MethodPatch, humanPath, humanDocId(docId), patch, &result)
+	return result, err
+}
+
+// HumanDelete deletes human with docId
+func (c *Client) HumanDelete(ctx context.Context, docId int) error {
+	return c.do(ctx, http.MethodDelete, humanPath, humanDocId(docId), nil, nil)
+}
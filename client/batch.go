@@ -0,0 +1,29 @@
+package client
+
+import "mis-catanddog/api"
+
+// Result is the outcome of a single lookup of a batch. Err is *Error in case the lookup failed
+type Result[T any] struct {
+	Item T
+	Err  error
+}
+
+// batchItem is a single item of http.StatusMultiStatus response, see handlers.BatchItem
+type batchItem[T any] struct {
+	Status  int
+	Item    T
+	Problem *api.Problem
+}
+
+// batchResults converts items of http.StatusMultiStatus response to results
+func batchResults[T any](items []batchItem[T]) []Result[T] {
+	var result = make([]Result[T], 0, len(items))
+	for _, val := range items {
+		if val.Problem != nil {
+			result = append(result, Result[T]{Err: problemError(*val.Problem)})
+			continue
+		}
+		result = append(result, Result[T]{Item: val.Item})
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+// Package client is the Go client of the service. Methods are typed with the controllers structs
+// and report API errors as *Error
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"mis-catanddog/api"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// defaults of Config
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultRetryWait = 100 * time.Millisecond
+)
+
+// responseMax limits the response body read by the client
+const responseMax = 16 << 20 // 16Mb
+
+// retryStatuses are responses worth another attempt, the request has not been processed
+var retryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// idempotentMethods are retried, repeating them does not change the outcome
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete}
+
+// Config configures Client. Zero Timeout and RetryWait take defaults
+type Config struct {
+	BaseURL    string        // scheme and host of the service, optionally followed by a path prefix
+	Timeout    time.Duration // limits a single attempt
+	Retries    int           // extra attempts of idempotent requests after network errors and 502, 503, 504 responses
+	RetryWait  time.Duration // pause before the first retry, doubled by every next one. Retry-After of the response takes precedence
+	HTTPClient *http.Client  // http.DefaultClient in case it is nil
+	APIKey     string        // sent as X-API-Key. Takes precedence over Token
+	Token      string        // JWT sent as the bearer token
+}
+
+// Client calls the service. It is safe for concurrent use
+type Client struct {
+	base *url.URL
+	cfg  Config
+	hc   *http.Client
+}
+
+// New returns Client configured with cfg. Returns error in case BaseURL is not an absolute http(s) URL
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base url: %w", err)
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("base url [%s] must be an absolute http or https url", cfg.BaseURL)
+	}
+	if cfg.Retries < 0 {
+		return nil, fmt.Errorf("retries must not be negative, got %d", cfg.Retries)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RetryWait == 0 {
+		cfg.RetryWait = defaultRetryWait
+	}
+
+	var c = &Client{base: base, cfg: cfg, hc: cfg.HTTPClient}
+	if c.hc == nil {
+		c.hc = http.DefaultClient
+	}
+	return c, nil
+}
+
+type requestIdKey struct{}
+
+// WithRequestId returns ctx making every call made with it send id as X-Request-ID.
+// Calls made within a request of a service using handlers.WithRequestId propagate its id without it
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// requestId returns id to send with the call. A new one is generated in case ctx carries none,
+// so every attempt of the call shares the same id
+func requestId(ctx context.Context) string {
+	if id, _ := ctx.Value(requestIdKey{}).(string); id != "" {
+		return id
+	}
+	if id := api.RequestId(ctx); id != "" {
+		return id
+	}
+	return uuid.NewString()
+}
+
+// response is the outcome of a single attempt
+type response struct {
+	status     int
+	header     http.Header
+	body       []byte
+	retryAfter time.Duration
+}
+
+// attempt sends a single request, the body is read before the attempt times out
+func (c *Client) attempt(ctx context.Context, method string, u string, body []byte, id string) (response, error) {
+	var result response
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Accept", api.MediaJSON)
+	req.Header.Set(api.RequestIdHeader, id)
+	switch {
+	case c.cfg.APIKey != "":
+		req.Header.Set(api.APIKeyHeader, c.cfg.APIKey)
+	case c.cfg.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", api.MediaJSON)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	result.status, result.header = resp.StatusCode, resp.Header
+	if result.body, err = io.ReadAll(io.LimitReader(resp.Body, responseMax)); err != nil {
+		return result, err
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		result.retryAfter = time.Duration(secs) * time.Second
+	}
+	return result, nil
+}
+
+// do calls method on path with query q and JSON encoded body, nil means no body. Responses with status
+// 400 and above are returned as *Error, other bodies are decoded into result unless it is nil
+func (c *Client) do(ctx context.Context, method string, path string, q url.Values, body any, result any) error {
+	var raw []byte
+	var err error
+
+	if body != nil {
+		if raw, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("cannot encode request body: %w", err)
+		}
+	}
+	u := c.base.JoinPath(path)
+	u.RawQuery = q.Encode()
+	id := requestId(ctx)
+
+	var resp response
+	for attempt := 0; ; attempt++ {
+		resp, err = c.attempt(ctx, method, u.String(), raw, id)
+		retry := err != nil || slices.Contains(retryStatuses, resp.status)
+		if !retry || attempt >= c.cfg.Retries || !slices.Contains(idempotentMethods, method) || ctx.Err() != nil {
+			break
+		}
+
+		wait := c.cfg.RetryWait << attempt
+		if resp.retryAfter > 0 {
+			wait = resp.retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s: %w", method, u.Path, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, u.Path, err)
+	}
+
+	if resp.status >= http.StatusBadRequest {
+		return newError(resp)
+	}
+	if result != nil && len(resp.body) > 0 {
+		if err := json.Unmarshal(resp.body, result); err != nil {
+			return fmt.Errorf("%s %s: cannot decode responce: %w", method, u.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/handlers/routes"
+	"mis-catanddog/repos/migrate"
+	"mis-catanddog/repos/sqlite3"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer runs the real router backed by a migrated sqlite db and returns API key accepted by it.
+// wrap is applied to the router in case it is set
+func testServer(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, string) {
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &sqlite3.SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	t.Cleanup(db.Close)
+	if _, err := migrate.Up(context.Background(), db, l); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate api key: %s", err.Error())
+	}
+	if _, err := db.APIKeyCreate(context.Background(), controllers.APIKey{Name: "client test", Hash: hash}, l); err != nil {
+		t.Fatalf("failed to create api key: %s", err.Error())
+	}
+	if err := db.RoleGrant(context.Background(), auth.APIKeySubject("client test"), "admin", l); err != nil {
+		t.Fatalf("failed to grant role: %s", err.Error())
+	}
+	authn, err := auth.New(db, nil)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %s", err.Error())
+	}
+
+	health, err := handlers.NewHealthHandler(db, "sqlite", time.Second, l)
+	if err != nil {
+		t.Fatalf("failed to create probes: %s", err.Error())
+	}
+	mux := http.NewServeMux()
+	rt, err := routes.Register(mux, db, health, l)
+	if err != nil {
+		t.Fatalf("failed to register handlers: %s", err.Error())
+	}
+	var h = handlers.Chain(mux, handlers.WithRequestId(l), handlers.WithAccessLog(l), handlers.WithAuth(authn, rt.IsPublic, l))
+	if wrap != nil {
+		h = wrap(h)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv, key
+}
+
+func TestNew(t *testing.T) {
+	type newTest struct {
+		Cfg     Config
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var arr = []newTest{
+		{Cfg: Config{BaseURL: "http://localhost:8080"}, Message: "positive test [http] failed", Crit: true},
+		{Cfg: Config{BaseURL: "https://example.com/api", Retries: 3}, Message: "positive test [https with prefix] failed", Crit: true},
+		{Cfg: Config{BaseURL: "localhost:8080"}, IsErr: true, Message: "negative test [no scheme] failed", Crit: true},
+		{Cfg: Config{BaseURL: "ftp://example.com"}, IsErr: true, Message: "negative test [scheme] failed", Crit: true},
+		{Cfg: Config{BaseURL: "http://"}, IsErr: true, Message: "negative test [no host] failed", Crit: true},
+		{Cfg: Config{BaseURL: "http://localhost", Retries: -1}, IsErr: true, Message: "negative test [negative retries] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		_, err := New(val.Cfg)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestClient(t *testing.T) {
+	type callTest struct {
+		Call    func(ctx context.Context) error
+		Err     error
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var ctx = context.Background()
+	srv, key := testServer(t, nil)
+	c, err := New(Config{BaseURL: srv.URL, APIKey: key})
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err.Error())
+	}
+	anonymous, _ := New(Config{BaseURL: srv.URL})
+	forged, _ := New(Config{BaseURL: srv.URL, Token: "a.b.c"})
+
+	var passport, cat controllers.DocType
+	var dog controllers.AnimalType
+	var human = controllers.Human{DocId: 10, FirstName: "Ann", LastName: "Lee", BirthDate: "1990-01-02"}
+	var animal = controllers.Animal{DocId: 20, Name: "Rex", BirthDate: "2020-03-04", Breed: "husky", OwnerDocId: 10}
+
+	// steps run in order, later ones rely on records created by the earlier ones
+	var arr = []callTest{
+		{
+			Call: func(ctx context.Context) error {
+				_, err := anonymous.HumanList(ctx, ListOptions{})
+				return err
+			},
+			Err:     ErrUnauthorized,
+			Message: "negative test [anonymous call] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := forged.HumanList(ctx, ListOptions{})
+				return err
+			},
+			Err:     ErrUnauthorized,
+			Message: "negative test [token without jwt config] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) (err error) {
+				if passport, err = c.DocTypeCreate(ctx, controllers.DocType{Doc: "passport"}); err == nil && passport.Id == 0 {
+					err = fmt.Errorf("created doc type has no id")
+				}
+				return err
+			},
+			Message: "positive test [DocTypeCreate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) (err error) {
+				cat, err = c.DocTypeCreate(ctx, controllers.DocType{Doc: "cat id"})
+				return err
+			},
+			Message: "positive test [second DocTypeCreate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := c.DocTypeCreate(ctx, controllers.DocType{Doc: "passport"})
+				return err
+			},
+			Err:     controllers.ErrAlreadyExists,
+			Message: "negative test [DocTypeCreate duplicate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				d, err := c.DocTypeGet(ctx, passport.Id)
+				if err == nil && d != passport {
+					err = fmt.Errorf("got %+v", d)
+				}
+				return err
+			},
+			Message: "positive test [DocTypeGet] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := c.DocTypeGetByDoc(ctx, "visa")
+				return err
+			},
+			Err:     controllers.ErrNotFound,
+			Message: "negative test [DocTypeGetByDoc miss] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.DocTypeGetByIds(ctx, []int{cat.Id, 999, passport.Id})
+				if err == nil && (len(result) != 3 || result[0].Item != cat || !errors.Is(result[1].Err, controllers.ErrNotFound) || result[2].Item != passport) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [DocTypeGetByIds batch] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.DocTypeGetByDocs(ctx, []string{"visa"})
+				if err == nil && (len(result) != 1 || !errors.Is(result[0].Err, controllers.ErrNotFound)) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [DocTypeGetByDocs single miss] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				page, err := c.DocTypeList(ctx, ListOptions{Limit: 1, Sort: "-doc"})
+				if err != nil {
+					return err
+				}
+				if len(page.Items) != 1 || page.Items[0] != passport || page.NextCursor == "" {
+					return fmt.Errorf("first page: got %+v", page)
+				}
+				page, err = c.DocTypeList(ctx, ListOptions{Limit: 1, Sort: "-doc", Cursor: page.NextCursor})
+				if err == nil && (len(page.Items) != 1 || page.Items[0] != cat || page.NextCursor != "") {
+					err = fmt.Errorf("second page: got %+v", page)
+				}
+				return err
+			},
+			Message: "positive test [DocTypeList pages] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := c.DocTypeList(ctx, ListOptions{Sort: "color"})
+				return err
+			},
+			Err:     ErrInvalid,
+			Message: "negative test [DocTypeList unknown sort] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) (err error) {
+				if dog, err = c.AnimalTypeCreate(ctx, controllers.AnimalType{Type: "dog"}); err == nil {
+					dog, err = c.AnimalTypeGetByType(ctx, "dog")
+				}
+				return err
+			},
+			Message: "positive test [AnimalTypeCreate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := c.AnimalTypeGet(ctx, 999)
+				return err
+			},
+			Err:     controllers.ErrNotFound,
+			Message: "negative test [AnimalTypeGet miss] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.AnimalTypeGetByIds(ctx, []int{999, dog.Id})
+				if err == nil && (len(result) != 2 || !errors.Is(result[0].Err, controllers.ErrNotFound) || result[1].Item != dog) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [AnimalTypeGetByIds batch] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.AnimalTypeGetByTypes(ctx, []string{"dog"})
+				if err == nil && (len(result) != 1 || result[0].Item != dog) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [AnimalTypeGetByTypes single] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				_, err := c.HumanCreate(ctx, controllers.Human{DocId: 10, DocType: 999, FirstName: "Ann", LastName: "Lee", BirthDate: "1990-01-02"})
+				var e *Error
+				if errors.As(err, &e) && (len(e.Problem.Errors) != 1 || e.Problem.Errors[0].Field != "DocType" || e.Problem.RequestId == "") {
+					return fmt.Errorf("got problem %+v", e.Problem)
+				}
+				return err
+			},
+			Err:     ErrInvalid,
+			Message: "negative test [HumanCreate dangling doc type] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) (err error) {
+				human.DocType = passport.Id
+				human, err = c.HumanCreate(ctx, human)
+				return err
+			},
+			Message: "positive test [HumanCreate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				err := c.DocTypeDelete(ctx, passport.Id)
+				if errors.Is(err, controllers.ErrAlreadyExists) {
+					return fmt.Errorf("reference violation reported as already exists: %w", err)
+				}
+				return err
+			},
+			Err:     controllers.ErrReference,
+			Message: "negative test [DocTypeDelete referenced] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				h, err := c.HumanPatch(ctx, human.DocId, controllers.Human{FirstName: "Anna"})
+				if err == nil && (h.FirstName != "Anna" || h.LastName != human.LastName) {
+					err = fmt.Errorf("got %+v", h)
+				}
+				return err
+			},
+			Message: "positive test [HumanPatch] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.HumanSearch(ctx, controllers.HumanFilter{FirstName: "An"})
+				if err == nil && (len(result) != 1 || result[0].DocId != human.DocId) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [HumanSearch] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) (err error) {
+				animal.DocType, animal.AnimalType = cat.Id, dog.Id
+				animal, err = c.AnimalCreate(ctx, animal)
+				return err
+			},
+			Message: "positive test [AnimalCreate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				a, err := c.AnimalGet(ctx, animal.DocId, ExpandOwner, ExpandType)
+				if err == nil && (a.Owner == nil || a.Owner.FirstName != "Anna" || a.Type == nil || a.Type.Type != "dog") {
+					err = fmt.Errorf("got %+v", a)
+				}
+				return err
+			},
+			Message: "positive test [AnimalGet expanded] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				result, err := c.AnimalGetByOwner(ctx, human.DocId)
+				if err == nil && (len(result) != 1 || result[0].Owner != nil) {
+					err = fmt.Errorf("got %+v", result)
+				}
+				return err
+			},
+			Message: "positive test [AnimalGetByOwner] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				page, err := c.AnimalList(ctx, ListOptions{Filters: []controllers.Filter{{Field: "name", Op: controllers.OpLike, Value: "R*"}}}, ExpandOwner)
+				if err == nil && (len(page.Items) != 1 || page.Items[0].Owner == nil) {
+					err = fmt.Errorf("got %+v", page)
+				}
+				return err
+			},
+			Message: "positive test [AnimalList filtered] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				animal.Breed = "malamute"
+				a, err := c.AnimalUpdate(ctx, animal)
+				if err == nil && a.Breed != "malamute" {
+					err = fmt.Errorf("got %+v", a)
+				}
+				return err
+			},
+			Message: "positive test [AnimalUpdate] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				if err := c.AnimalDelete(ctx, animal.DocId); err != nil {
+					return err
+				}
+				_, err := c.AnimalGet(ctx, animal.DocId)
+				return err
+			},
+			Err:     controllers.ErrNotFound,
+			Message: "positive test [AnimalDelete] failed", Crit: true,
+		},
+		{
+			Call: func(ctx context.Context) error {
+				return c.HumanDelete(ctx, 999)
+			},
+			Err:     controllers.ErrNotFound,
+			Message: "negative test [HumanDelete miss] failed", Crit: true,
+		},
+	}
+
+	for _, val := range arr {
+		err := val.Call(ctx)
+		if (val.Err == nil && err != nil) || (val.Err != nil && !errors.Is(err, val.Err)) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	type retryTest struct {
+		Method   string
+		Failures int
+		Retries  int
+		Attempts int
+		IsErr    bool
+		Message  string
+		Crit     bool
+	}
+
+	var fail bool
+	var mu sync.Mutex
+	var failures, attempts int
+	var ids []string
+
+	// the first failures attempts get 503 the way an overloaded proxy would answer
+	srv, key := testServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			attempts++
+			ids = append(ids, r.Header.Get(api.RequestIdHeader))
+			failed := attempts <= failures
+			mu.Unlock()
+			if failed {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var arr = []retryTest{
+		{Method: http.MethodGet, Failures: 2, Retries: 2, Attempts: 3, Message: "positive test [GET recovers] failed", Crit: true},
+		{Method: http.MethodPost, Failures: 0, Retries: 2, Attempts: 1, Message: "positive test [POST] failed", Crit: true},
+		{Method: http.MethodGet, Failures: 3, Retries: 2, Attempts: 3, IsErr: true, Message: "negative test [GET retries exhausted] failed", Crit: true},
+		{Method: http.MethodGet, Failures: 1, Retries: 0, Attempts: 1, IsErr: true, Message: "negative test [no retries] failed", Crit: true},
+		{Method: http.MethodPost, Failures: 1, Retries: 2, Attempts: 1, IsErr: true, Message: "negative test [POST is not retried] failed", Crit: true},
+	}
+
+	for i, val := range arr {
+		var err error
+		mu.Lock()
+		failures, attempts, ids = val.Failures, 0, nil
+		mu.Unlock()
+
+		c, _ := New(Config{BaseURL: srv.URL, Retries: val.Retries, RetryWait: time.Millisecond, APIKey: key})
+		ctx := WithRequestId(context.Background(), fmt.Sprintf("retry-%d", i))
+		if val.Method == http.MethodGet {
+			_, err = c.DocTypeList(ctx, ListOptions{})
+		} else {
+			_, err = c.DocTypeCreate(ctx, controllers.DocType{Doc: fmt.Sprintf("doc %d", i)})
+		}
+
+		var e *Error
+		switch {
+		case (err != nil) != val.IsErr:
+		case err != nil && (!errors.As(err, &e) || e.Status != http.StatusServiceUnavailable):
+		case attempts != val.Attempts:
+		case strings.Count(strings.Join(ids, ","), fmt.Sprintf("retry-%d", i)) != attempts:
+		default:
+			continue
+		}
+		if val.Crit {
+			fail = true
+		}
+		t.Logf("crit: %t; %s; got %v after %d attempts with ids %v", val.Crit, val.Message, err, attempts, ids)
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
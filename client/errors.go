@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+)
+
+// ErrInvalid matches errors of requests the service rejected as malformed or invalid
+var ErrInvalid = errors.New("invalid request")
+
+// ErrUnauthorized matches errors of requests the service rejected for missing or invalid credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Error is an error response of the service. Problem.Code is the stable error code, Problem.Errors
+// lists invalid fields. errors.Is matches it with controllers.ErrNotFound, controllers.ErrAlreadyExists,
+// controllers.ErrReference, controllers.ErrUnavailable, controllers.ErrForbidden, ErrInvalid
+// and ErrUnauthorized by the code
+type Error struct {
+	Status  int
+	Problem api.Problem
+}
+
+func (e *Error) Error() string {
+	if e.Problem.Detail == "" {
+		return fmt.Sprintf("%d %s", e.Status, e.Problem.Code)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Problem.Code, e.Problem.Detail)
+}
+
+// Is maps the error code to errors of the controllers
+func (e *Error) Is(target error) bool {
+	switch target {
+	case controllers.ErrNotFound:
+		return e.Problem.Code == api.CodeNotFound
+	case controllers.ErrAlreadyExists:
+		return e.Problem.Code == api.CodeConflict
+	case controllers.ErrReference:
+		return e.Problem.Code == api.CodeReference
+	case controllers.ErrUnavailable:
+		return e.Problem.Code == api.CodeUnavailable
+	case controllers.ErrForbidden:
+		return e.Problem.Code == api.CodeForbidden
+	case ErrInvalid:
+		return e.Problem.Code == api.CodeBadRequest || e.Problem.Code == api.CodeValidation
+	case ErrUnauthorized:
+		return e.Problem.Code == api.CodeUnauthorized
+	}
+	return false
+}
+
+// newError builds Error of the response. Responses that do not carry a problem, like those of proxies,
+// keep the status text as the detail
+func newError(resp response) *Error {
+	var e = &Error{Status: resp.status}
+	mt, _, _ := mime.ParseMediaType(resp.header.Get("Content-Type"))
+	if mt != api.ProblemContentType || json.Unmarshal(resp.body, &e.Problem) != nil {
+		e.Problem = api.Problem{Status: resp.status, Title: http.StatusText(resp.status), Detail: http.StatusText(resp.status)}
+	}
+	if e.Problem.RequestId == "" {
+		e.Problem.RequestId = resp.header.Get(api.RequestIdHeader)
+	}
+	return e
+}
+
+// problemError returns Error of a problem reported inside a successful response, like batch items
+func problemError(p api.Problem) *Error {
+	return &Error{Status: p.Status, Problem: p}
+}
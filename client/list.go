@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+	"mis-catanddog/controllers"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions selects a page of a list. Zero values take the service defaults.
+// Sort is comma separated fields, '-' prefix sorts descending. Cursor is NextCursor of the previous page
+type ListOptions struct {
+	Limit   int
+	Sort    string
+	Cursor  string
+	Filters []controllers.Filter
+}
+
+// Page is a page of a list. NextCursor is empty on the last page
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Next       string
+}
+
+// values returns query parameters of the options
+func (o ListOptions) values() url.Values {
+	var q = url.Values{}
+	if o.Limit != 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	for _, val := range o.Filters {
+		q.Add(fmt.Sprintf("%s[%s]", val.Field, val.Op), val.Value)
+	}
+	return q
+}
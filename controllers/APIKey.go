@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// APIKey is a static credential of a service caller. The key itself is never stored, only its hash
+type APIKey struct {
+	Id      int
+	Name    string
+	Hash    string
+	Revoked bool
+}
+
+// APIKeyRepo stores API keys. APIKeyGetByHash returns ErrNotFound for unknown and revoked keys alike,
+// APIKeyCreate returns ErrAlreadyExists in case either name or hash is taken
+type APIKeyRepo interface {
+	APIKeyGetByHash(ctx context.Context, hash string, l *slog.Logger) (APIKey, error)
+	APIKeyCreate(ctx context.Context, k APIKey, l *slog.Logger) (APIKey, error)
+	APIKeyRevoke(ctx context.Context, name string, l *slog.Logger) error
+}
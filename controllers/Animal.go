@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Animal is a patient record. Owner and Type are filled only when expansion is requested
+type Animal struct {
+	DocId      int
+	DocType    int
+	Name       string
+	BirthDate  string
+	AnimalType int
+	Breed      string
+	OwnerDocId int
+	Owner      *Human      `json:",omitempty"`
+	Type       *AnimalType `json:",omitempty"`
+}
+
+type AnimalRepo interface {
+	AnimalCreate(ctx context.Context, a Animal, l *slog.Logger) (Animal, error)
+	AnimalGet(ctx context.Context, docId int, l *slog.Logger) (Animal, error)
+	AnimalGetByOwner(ctx context.Context, ownerDocId int, l *slog.Logger) ([]Animal, error)
+	AnimalList(ctx context.Context, q ListQuery, l *slog.Logger) ([]Animal, []string, error)
+	AnimalUpdate(ctx context.Context, a Animal, l *slog.Logger) (Animal, error)
+	AnimalDelete(ctx context.Context, docId int, l *slog.Logger) error
+}
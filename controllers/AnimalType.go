@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AnimalType is a record of the animal type dictionary
+type AnimalType struct {
+	Id   int
+	Type string
+}
+
+// AnimalTypeGetter returns ErrNotFound in case there is no such record. Batch methods run a single query,
+// return records in the order of the requested values and report misses as AnimalType with Id 0
+type AnimalTypeGetter interface {
+	AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (AnimalType, error)
+	AnimalTypeGetByType(ctx context.Context, animalType string, l *slog.Logger) (AnimalType, error)
+	AnimalTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]AnimalType, error)
+	AnimalTypeGetByTypes(ctx context.Context, types []string, l *slog.Logger) ([]AnimalType, error)
+	AnimalTypeList(ctx context.Context, q ListQuery, l *slog.Logger) ([]AnimalType, []string, error)
+}
+
+type AnimalTypeWriter interface {
+	AnimalTypeCreate(ctx context.Context, a AnimalType, l *slog.Logger) (AnimalType, error)
+	AnimalTypeUpdate(ctx context.Context, a AnimalType, l *slog.Logger) (AnimalType, error)
+	AnimalTypeDelete(ctx context.Context, id int, l *slog.Logger) error
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+// Actor is the caller on whose behalf the data is changed. Repos record it along with every change
+type Actor struct {
+	Subject   string
+	RequestId string
+}
+
+type actorKey struct{}
+
+// WithActor returns ctx carrying a
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFromContext returns actor stored by WithActor, ok is false in case there is none
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+// AuditEntry is a record of the append-only audit log. At is UTC time of the change in RFC 3339 format.
+// Before and After hold the changed fields only, Before is null for created records and After for deleted ones
+type AuditEntry struct {
+	Id        int
+	At        string
+	Actor     string
+	RequestId string
+	Entity    string
+	EntityId  string
+	Action    string
+	Before    json.RawMessage
+	After     json.RawMessage
+}
+
+// AuditReader reads the audit log. Writes happen inside the repos along with the change they record
+type AuditReader interface {
+	AuditList(ctx context.Context, q ListQuery, l *slog.Logger) ([]AuditEntry, []string, error)
+}
@@ -8,10 +8,20 @@ import (
 type DocType struct {
 	Id  int
 	Doc string
-	Err string
 }
 
+// DocTypeGetter returns ErrNotFound in case there is no such record. Batch methods run a single query,
+// return records in the order of the requested values and report misses as DocType with Id 0
 type DocTypeGetter interface {
-	DocTypeGetById(ctx context.Context, id int, l *slog.Logger) DocType
-	DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) DocType
+	DocTypeGetById(ctx context.Context, id int, l *slog.Logger) (DocType, error)
+	DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) (DocType, error)
+	DocTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]DocType, error)
+	DocTypeGetByDocs(ctx context.Context, docs []string, l *slog.Logger) ([]DocType, error)
+	DocTypeList(ctx context.Context, q ListQuery, l *slog.Logger) ([]DocType, []string, error)
+}
+
+type DocTypeWriter interface {
+	DocTypeCreate(ctx context.Context, d DocType, l *slog.Logger) (DocType, error)
+	DocTypeUpdate(ctx context.Context, d DocType, l *slog.Logger) (DocType, error)
+	DocTypeDelete(ctx context.Context, id int, l *slog.Logger) error
 }
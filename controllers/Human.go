@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DateLayout is the format of all dates passed to and from controllers
+const DateLayout = "2006-01-02"
+
+type Human struct {
+	DocId      int
+	DocType    int
+	FirstName  string
+	MiddleName string
+	LastName   string
+	BirthDate  string
+}
+
+// HumanFilter holds HumanSearch conditions. Zero value fields are ignored,
+// names are matched by prefix
+type HumanFilter struct {
+	DocType   int
+	FirstName string
+	LastName  string
+	BirthDate string
+}
+
+type HumanRepo interface {
+	HumanCreate(ctx context.Context, h Human, l *slog.Logger) (Human, error)
+	HumanGet(ctx context.Context, docId int, l *slog.Logger) (Human, error)
+	HumanUpdate(ctx context.Context, h Human, l *slog.Logger) (Human, error)
+	HumanDelete(ctx context.Context, docId int, l *slog.Logger) error
+	HumanSearch(ctx context.Context, f HumanFilter, l *slog.Logger) ([]Human, error)
+	HumanList(ctx context.Context, q ListQuery, l *slog.Logger) ([]Human, []string, error)
+}
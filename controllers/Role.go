@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RoleRepo stores roles granted to subjects of authenticated callers. SubjectPermissions returns permissions
+// of every role granted to the subject, none for unknown subjects. RoleGrant returns ErrNotFound for unknown
+// roles and ErrAlreadyExists for granted ones, RoleRevoke returns ErrNotFound in case the role is not granted
+type RoleRepo interface {
+	SubjectPermissions(ctx context.Context, subject string, l *slog.Logger) ([]string, error)
+	RoleGrant(ctx context.Context, subject string, role string, l *slog.Logger) error
+	RoleRevoke(ctx context.Context, subject string, role string, l *slog.Logger) error
+}
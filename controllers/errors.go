@@ -0,0 +1,19 @@
+package controllers
+
+import "errors"
+
+// ErrNotFound means the record addressed by the request does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrAlreadyExists means the record conflicts with an already existing one
+var ErrAlreadyExists = errors.New("record already exists")
+
+// ErrReference means the change breaks a reference between records: the record refers to a missing one
+// or is still referred to by others
+var ErrReference = errors.New("record reference violated")
+
+// ErrUnavailable means the repository can't serve requests right now. Retry may succeed
+var ErrUnavailable = errors.New("repository unavailable")
+
+// ErrForbidden means the caller lacks permission for the operation
+var ErrForbidden = errors.New("access denied")
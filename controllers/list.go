@@ -0,0 +1,118 @@
+package controllers
+
+// ListType tells how values of a list field are parsed and compared
+type ListType int
+
+const (
+	ListText ListType = iota
+	ListInt
+	ListDate
+)
+
+// ListOp is a filter operation
+type ListOp string
+
+const (
+	OpEq   ListOp = "eq"
+	OpLike ListOp = "like" // '*' matches any sequence of characters, text fields only
+	OpGte  ListOp = "gte"  // int and date fields only
+	OpLte  ListOp = "lte"  // int and date fields only
+)
+
+// ListField is a field of a list endpoint available for sorting and filtering
+type ListField struct {
+	Name string
+	Type ListType
+}
+
+// ListSpec describes sortable and filterable fields of a list endpoint. Key is the unique field
+// that makes the order total, so keyset pagination never skips or repeats records
+type ListSpec struct {
+	Fields []ListField
+	Key    string
+}
+
+// Field returns field by name
+func (s ListSpec) Field(name string) (ListField, bool) {
+	for _, val := range s.Fields {
+		if val.Name == name {
+			return val, true
+		}
+	}
+	return ListField{}, false
+}
+
+// SortField is a single sort criterion
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is a single filter criterion
+type Filter struct {
+	Field string
+	Op    ListOp
+	Value string
+}
+
+// ListQuery asks for a page of a list. Sort always ends with the ListSpec Key.
+// After holds values of Sort fields of the last record of the previous page, empty for the first page.
+// List methods of the repos return the page and After of the next one, nil on the last page
+type ListQuery struct {
+	Limit   int
+	Sort    []SortField
+	Filters []Filter
+	After   []string
+}
+
+// DocTypeListSpec lists fields of the doc_type list
+var DocTypeListSpec = ListSpec{
+	Fields: []ListField{{Name: "id", Type: ListInt}, {Name: "doc", Type: ListText}},
+	Key:    "id",
+}
+
+// AnimalTypeListSpec lists fields of the animal_type list
+var AnimalTypeListSpec = ListSpec{
+	Fields: []ListField{{Name: "id", Type: ListInt}, {Name: "type", Type: ListText}},
+	Key:    "id",
+}
+
+// HumanListSpec lists fields of the human list. middle_name is nullable, so it is left out
+var HumanListSpec = ListSpec{
+	Fields: []ListField{
+		{Name: "doc_id", Type: ListInt},
+		{Name: "doc_type", Type: ListInt},
+		{Name: "first_name", Type: ListText},
+		{Name: "last_name", Type: ListText},
+		{Name: "birth_date", Type: ListDate},
+	},
+	Key: "doc_id",
+}
+
+// AnimalListSpec lists fields of the animal list
+var AnimalListSpec = ListSpec{
+	Fields: []ListField{
+		{Name: "doc_id", Type: ListInt},
+		{Name: "doc_type", Type: ListInt},
+		{Name: "name", Type: ListText},
+		{Name: "birth_date", Type: ListDate},
+		{Name: "animal_type", Type: ListInt},
+		{Name: "breed", Type: ListText},
+		{Name: "owner_doc_id", Type: ListInt},
+	},
+	Key: "doc_id",
+}
+
+// AuditListSpec lists fields of the audit log. date is the UTC day of the change
+var AuditListSpec = ListSpec{
+	Fields: []ListField{
+		{Name: "id", Type: ListInt},
+		{Name: "date", Type: ListDate},
+		{Name: "actor", Type: ListText},
+		{Name: "request_id", Type: ListText},
+		{Name: "entity", Type: ListText},
+		{Name: "entity_id", Type: ListText},
+		{Name: "action", Type: ListText},
+	},
+	Key: "id",
+}
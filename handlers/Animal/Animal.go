@@ -0,0 +1,102 @@
+package Animal
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/lg"
+	"net/http"
+)
+
+// route documents the /animal url
+var route = handlers.Route{
+	Path:    "/animal",
+	Summary: "Animals",
+	Operations: []handlers.Operation{
+		{
+			Method:  http.MethodGet,
+			Summary: "List or look up animals",
+			Query:   getAnimalQuery,
+			Responses: []handlers.Response{
+				{Status: http.StatusOK, Description: "Page of the list, the animal with doc_id, or animals of the owner", Bodies: []any{handlers.ListPage{Items: []controllers.Animal{}}, controllers.Animal{}, []controllers.Animal{}}, List: true},
+			},
+		},
+		{
+			Method:    http.MethodPost,
+			Summary:   "Create animal",
+			Body:      controllers.Animal{},
+			Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created animal", Bodies: []any{controllers.Animal{}}}},
+		},
+		{
+			Method:    http.MethodPut,
+			Summary:   "Replace animal",
+			Query:     animalDocIdQuery,
+			Body:      controllers.Animal{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated animal", Bodies: []any{controllers.Animal{}}}},
+		},
+		{
+			Method:    http.MethodPatch,
+			Summary:   "Update non-empty fields of animal set in the body",
+			Query:     animalDocIdQuery,
+			Body:      controllers.Animal{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated animal", Bodies: []any{controllers.Animal{}}}},
+		},
+		{
+			Method:    http.MethodDelete,
+			Summary:   "Delete animal",
+			Query:     animalDocIdQuery,
+			Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+		},
+	},
+}
+
+// allowedMethods lists methods served by the /animal url
+var allowedMethods = route.Methods()
+
+// Handler handles CRUD operation for the /animal url
+type Handler struct {
+	animals     controllers.AnimalRepo
+	humans      controllers.HumanRepo
+	docTypes    controllers.DocTypeGetter
+	animalTypes controllers.AnimalTypeGetter
+	log         *slog.Logger
+}
+
+// New returns Handler built from app. Returns error in case any dependency is missing
+func New(app *handlers.App) (*Handler, error) {
+	if app == nil || app.AnimalRepo == nil || app.HumanRepo == nil || app.DocTypeGetter == nil || app.AnimalTypeGetter == nil || app.Log == nil {
+		return nil, fmt.Errorf("animal handler requires AnimalRepo, HumanRepo, DocTypeGetter, AnimalTypeGetter and logger")
+	}
+	return &Handler{
+		animals:     app.AnimalRepo,
+		humans:      app.HumanRepo,
+		docTypes:    app.DocTypeGetter,
+		animalTypes: app.AnimalTypeGetter,
+		log:         app.Log,
+	}, nil
+}
+
+// Route documents the /animal url
+func (h *Handler) Route() handlers.Route {
+	return route
+}
+
+// ServeHTTP selects handler by the request method
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := lg.FromContext(r.Context(), h.log)
+
+	// select handler
+	switch r.Method {
+	case http.MethodGet:
+		h.getAnimal(r.Context(), w, r, log)
+	case http.MethodPost:
+		h.postAnimal(r.Context(), w, r, log)
+	case http.MethodPut, http.MethodPatch:
+		h.putAnimal(r.Context(), w, r, log)
+	case http.MethodDelete:
+		h.deleteAnimal(r.Context(), w, r, log)
+	default:
+		handlers.MethodNotAllowed(w, r, log, allowedMethods)
+	}
+}
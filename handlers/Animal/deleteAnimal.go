@@ -0,0 +1,28 @@
+package Animal
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// deleteAnimal addresses the record by the 'doc_id' query parameter, see deleteAnimalId
+func (h *Handler) deleteAnimal(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	docId, err := animalDocIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.deleteAnimalId(ctx, w, r, docId, l)
+}
+
+// deleteAnimalId deletes the record and responds with http.StatusNoContent
+func (h *Handler) deleteAnimalId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	if err := h.animals.AnimalDelete(ctx, docId, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,181 @@
+package Animal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// animalExpand lists related objects to be returned inline with the animal
+type animalExpand struct {
+	owner bool
+	typ   bool
+}
+
+// animalExpandParam lists related objects to return inline, see getAnimalExpand
+var animalExpandParam = handlers.Param{Name: "expand", Type: handlers.ParamString, MaxCount: 2}
+
+// getAnimalQuery is the query contract of GET. Either 'doc_id' or 'owner_doc_id' is expected,
+// neither of them means the list is requested
+var getAnimalQuery = handlers.QuerySchema{
+	Params: []handlers.Param{
+		{Name: "doc_id", Type: handlers.ParamInt, Range: handlers.Positive},
+		{Name: "owner_doc_id", Type: handlers.ParamInt, Range: handlers.Positive},
+		animalExpandParam,
+	},
+	Rules: []handlers.Rule{{Mode: handlers.ModeOneOf, Params: []string{"doc_id", "owner_doc_id"}}},
+	List:  &controllers.AnimalListSpec,
+}
+
+// animalDocIdQuery is the query contract of requests addressing a single animal
+var animalDocIdQuery = handlers.QuerySchema{
+	Params: []handlers.Param{{Name: "doc_id", Type: handlers.ParamInt, Range: handlers.Positive}},
+	Rules:  []handlers.Rule{{Mode: handlers.ModeRequired, Params: []string{"doc_id"}}},
+}
+
+// getAnimalExpand parses 'expand' query values. Both comma separated and repeated values are accepted
+func getAnimalExpand(vals url.Values) (animalExpand, error) {
+	var e animalExpand
+	for _, val := range vals["expand"] {
+		for _, item := range strings.Split(val, ",") {
+			switch strings.TrimSpace(item) {
+			case "owner":
+				e.owner = true
+			case "type":
+				e.typ = true
+			default:
+				return e, fmt.Errorf("unexpected expand value [%s]. Expected: owner, type", item)
+			}
+		}
+	}
+	return e, nil
+}
+
+// getAnimalExpandData fills Owner and Type of every animal. Dangling references are left empty
+func (h *Handler) getAnimalExpandData(ctx context.Context, result []controllers.Animal, e animalExpand, l *slog.Logger) error {
+	var owners = make(map[int]*controllers.Human)
+	var types = make(map[int]*controllers.AnimalType)
+
+	for i := range result {
+		if e.owner {
+			owner, ok := owners[result[i].OwnerDocId]
+			if !ok {
+				human, err := h.humans.HumanGet(ctx, result[i].OwnerDocId, l)
+				if err != nil && !errors.Is(err, controllers.ErrNotFound) {
+					return err
+				}
+				if err == nil {
+					owner = &human
+				}
+				owners[result[i].OwnerDocId] = owner
+			}
+			result[i].Owner = owner
+		}
+		if e.typ {
+			typ, ok := types[result[i].AnimalType]
+			if !ok {
+				t, err := h.animalTypes.AnimalTypeGetById(ctx, result[i].AnimalType, l)
+				if err != nil && !errors.Is(err, controllers.ErrNotFound) {
+					return err
+				}
+				if err == nil {
+					typ = &t
+				}
+				types[result[i].AnimalType] = typ
+			}
+			result[i].Type = typ
+		}
+	}
+	return nil
+}
+
+// getAnimalList responds with a page of the list, related objects expanded as requested
+func (h *Handler) getAnimalList(ctx context.Context, w http.ResponseWriter, r *http.Request, e animalExpand, l *slog.Logger) {
+	q, err := handlers.ParseList(r.URL.Query(), controllers.AnimalListSpec, "expand")
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	items, after, err := h.animals.AnimalList(ctx, q, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	if err := h.getAnimalExpandData(ctx, items, e, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.WriteList(w, r, q, items, after, l)
+}
+
+func (h *Handler) getAnimal(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	// validate URL query
+	vals := r.URL.Query()
+	if err := getAnimalQuery.Validate(vals); err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	e, err := getAnimalExpand(vals)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+
+	if !vals.Has("doc_id") && !vals.Has("owner_doc_id") {
+		h.getAnimalList(ctx, w, r, e, l)
+		return
+	}
+
+	if vals.Has("doc_id") {
+		h.getAnimalId(ctx, w, r, handlers.QueryInt(vals, "doc_id"), e, l)
+		return
+	}
+	h.getAnimalsByOwner(ctx, w, r, handlers.QueryInt(vals, "owner_doc_id"), e, l)
+}
+
+// getAnimalId responds with the animal, related objects expanded as requested
+func (h *Handler) getAnimalId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, e animalExpand, l *slog.Logger) {
+	result, err := h.animals.AnimalGet(ctx, docId, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	list := []controllers.Animal{result}
+	if err := h.getAnimalExpandData(ctx, list, e, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, list[0], l)
+}
+
+// getAnimalsByOwner responds with animals of the owner, related objects expanded as requested
+func (h *Handler) getAnimalsByOwner(ctx context.Context, w http.ResponseWriter, r *http.Request, ownerDocId int, e animalExpand, l *slog.Logger) {
+	result, err := h.animals.AnimalGetByOwner(ctx, ownerDocId, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	if result == nil {
+		result = make([]controllers.Animal, 0)
+	}
+	if err := h.getAnimalExpandData(ctx, result, e, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
+
+// animalDocIdFromQuery returns single integer doc_id from the request URL
+func animalDocIdFromQuery(vals url.Values) (int, error) {
+	if err := animalDocIdQuery.Validate(vals); err != nil {
+		return 0, err
+	}
+	return handlers.QueryInt(vals, "doc_id"), nil
+}
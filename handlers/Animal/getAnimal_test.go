@@ -0,0 +1,132 @@
+package Animal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"net/url"
+	"testing"
+)
+
+type fakeDB struct {
+	humans      map[int]controllers.Human
+	animalTypes map[int]string
+	err         error // returned by lookups in place of the result
+}
+
+func (f *fakeDB) HumanCreate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	return h, nil
+}
+
+func (f *fakeDB) HumanGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Human, error) {
+	val, ok := f.humans[docId]
+	if !ok {
+		return controllers.Human{}, controllers.ErrNotFound
+	}
+	return val, nil
+}
+
+func (f *fakeDB) HumanUpdate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	return h, nil
+}
+
+func (f *fakeDB) HumanDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	return nil
+}
+
+func (f *fakeDB) HumanSearch(ctx context.Context, hf controllers.HumanFilter, l *slog.Logger) ([]controllers.Human, error) {
+	return nil, nil
+}
+
+func (f *fakeDB) HumanList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.Human, []string, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeDB) AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.AnimalType, error) {
+	if f.err != nil {
+		return controllers.AnimalType{}, f.err
+	}
+	val, ok := f.animalTypes[id]
+	if !ok {
+		return controllers.AnimalType{}, controllers.ErrNotFound
+	}
+	return controllers.AnimalType{Id: id, Type: val}, nil
+}
+
+func (f *fakeDB) AnimalTypeGetByType(ctx context.Context, animalType string, l *slog.Logger) (controllers.AnimalType, error) {
+	return controllers.AnimalType{}, controllers.ErrNotFound
+}
+
+func (f *fakeDB) AnimalTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.AnimalType, error) {
+	return nil, nil
+}
+
+func (f *fakeDB) AnimalTypeGetByTypes(ctx context.Context, types []string, l *slog.Logger) ([]controllers.AnimalType, error) {
+	return nil, nil
+}
+
+func (f *fakeDB) AnimalTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AnimalType, []string, error) {
+	return nil, nil, nil
+}
+
+func TestGetAnimalExpand(t *testing.T) {
+	type expandTest struct {
+		Url     url.Values
+		Result  animalExpand
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []expandTest{
+		{Url: map[string][]string{}, Result: animalExpand{}, Message: "positive test [no expand] failed", Crit: true},
+		{Url: map[string][]string{"expand": {"owner,type"}}, Result: animalExpand{owner: true, typ: true}, Message: "positive test [expand owner,type] failed", Crit: true},
+		{Url: map[string][]string{"expand": {"type"}}, Result: animalExpand{typ: true}, Message: "positive test [expand type] failed", Crit: true},
+		{Url: map[string][]string{"expand": {"owner", "type"}}, Result: animalExpand{owner: true, typ: true}, Message: "positive test [expand owner expand type] failed", Crit: true},
+		{Url: map[string][]string{"expand": {"breed"}}, IsErr: true, Message: "negative test [expand breed] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		result, err := getAnimalExpand(val.Url)
+		if (err != nil) != val.IsErr || (err == nil && result != val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestGetAnimalExpandData(t *testing.T) {
+	var log = &slog.Logger{}
+	var db = &fakeDB{
+		humans:      map[int]controllers.Human{1: {DocId: 1, DocType: 1, FirstName: "Alexey", LastName: "Ivanov", BirthDate: "1992-03-30"}},
+		animalTypes: map[int]string{1: "dog"},
+	}
+	var h = &Handler{humans: db, animalTypes: db}
+	var result = []controllers.Animal{
+		{DocId: 10, Name: "Rex", AnimalType: 1, OwnerDocId: 1},
+		{DocId: 11, Name: "Tom", AnimalType: 2, OwnerDocId: 2},
+	}
+
+	if err := h.getAnimalExpandData(context.TODO(), result, animalExpand{owner: true, typ: true}, log); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if result[0].Owner == nil || result[0].Owner.LastName != "Ivanov" || result[0].Type == nil || result[0].Type.Type != "dog" {
+		t.Fatalf("expected owner and type to be expanded; got %+v", result[0])
+	}
+	if result[1].Owner != nil || result[1].Type != nil {
+		t.Fatalf("expected dangling references to stay empty; got %+v", result[1])
+	}
+
+	// an outage is not a dangling reference
+	db.err = controllers.ErrUnavailable
+	if err := h.getAnimalExpandData(context.TODO(), []controllers.Animal{{DocId: 10, AnimalType: 1}}, animalExpand{typ: true}, log); !errors.Is(err, controllers.ErrUnavailable) {
+		t.Fatalf("expected lookup failure to be returned; got %v", err)
+	}
+}
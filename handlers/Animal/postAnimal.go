@@ -0,0 +1,104 @@
+package Animal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// animalReadBody decodes Animal object from the request body. Expanded fields are ignored
+func animalReadBody(r *http.Request) (controllers.Animal, error) {
+	var a controllers.Animal
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		return a, fmt.Errorf("cannot decode request body: %w", err)
+	}
+	a.Name = strings.TrimSpace(a.Name)
+	a.Breed = strings.TrimSpace(a.Breed)
+	a.Owner, a.Type = nil, nil
+	return a, nil
+}
+
+// animalValidate checks Animal object fields. doc_type, animal_type and owner must reference existing records.
+// Errors other than api.FieldError mean the repo failed
+func (h *Handler) animalValidate(ctx context.Context, a controllers.Animal, l *slog.Logger) error {
+	if a.DocId <= 0 {
+		return api.FieldError{Field: "DocId", Detail: "must be a positive integer"}
+	}
+	if a.Name == "" {
+		return api.FieldError{Field: "Name", Detail: "must not be empty"}
+	}
+	if a.Breed == "" {
+		return api.FieldError{Field: "Breed", Detail: "must not be empty"}
+	}
+	birthDate, err := time.Parse(controllers.DateLayout, a.BirthDate)
+	if err != nil {
+		return api.FieldError{Field: "BirthDate", Detail: fmt.Sprintf("[%s] is not a %s date", a.BirthDate, controllers.DateLayout)}
+	}
+	if birthDate.After(time.Now()) {
+		return api.FieldError{Field: "BirthDate", Detail: fmt.Sprintf("[%s] is in the future", a.BirthDate)}
+	}
+	if a.DocType <= 0 {
+		return api.FieldError{Field: "DocType", Detail: "must be a positive integer"}
+	}
+	_, err = h.docTypes.DocTypeGetById(ctx, a.DocType, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return api.FieldError{Field: "DocType", Detail: fmt.Sprintf("[%d] does not reference an existing doc_type", a.DocType)}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot look up doc_type [%d]: %w", a.DocType, err)
+	}
+	if a.AnimalType <= 0 {
+		return api.FieldError{Field: "AnimalType", Detail: fmt.Sprintf("[%d] does not reference an existing animal_type", a.AnimalType)}
+	}
+	_, err = h.animalTypes.AnimalTypeGetById(ctx, a.AnimalType, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return api.FieldError{Field: "AnimalType", Detail: fmt.Sprintf("[%d] does not reference an existing animal_type", a.AnimalType)}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot look up animal_type [%d]: %w", a.AnimalType, err)
+	}
+	if a.OwnerDocId <= 0 {
+		return api.FieldError{Field: "OwnerDocId", Detail: "must be a positive integer"}
+	}
+	_, err = h.humans.HumanGet(ctx, a.OwnerDocId, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return api.FieldError{Field: "OwnerDocId", Detail: fmt.Sprintf("[%d] does not reference an existing human", a.OwnerDocId)}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot look up owner [%d]: %w", a.OwnerDocId, err)
+	}
+	return nil
+}
+
+func (h *Handler) postAnimal(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	a, err := animalReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+
+	if err := h.animalValidate(ctx, a, l); err != nil {
+		handlers.InvalidRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.animals.AnimalCreate(ctx, a, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusCreated, result, l)
+}
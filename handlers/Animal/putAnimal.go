@@ -0,0 +1,84 @@
+package Animal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// animalMerge overlays non-empty fields of patch onto a
+func animalMerge(a, patch controllers.Animal) controllers.Animal {
+	if patch.DocType != 0 {
+		a.DocType = patch.DocType
+	}
+	if patch.Name != "" {
+		a.Name = patch.Name
+	}
+	if patch.BirthDate != "" {
+		a.BirthDate = patch.BirthDate
+	}
+	if patch.AnimalType != 0 {
+		a.AnimalType = patch.AnimalType
+	}
+	if patch.Breed != "" {
+		a.Breed = patch.Breed
+	}
+	if patch.OwnerDocId != 0 {
+		a.OwnerDocId = patch.OwnerDocId
+	}
+	return a
+}
+
+// putAnimal addresses the record by the 'doc_id' query parameter, see putAnimalId
+func (h *Handler) putAnimal(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	docId, err := animalDocIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.putAnimalId(ctx, w, r, docId, l)
+}
+
+// putAnimalId replaces the record on PUT and merges non-empty fields on PATCH
+func (h *Handler) putAnimalId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	a, err := animalReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if a.DocId != 0 && a.DocId != docId {
+		handlers.BadRequest(w, r, l, api.FieldError{Field: "DocId", Detail: fmt.Sprintf("[%d] differs from the addressed doc_id [%d]", a.DocId, docId)})
+		return
+	}
+	a.DocId = docId
+
+	if r.Method == http.MethodPatch {
+		current, err := h.animals.AnimalGet(ctx, docId, l)
+		if err != nil {
+			handlers.RepoError(w, r, l, err)
+			return
+		}
+		a = animalMerge(current, a)
+	}
+
+	if err := h.animalValidate(ctx, a, l); err != nil {
+		handlers.InvalidRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.animals.AnimalUpdate(ctx, a, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
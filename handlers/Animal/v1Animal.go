@@ -0,0 +1,150 @@
+package Animal
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// query contracts of the v1 routes
+var (
+	animalListQuery   = handlers.QuerySchema{Params: []handlers.Param{animalExpandParam}, List: &controllers.AnimalListSpec}
+	animalExpandQuery = handlers.QuerySchema{Params: []handlers.Param{animalExpandParam}}
+)
+
+// withExpand parses 'expand' query values before calling f
+func withExpand(f func(ctx context.Context, w http.ResponseWriter, r *http.Request, e animalExpand, l *slog.Logger)) handlers.OperationFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		e, err := getAnimalExpand(r.URL.Query())
+		if err != nil {
+			handlers.BadRequest(w, r, l, err)
+			return
+		}
+		f(ctx, w, r, e, l)
+	}
+}
+
+// getAnimalOwner responds with the owner of the animal, 404 in case either of them does not exist
+func (h *Handler) getAnimalOwner(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	a, err := h.animals.AnimalGet(ctx, docId, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	result, err := h.humans.HumanGet(ctx, a.OwnerDocId, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
+
+// getHumanAnimals responds with animals of the human, 404 in case the human does not exist
+func (h *Handler) getHumanAnimals(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, e animalExpand, l *slog.Logger) {
+	if _, err := h.humans.HumanGet(ctx, docId, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	h.getAnimalsByOwner(ctx, w, r, docId, e, l)
+}
+
+// V1Routes returns routes of animals in the v1 resource API, paths are relative to the version prefix
+func (h *Handler) V1Routes() []handlers.Route {
+	var one = []handlers.Response{{Status: http.StatusOK, Description: "The animal", Bodies: []any{controllers.Animal{}}}}
+	var put = func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		h.putAnimalId(ctx, w, r, handlers.PathInt(r, "id"), l)
+	}
+
+	return []handlers.Route{
+		{
+			Path:    "/animals",
+			Summary: "Animals",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "List animals",
+					Query:     animalListQuery,
+					Responses: []handlers.Response{{Status: http.StatusOK, Description: "Page of the list", Bodies: []any{handlers.ListPage{Items: []controllers.Animal{}}}, List: true}},
+					Handler:   withExpand(h.getAnimalList),
+				},
+				{
+					Method:    http.MethodPost,
+					Summary:   "Create animal",
+					Body:      controllers.Animal{},
+					Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created animal", Bodies: []any{controllers.Animal{}}}},
+					Handler:   h.postAnimal,
+				},
+			},
+		},
+		{
+			Path:    "/animals/{id}",
+			Summary: "Animal addressed by the doc id",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "Get animal",
+					Path:      handlers.PathId,
+					Query:     animalExpandQuery,
+					Responses: one,
+					Handler: withExpand(func(ctx context.Context, w http.ResponseWriter, r *http.Request, e animalExpand, l *slog.Logger) {
+						h.getAnimalId(ctx, w, r, handlers.PathInt(r, "id"), e, l)
+					}),
+				},
+				{
+					Method:    http.MethodPut,
+					Summary:   "Replace animal",
+					Path:      handlers.PathId,
+					Body:      controllers.Animal{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodPatch,
+					Summary:   "Update non-empty fields of animal set in the body",
+					Path:      handlers.PathId,
+					Body:      controllers.Animal{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodDelete,
+					Summary:   "Delete animal",
+					Path:      handlers.PathId,
+					Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.deleteAnimalId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+			},
+		},
+		{
+			Path:    "/animals/{id}/owner",
+			Summary: "Owner of the animal",
+			Operations: []handlers.Operation{{
+				Method:    http.MethodGet,
+				Summary:   "Get owner of animal",
+				Path:      handlers.PathId,
+				Responses: []handlers.Response{{Status: http.StatusOK, Description: "The owner", Bodies: []any{controllers.Human{}}}},
+				Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+					h.getAnimalOwner(ctx, w, r, handlers.PathInt(r, "id"), l)
+				},
+			}},
+		},
+		{
+			Path:    "/humans/{id}/animals",
+			Summary: "Animals of the human",
+			Operations: []handlers.Operation{{
+				Method:    http.MethodGet,
+				Summary:   "List animals of human",
+				Path:      handlers.PathId,
+				Query:     animalExpandQuery,
+				Responses: []handlers.Response{{Status: http.StatusOK, Description: "Animals of the human", Bodies: []any{[]controllers.Animal{}}}},
+				Handler: withExpand(func(ctx context.Context, w http.ResponseWriter, r *http.Request, e animalExpand, l *slog.Logger) {
+					h.getHumanAnimals(ctx, w, r, handlers.PathInt(r, "id"), e, l)
+				}),
+			}},
+		},
+	}
+}
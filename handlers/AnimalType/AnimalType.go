@@ -0,0 +1,95 @@
+package AnimalType
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/lg"
+	"net/http"
+)
+
+// route documents the /animal_type url
+var route = handlers.Route{
+	Path:    "/animal_type",
+	Summary: "Animal types",
+	Operations: []handlers.Operation{
+		{
+			Method:  http.MethodGet,
+			Summary: "List or look up animal types",
+			Query:   getAnimalTypeQuery,
+			Responses: []handlers.Response{
+				{Status: http.StatusOK, Description: "Page of the list, or the looked up animal type", Bodies: []any{handlers.ListPage{Items: []controllers.AnimalType{}}, []controllers.AnimalType{}}, List: true},
+				{Status: http.StatusMultiStatus, Description: "Per-item results of a batch lookup", Bodies: []any{[]handlers.BatchItem{{Item: controllers.AnimalType{}}}}},
+			},
+		},
+		{
+			Method:    http.MethodPost,
+			Summary:   "Create animal type",
+			Body:      controllers.AnimalType{},
+			Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created animal type", Bodies: []any{controllers.AnimalType{}}}},
+		},
+		{
+			Method:    http.MethodPut,
+			Summary:   "Replace animal type",
+			Query:     animalTypeIdQuery,
+			Body:      controllers.AnimalType{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated animal type", Bodies: []any{controllers.AnimalType{}}}},
+		},
+		{
+			Method:    http.MethodPatch,
+			Summary:   "Replace animal type, same as PUT since type is the only mutable field",
+			Query:     animalTypeIdQuery,
+			Body:      controllers.AnimalType{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated animal type", Bodies: []any{controllers.AnimalType{}}}},
+		},
+		{
+			Method:    http.MethodDelete,
+			Summary:   "Delete animal type",
+			Query:     animalTypeIdQuery,
+			Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+		},
+	},
+}
+
+// allowedMethods lists methods served by the /animal_type url
+var allowedMethods = route.Methods()
+
+// Handler handles CRUD operation for the /animal_type url
+type Handler struct {
+	getter controllers.AnimalTypeGetter
+	writer controllers.AnimalTypeWriter
+	log    *slog.Logger
+}
+
+// New returns Handler built from app. Returns error in case any dependency is missing
+func New(app *handlers.App) (*Handler, error) {
+	if app == nil || app.AnimalTypeGetter == nil || app.AnimalTypeWriter == nil || app.Log == nil {
+		return nil, fmt.Errorf("animal type handler requires AnimalTypeGetter, AnimalTypeWriter and logger")
+	}
+	return &Handler{getter: app.AnimalTypeGetter, writer: app.AnimalTypeWriter, log: app.Log}, nil
+}
+
+// Route documents the /animal_type url
+func (h *Handler) Route() handlers.Route {
+	return route
+}
+
+// ServeHTTP selects handler by the request method
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := lg.FromContext(r.Context(), h.log)
+
+	// select handler
+	switch r.Method {
+	case http.MethodGet:
+		h.getAnimalType(r.Context(), w, r, log)
+	case http.MethodPost:
+		h.postAnimalType(r.Context(), w, r, log)
+	case http.MethodPut, http.MethodPatch:
+		h.putAnimalType(r.Context(), w, r, log)
+	case http.MethodDelete:
+		h.deleteAnimalType(r.Context(), w, r, log)
+	default:
+		handlers.MethodNotAllowed(w, r, log, allowedMethods)
+	}
+}
@@ -0,0 +1,28 @@
+package AnimalType
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// deleteAnimalType addresses the record by the 'id' query parameter, see deleteAnimalTypeId
+func (h *Handler) deleteAnimalType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	id, err := animalTypeIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.deleteAnimalTypeId(ctx, w, r, id, l)
+}
+
+// deleteAnimalTypeId deletes the record and responds with http.StatusNoContent
+func (h *Handler) deleteAnimalTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	if err := h.writer.AnimalTypeDelete(ctx, id, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
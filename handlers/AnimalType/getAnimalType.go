@@ -0,0 +1,158 @@
+package AnimalType
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// animalTypeLookup is the result of a single lookup. key is the requested id or type
+type animalTypeLookup struct {
+	key  string
+	item controllers.AnimalType
+	err  error
+}
+
+// animalTypeBatchMax caps the number of values looked up by a single request
+const animalTypeBatchMax = 100
+
+// getAnimalTypeQuery is the query contract of GET. Neither 'type' nor 'id' means the list is requested
+var getAnimalTypeQuery = handlers.QuerySchema{
+	Params: []handlers.Param{
+		{Name: "id", Type: handlers.ParamInt, MaxCount: animalTypeBatchMax},
+		{Name: "type", Type: handlers.ParamString, MaxCount: animalTypeBatchMax},
+	},
+	Rules: []handlers.Rule{{Mode: handlers.ModeOneOf, Params: []string{"id", "type"}}},
+	List:  &controllers.AnimalTypeListSpec,
+}
+
+// getAnimalTypeIds converts every 'id' value to an integer
+func getAnimalTypeIds(vals url.Values) ([]int, error) {
+	var ids []int
+	for _, val := range vals["id"] {
+		intVal, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, api.FieldError{Field: "id", Detail: fmt.Sprintf("failed to convert [%s] to an integer", val)}
+		}
+		ids = append(ids, intVal)
+	}
+	return ids, nil
+}
+
+// getAnimalTypeLookups pairs looked up items with the requested keys. Id 0 means a miss
+func getAnimalTypeLookups(keys []string, items []controllers.AnimalType, err error) []animalTypeLookup {
+	var result = make([]animalTypeLookup, 0, len(keys))
+	for i, key := range keys {
+		switch {
+		case err != nil:
+			result = append(result, animalTypeLookup{key: key, err: err})
+		case i >= len(items) || items[i].Id == 0:
+			result = append(result, animalTypeLookup{key: key, err: controllers.ErrNotFound})
+		default:
+			result = append(result, animalTypeLookup{key: key, item: items[i]})
+		}
+	}
+	return result
+}
+
+// getAnimalTypeQueryData looks up every requested value with a single repo call, results follow request order.
+// vals are expected to match getAnimalTypeQuery. Returns error in case any id is malformed, before querying the repo
+func getAnimalTypeQueryData(ctx context.Context, vals url.Values, db controllers.AnimalTypeGetter, l *slog.Logger) ([]animalTypeLookup, error) {
+	if valType, okType := vals["type"]; okType {
+		items, err := db.AnimalTypeGetByTypes(ctx, valType, l)
+		return getAnimalTypeLookups(valType, items, err), nil
+	}
+
+	ids, err := getAnimalTypeIds(vals)
+	if err != nil {
+		return nil, err
+	}
+	items, err := db.AnimalTypeGetByIds(ctx, ids, l)
+	return getAnimalTypeLookups(vals["id"], items, err), nil
+}
+
+// getAnimalTypeFailure returns the first lookup error that is not a miss. Such an error fails the whole request
+func getAnimalTypeFailure(result []animalTypeLookup) error {
+	for _, val := range result {
+		if val.err != nil && !errors.Is(val.err, controllers.ErrNotFound) {
+			return val.err
+		}
+	}
+	return nil
+}
+
+// getAnimalTypeBatch converts lookups to per-item results. Internal error details are not shared
+func getAnimalTypeBatch(r *http.Request, result []animalTypeLookup) []handlers.BatchItem {
+	var items = make([]handlers.BatchItem, 0, len(result))
+	for _, val := range result {
+		if val.err != nil {
+			p := handlers.RepoProblem(r, val.err)
+			if errors.Is(val.err, controllers.ErrNotFound) {
+				p.Detail = fmt.Sprintf("animal_type [%s] does not exist", val.key)
+			}
+			items = append(items, handlers.BatchItem{Status: p.Status, Problem: &p})
+			continue
+		}
+		items = append(items, handlers.BatchItem{Status: http.StatusOK, Item: val.item})
+	}
+	return items
+}
+
+// getAnimalTypeList responds with a page of the list
+func (h *Handler) getAnimalTypeList(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	q, err := handlers.ParseList(r.URL.Query(), controllers.AnimalTypeListSpec)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	items, after, err := h.getter.AnimalTypeList(ctx, q, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.WriteList(w, r, q, items, after, l)
+}
+
+// getAnimalType responds with 200 and a single item array for single value lookups, 404 in case it missed,
+// and with 207 and per-item statuses for batch lookups. Repo failures fail the whole request
+func (h *Handler) getAnimalType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	// validate URL query
+	vals := r.URL.Query()
+	if err := getAnimalTypeQuery.Validate(vals); err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if !vals.Has("type") && !vals.Has("id") {
+		h.getAnimalTypeList(ctx, w, r, l)
+		return
+	}
+
+	// get results
+	result, err := getAnimalTypeQueryData(ctx, r.URL.Query(), h.getter, l)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if err := getAnimalTypeFailure(result); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	// return to caller
+	if len(result) > 1 {
+		handlers.WriteBatch(w, r, getAnimalTypeBatch(r, result), l)
+		return
+	}
+	if result[0].err != nil {
+		handlers.RepoError(w, r, l, result[0].err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, []controllers.AnimalType{result[0].item}, l)
+}
@@ -0,0 +1,305 @@
+package AnimalType
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type fakeDB struct {
+	animalTypeId   map[int]string
+	animalTypeType map[string]int
+	// failId makes lookups of the id fail with the error
+	failId map[int]error
+	// batchCalls counts batch lookups
+	batchCalls int
+}
+
+func (f *fakeDB) AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.AnimalType, error) {
+	if err, ok := f.failId[id]; ok {
+		return controllers.AnimalType{}, err
+	}
+	val, ok := f.animalTypeId[id]
+	if !ok {
+		return controllers.AnimalType{}, controllers.ErrNotFound
+	}
+	return controllers.AnimalType{Id: id, Type: val}, nil
+}
+
+func (f *fakeDB) AnimalTypeGetByType(ctx context.Context, animalType string, l *slog.Logger) (controllers.AnimalType, error) {
+	val, ok := f.animalTypeType[animalType]
+	if !ok {
+		return controllers.AnimalType{}, controllers.ErrNotFound
+	}
+	return controllers.AnimalType{Id: val, Type: animalType}, nil
+}
+
+func (f *fakeDB) AnimalTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.AnimalType, error) {
+	var result []controllers.AnimalType
+	f.batchCalls++
+	for _, id := range ids {
+		if err, ok := f.failId[id]; ok {
+			return nil, err
+		}
+		typ, ok := f.animalTypeId[id]
+		if !ok {
+			result = append(result, controllers.AnimalType{})
+			continue
+		}
+		result = append(result, controllers.AnimalType{Id: id, Type: typ})
+	}
+	return result, nil
+}
+
+func (f *fakeDB) AnimalTypeGetByTypes(ctx context.Context, types []string, l *slog.Logger) ([]controllers.AnimalType, error) {
+	var result []controllers.AnimalType
+	f.batchCalls++
+	for _, typ := range types {
+		if _, ok := f.animalTypeType[typ]; !ok {
+			result = append(result, controllers.AnimalType{})
+			continue
+		}
+		result = append(result, controllers.AnimalType{Id: f.animalTypeType[typ], Type: typ})
+	}
+	return result, nil
+}
+
+func (f *fakeDB) AnimalTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AnimalType, []string, error) {
+	return nil, nil, nil
+}
+
+func TestGetAnimalTypeQuery(t *testing.T) {
+	type urlTest struct {
+		Url     url.Values
+		Err     string
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []urlTest{
+		{
+			Url: map[string][]string{
+				"id": {"1", "2"},
+			},
+			Err:     "",
+			Message: "positive test [id 1 2] failed",
+			Crit:    true,
+		},
+		{
+			Url: map[string][]string{
+				"type": {"dog", "kat"},
+			},
+			Err:     "",
+			Message: "positive test [type 'dog' 'kat'] failed",
+			Crit:    true,
+		},
+		{
+			Url: map[string][]string{
+				"id":   {"1", "2"},
+				"type": {"dog", "kat"},
+			},
+			Err:     "'id, type' at most one of the parameters expected, got id, type",
+			Message: "negative test ['type' and 'id' both preset] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": strings.Split(strings.Repeat("1,", animalTypeBatchMax+1), ",")[:animalTypeBatchMax+1]},
+			Err:     fmt.Sprintf("'id' at most %d values expected, got %d", animalTypeBatchMax, animalTypeBatchMax+1),
+			Message: "negative test [too many ids] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": {"dog"}},
+			Err:     "'id' failed to convert [dog] to an integer",
+			Message: "negative test [malformed id] failed",
+			Crit:    true,
+		},
+	}
+
+	for _, val := range arr {
+		err := getAnimalTypeQuery.Validate(val.Url)
+		if (err == nil && val.Err != "") || (err != nil && err.Error() != val.Err) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func compare(a []animalTypeLookup, b []controllers.AnimalType) bool {
+	var ln = len(a)
+	if ln != len(b) {
+		return false
+	}
+
+	for i := 0; i < ln; i++ {
+		if a[i].item != b[i] {
+			return false
+		}
+		// empty expected item means a miss
+		if (b[i].Id == 0) != errors.Is(a[i].err, controllers.ErrNotFound) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestGetAnimalTypeQueryData(t *testing.T) {
+	type queryTest struct {
+		Url     url.Values
+		Result  []controllers.AnimalType
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+
+	var ctx = context.TODO()
+	var log = &slog.Logger{}
+	var fail = false
+	var db = &fakeDB{
+		animalTypeId: map[int]string{
+			1: "dog",
+			2: "cat",
+		},
+		animalTypeType: map[string]int{
+			"dog": 1,
+			"cat": 2,
+		},
+	}
+	var arr = []queryTest{
+		{
+			Url:     map[string][]string{"id": {"1"}},
+			Result:  []controllers.AnimalType{{Id: 1, Type: "dog"}},
+			Message: "positive test [ Url map[id:[1]] Result [{1 dog}] ] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": {"2", "1"}},
+			Result:  []controllers.AnimalType{{Id: 2, Type: "cat"}, {Id: 1, Type: "dog"}},
+			Message: "positive test [ Url map[id:[2 1]] Result [{2 cat} {1 dog}] ] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"type": {"dog", "cat"}},
+			Result:  []controllers.AnimalType{{Id: 1, Type: "dog"}, {Id: 2, Type: "cat"}},
+			Message: "positive test [ Url map[type:[dog cat]] Result [{1 dog} {2 cat}] ] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"type": {"parrot"}},
+			Result:  []controllers.AnimalType{{}},
+			Message: "negative test [ Url map[type:[parrot]] Result [miss] ] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": {"1", "3"}},
+			Result:  []controllers.AnimalType{{Id: 1, Type: "dog"}, {}},
+			Message: "negative test [ Url map[id:[1 3]] Result [{1 dog} miss] ] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": {"1", "fail"}},
+			IsErr:   true,
+			Message: "negative test [ Url map[id:[1 fail]] malformed id ] failed",
+			Crit:    true,
+		},
+	}
+
+	for _, val := range arr {
+		db.batchCalls = 0
+		result, err := getAnimalTypeQueryData(ctx, val.Url, db, log)
+		if (err != nil) != val.IsErr || (err == nil && !compare(result, val.Result)) || (err == nil && db.batchCalls != 1) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestGetAnimalType(t *testing.T) {
+	type getTest struct {
+		Url     string
+		Status  int
+		Code    string
+		Message string
+		Crit    bool
+	}
+
+	var fail = false
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &fakeDB{
+		animalTypeId:   map[int]string{1: "dog", 2: "cat"},
+		animalTypeType: map[string]int{"dog": 1, "cat": 2},
+		failId: map[int]error{
+			13: fmt.Errorf("bad DB query: %w", controllers.ErrUnavailable),
+			66: fmt.Errorf("bad DB query: no such table: animal_type"),
+		},
+	}
+	var h = &Handler{getter: db, log: log}
+	var arr = []getTest{
+		{Url: "/animal_type?id=1", Status: http.StatusOK, Message: "positive test [single hit] failed", Crit: true},
+		{Url: "/animal_type?id=3", Status: http.StatusNotFound, Code: api.CodeNotFound, Message: "negative test [single miss] failed", Crit: true},
+		{Url: "/animal_type?type=parrot", Status: http.StatusNotFound, Code: api.CodeNotFound, Message: "negative test [single type miss] failed", Crit: true},
+		{Url: "/animal_type?id=fail", Status: http.StatusBadRequest, Code: api.CodeValidation, Message: "negative test [malformed id] failed", Crit: true},
+		{Url: "/animal_type?id=1&type=dog", Status: http.StatusBadRequest, Code: api.CodeValidation, Message: "negative test [ambiguous query] failed", Crit: true},
+		{Url: "/animal_type?id=1&id=3", Status: http.StatusMultiStatus, Message: "positive test [batch with a miss] failed", Crit: true},
+		{Url: "/animal_type?id=13", Status: http.StatusServiceUnavailable, Code: api.CodeUnavailable, Message: "negative test [repo unavailable] failed", Crit: true},
+		{Url: "/animal_type?id=1&id=66", Status: http.StatusInternalServerError, Code: api.CodeInternal, Message: "negative test [repo failure in batch] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		var p api.Problem
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, val.Url, nil))
+		if val.Code != "" {
+			_ = json.NewDecoder(w.Body).Decode(&p)
+		}
+		if w.Code != val.Status || p.Code != val.Code {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, fmt.Sprintf("got status %d code [%s], expected %d [%s]", w.Code, p.Code, val.Status, val.Code))
+		}
+	}
+
+	// batch reports every item in request order
+	var items []struct {
+		Status  int
+		Item    controllers.AnimalType
+		Problem *api.Problem
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/animal_type?id=3&id=1", nil))
+	if err := json.NewDecoder(w.Body).Decode(&items); err != nil {
+		t.Fatalf("cannot decode batch response: %s", err.Error())
+	}
+	if len(items) != 2 || items[0].Status != http.StatusNotFound || items[0].Problem == nil || items[0].Problem.Code != api.CodeNotFound ||
+		items[1].Status != http.StatusOK || items[1].Item.Type != "dog" {
+		fail = true
+		t.Logf("crit: true; positive test [batch items] failed; got %+v", items)
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,49 @@
+package AnimalType
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"strings"
+)
+
+// animalTypeReadBody decodes AnimalType object from the request body
+func animalTypeReadBody(r *http.Request) (controllers.AnimalType, error) {
+	var a controllers.AnimalType
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		return a, fmt.Errorf("cannot decode request body: %w", err)
+	}
+	a.Type = strings.TrimSpace(a.Type)
+	if a.Type == "" {
+		return a, api.FieldError{Field: "Type", Detail: "must not be empty"}
+	}
+	if a.Id < 0 {
+		return a, api.FieldError{Field: "Id", Detail: "must not be negative"}
+	}
+	return a, nil
+}
+
+func (h *Handler) postAnimalType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	a, err := animalTypeReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.writer.AnimalTypeCreate(ctx, a, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusCreated, result, l)
+}
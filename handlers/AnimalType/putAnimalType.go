@@ -0,0 +1,61 @@
+package AnimalType
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+)
+
+// animalTypeIdQuery is the query contract of requests addressing a single animal_type
+var animalTypeIdQuery = handlers.QuerySchema{
+	Params: []handlers.Param{{Name: "id", Type: handlers.ParamInt, Range: handlers.Positive}},
+	Rules:  []handlers.Rule{{Mode: handlers.ModeRequired, Params: []string{"id"}}},
+}
+
+// animalTypeIdFromQuery returns single integer id from the request URL
+func animalTypeIdFromQuery(vals url.Values) (int, error) {
+	if err := animalTypeIdQuery.Validate(vals); err != nil {
+		return 0, err
+	}
+	return handlers.QueryInt(vals, "id"), nil
+}
+
+// putAnimalType addresses the record by the 'id' query parameter, see putAnimalTypeId
+func (h *Handler) putAnimalType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	id, err := animalTypeIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.putAnimalTypeId(ctx, w, r, id, l)
+}
+
+// putAnimalTypeId handles both PUT and PATCH, since type is the only mutable field
+func (h *Handler) putAnimalTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	a, err := animalTypeReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if a.Id != 0 && a.Id != id {
+		handlers.BadRequest(w, r, l, api.FieldError{Field: "Id", Detail: fmt.Sprintf("[%d] differs from the addressed id [%d]", a.Id, id)})
+		return
+	}
+	a.Id = id
+
+	result, err := h.writer.AnimalTypeUpdate(ctx, a, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
@@ -0,0 +1,93 @@
+package AnimalType
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// animalTypeListQuery is the query contract of the v1 list
+var animalTypeListQuery = handlers.QuerySchema{List: &controllers.AnimalTypeListSpec}
+
+// getAnimalTypeId responds with the animal type, 404 in case it does not exist
+func (h *Handler) getAnimalTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	result, err := h.getter.AnimalTypeGetById(ctx, id, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
+
+// V1Routes returns routes of animal types in the v1 resource API, paths are relative to the version prefix
+func (h *Handler) V1Routes() []handlers.Route {
+	var one = []handlers.Response{{Status: http.StatusOK, Description: "The animal type", Bodies: []any{controllers.AnimalType{}}}}
+	var put = func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		h.putAnimalTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+	}
+
+	return []handlers.Route{
+		{
+			Path:    "/animal-types",
+			Summary: "Animal types",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "List animal types",
+					Query:     animalTypeListQuery,
+					Responses: []handlers.Response{{Status: http.StatusOK, Description: "Page of the list", Bodies: []any{handlers.ListPage{Items: []controllers.AnimalType{}}}, List: true}},
+					Handler:   h.getAnimalTypeList,
+				},
+				{
+					Method:    http.MethodPost,
+					Summary:   "Create animal type",
+					Body:      controllers.AnimalType{},
+					Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created animal type", Bodies: []any{controllers.AnimalType{}}}},
+					Handler:   h.postAnimalType,
+				},
+			},
+		},
+		{
+			Path:    "/animal-types/{id}",
+			Summary: "Animal type",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "Get animal type",
+					Path:      handlers.PathId,
+					Responses: one,
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.getAnimalTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+				{
+					Method:    http.MethodPut,
+					Summary:   "Replace animal type",
+					Path:      handlers.PathId,
+					Body:      controllers.AnimalType{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodPatch,
+					Summary:   "Replace animal type, same as PUT since type is the only mutable field",
+					Path:      handlers.PathId,
+					Body:      controllers.AnimalType{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodDelete,
+					Summary:   "Delete animal type",
+					Path:      handlers.PathId,
+					Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.deleteAnimalTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// Package Audit serves the audit log. The log is written by the repos along with every change, so the API reads it only
+package Audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// auditListQuery is the query contract of the audit log
+var auditListQuery = handlers.QuerySchema{List: &controllers.AuditListSpec}
+
+// Handler handles reads of the audit log
+type Handler struct {
+	reader controllers.AuditReader
+	log    *slog.Logger
+}
+
+// New returns Handler built from app. Returns error in case any dependency is missing
+func New(app *handlers.App) (*Handler, error) {
+	if app == nil || app.AuditReader == nil || app.Log == nil {
+		return nil, fmt.Errorf("audit handler requires AuditReader and logger")
+	}
+	return &Handler{reader: app.AuditReader, log: app.Log}, nil
+}
+
+// getAuditList responds with a page of the audit log
+func (h *Handler) getAuditList(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	q, err := handlers.ParseList(r.URL.Query(), controllers.AuditListSpec)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	items, after, err := h.reader.AuditList(ctx, q, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.WriteList(w, r, q, items, after, l)
+}
+
+// V1Routes returns routes of the audit log in the v1 resource API, paths are relative to the version prefix
+func (h *Handler) V1Routes() []handlers.Route {
+	return []handlers.Route{
+		{
+			Path:    "/audit",
+			Summary: "Audit log",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "List changes of the records, sort=-id lists the latest first",
+					Query:     auditListQuery,
+					Responses: []handlers.Response{{Status: http.StatusOK, Description: "Page of the log", Bodies: []any{handlers.ListPage{Items: []controllers.AuditEntry{}}}, List: true}},
+					Handler:   h.getAuditList,
+				},
+			},
+		},
+	}
+}
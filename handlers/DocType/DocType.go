@@ -6,39 +6,94 @@ package DocType
 
 import (
 	"fmt"
-	"github.com/google/uuid"
 	"log/slog"
-	"mis-catanddog/repos"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/lg"
 	"net/http"
 )
 
-// DocType handles CRUD operation for the /doc_type url.
-// It receives DB object of type interfaces.DB from the request context.
-func DocType(w http.ResponseWriter, r *http.Request) {
-	// get logger
-	log, ok := (r.Context().Value("logger")).(*slog.Logger)
-	if !ok {
-		// how to log without a logger?
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	log = log.With("ID", uuid.New())
+// route documents the /doc_type url
+var route = handlers.Route{
+	Path:    "/doc_type",
+	Summary: "Doc types",
+	Operations: []handlers.Operation{
+		{
+			Method:  http.MethodGet,
+			Summary: "List or look up doc types",
+			Query:   getDocTypeQuery,
+			Responses: []handlers.Response{
+				{Status: http.StatusOK, Description: "Page of the list, or the looked up doc type", Bodies: []any{handlers.ListPage{Items: []controllers.DocType{}}, []controllers.DocType{}}, List: true},
+				{Status: http.StatusMultiStatus, Description: "Per-item results of a batch lookup", Bodies: []any{[]handlers.BatchItem{{Item: controllers.DocType{}}}}},
+			},
+		},
+		{
+			Method:    http.MethodPost,
+			Summary:   "Create doc type",
+			Body:      controllers.DocType{},
+			Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created doc type", Bodies: []any{controllers.DocType{}}}},
+		},
+		{
+			Method:    http.MethodPut,
+			Summary:   "Replace doc type",
+			Query:     docTypeIdQuery,
+			Body:      controllers.DocType{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated doc type", Bodies: []any{controllers.DocType{}}}},
+		},
+		{
+			Method:    http.MethodPatch,
+			Summary:   "Replace doc type, same as PUT since doc is the only mutable field",
+			Query:     docTypeIdQuery,
+			Body:      controllers.DocType{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated doc type", Bodies: []any{controllers.DocType{}}}},
+		},
+		{
+			Method:    http.MethodDelete,
+			Summary:   "Delete doc type",
+			Query:     docTypeIdQuery,
+			Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+		},
+	},
+}
 
-	log.Info("request", "Method", r.Method, "Host", r.Host, "URL", r.URL, "Headers", r.Header)
+// allowedMethods lists methods served by the /doc_type url
+var allowedMethods = route.Methods()
+
+// Handler handles CRUD operation for the /doc_type url
+type Handler struct {
+	getter controllers.DocTypeGetter
+	writer controllers.DocTypeWriter
+	log    *slog.Logger
+}
 
-	// get repo
-	db, ok := (r.Context().Value("db")).(repos.DB)
-	if !ok {
-		log.Error("cannot get DB object from context")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+// New returns Handler built from app. Returns error in case any dependency is missing
+func New(app *handlers.App) (*Handler, error) {
+	if app == nil || app.DocTypeGetter == nil || app.DocTypeWriter == nil || app.Log == nil {
+		return nil, fmt.Errorf("doc type handler requires DocTypeGetter, DocTypeWriter and logger")
 	}
+	return &Handler{getter: app.DocTypeGetter, writer: app.DocTypeWriter, log: app.Log}, nil
+}
+
+// Route documents the /doc_type url
+func (h *Handler) Route() handlers.Route {
+	return route
+}
+
+// ServeHTTP selects handler by the request method
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := lg.FromContext(r.Context(), h.log)
 
 	// select handler
 	switch r.Method {
 	case http.MethodGet:
-		getDocType(r.Context(), w, r, log, db)
+		h.getDocType(r.Context(), w, r, log)
+	case http.MethodPost:
+		h.postDocType(r.Context(), w, r, log)
+	case http.MethodPut, http.MethodPatch:
+		h.putDocType(r.Context(), w, r, log)
+	case http.MethodDelete:
+		h.deleteDocType(r.Context(), w, r, log)
 	default:
-		log.Error(fmt.Sprintf("unexpected method %s", r.Method))
+		handlers.MethodNotAllowed(w, r, log, allowedMethods)
 	}
 }
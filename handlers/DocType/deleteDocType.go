@@ -0,0 +1,28 @@
+package DocType
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// deleteDocType addresses the record by the 'id' query parameter, see deleteDocTypeId
+func (h *Handler) deleteDocType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	id, err := docTypeIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.deleteDocTypeId(ctx, w, r, id, l)
+}
+
+// deleteDocTypeId deletes the record and responds with http.StatusNoContent
+func (h *Handler) deleteDocTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	if err := h.writer.DocTypeDelete(ctx, id, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
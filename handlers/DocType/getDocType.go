@@ -2,86 +2,157 @@ package DocType
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mis-catanddog/api"
 	"mis-catanddog/controllers"
-	"mis-catanddog/repos"
+	"mis-catanddog/handlers"
 	"net/http"
 	"net/url"
 	"strconv"
 )
 
-// getDocTypeValidateUrl validates request URL
-func getDocTypeValidateUrl(vals url.Values) error {
-	_, okDoc := vals["doc"]
-	_, okId := vals["id"]
-	if (!okDoc && !okId) || (okDoc && okId) {
-		return fmt.Errorf("ambiguous query; 'doc' and 'id' either together or not present; query [%s]", vals)
-	}
-	return nil
+// docTypeLookup is the result of a single lookup. key is the requested id or doc
+type docTypeLookup struct {
+	key  string
+	item controllers.DocType
+	err  error
+}
+
+// docTypeBatchMax caps the number of values looked up by a single request
+const docTypeBatchMax = 100
+
+// getDocTypeQuery is the query contract of GET. Neither 'doc' nor 'id' means the list is requested
+var getDocTypeQuery = handlers.QuerySchema{
+	Params: []handlers.Param{
+		{Name: "id", Type: handlers.ParamInt, MaxCount: docTypeBatchMax},
+		{Name: "doc", Type: handlers.ParamString, MaxCount: docTypeBatchMax},
+	},
+	Rules: []handlers.Rule{{Mode: handlers.ModeOneOf, Params: []string{"id", "doc"}}},
+	List:  &controllers.DocTypeListSpec,
 }
 
-// getDocTypeQueryData returns results to hand over to client
-func getDocTypeQueryData(ctx context.Context, vals url.Values, db controllers.DocTypeGetter, l *slog.Logger) []controllers.DocType {
-	var result []controllers.DocType
-	valDoc, okDoc := vals["doc"]
-	if okDoc {
-		for _, val := range valDoc {
-			result = append(result, db.DocTypeGetByDoc(ctx, val, l))
+// getDocTypeIds converts every 'id' value to an integer
+func getDocTypeIds(vals url.Values) ([]int, error) {
+	var ids []int
+	for _, val := range vals["id"] {
+		intVal, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, api.FieldError{Field: "id", Detail: fmt.Sprintf("failed to convert [%s] to an integer", val)}
 		}
-	} else {
-		valId, _ := vals["id"]
-		for _, val := range valId {
-			intVal, err := strconv.Atoi(val)
-			if err != nil {
-				result = append(result, controllers.DocType{Id: 0, Doc: "", Err: fmt.Sprintf("failed to convert id [%s] to an integer", val)})
-				return result
-			}
-			result = append(result, db.DocTypeGetById(ctx, intVal, l))
+		ids = append(ids, intVal)
+	}
+	return ids, nil
+}
+
+// getDocTypeLookups pairs looked up items with the requested keys. Id 0 means a miss
+func getDocTypeLookups(keys []string, items []controllers.DocType, err error) []docTypeLookup {
+	var result = make([]docTypeLookup, 0, len(keys))
+	for i, key := range keys {
+		switch {
+		case err != nil:
+			result = append(result, docTypeLookup{key: key, err: err})
+		case i >= len(items) || items[i].Id == 0:
+			result = append(result, docTypeLookup{key: key, err: controllers.ErrNotFound})
+		default:
+			result = append(result, docTypeLookup{key: key, item: items[i]})
 		}
 	}
 	return result
 }
 
-// getDocTypeHideInternals hides any possible error details
-func getDocTypeHideInternals(result []controllers.DocType, l *slog.Logger) {
-	ln := len(result)
-	for i := 0; i < ln; i++ {
-		if result[i].Err != "" {
-			result[i].Err = "Bad request"
+// getDocTypeQueryData looks up every requested value with a single repo call, results follow request order.
+// vals are expected to match getDocTypeQuery. Returns error in case any id is malformed, before querying the repo
+func getDocTypeQueryData(ctx context.Context, vals url.Values, db controllers.DocTypeGetter, l *slog.Logger) ([]docTypeLookup, error) {
+	if valDoc, okDoc := vals["doc"]; okDoc {
+		items, err := db.DocTypeGetByDocs(ctx, valDoc, l)
+		return getDocTypeLookups(valDoc, items, err), nil
+	}
+
+	ids, err := getDocTypeIds(vals)
+	if err != nil {
+		return nil, err
+	}
+	items, err := db.DocTypeGetByIds(ctx, ids, l)
+	return getDocTypeLookups(vals["id"], items, err), nil
+}
+
+// getDocTypeFailure returns the first lookup error that is not a miss. Such an error fails the whole request
+func getDocTypeFailure(result []docTypeLookup) error {
+	for _, val := range result {
+		if val.err != nil && !errors.Is(val.err, controllers.ErrNotFound) {
+			return val.err
 		}
-		// id = 0 means empty result for the query
-		if result[i].Id == 0 {
-			result[i].Err = "Empty result"
+	}
+	return nil
+}
+
+// getDocTypeBatch converts lookups to per-item results. Internal error details are not shared
+func getDocTypeBatch(r *http.Request, result []docTypeLookup) []handlers.BatchItem {
+	var items = make([]handlers.BatchItem, 0, len(result))
+	for _, val := range result {
+		if val.err != nil {
+			p := handlers.RepoProblem(r, val.err)
+			if errors.Is(val.err, controllers.ErrNotFound) {
+				p.Detail = fmt.Sprintf("doc_type [%s] does not exist", val.key)
+			}
+			items = append(items, handlers.BatchItem{Status: p.Status, Problem: &p})
+			continue
 		}
+		items = append(items, handlers.BatchItem{Status: http.StatusOK, Item: val.item})
 	}
+	return items
 }
 
-func getDocType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger, db repos.DB) {
-	// validate URL query
-	if err := getDocTypeValidateUrl(r.URL.Query()); err != nil {
-		l.Error(err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+// getDocTypeList responds with a page of the list
+func (h *Handler) getDocTypeList(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	q, err := handlers.ParseList(r.URL.Query(), controllers.DocTypeListSpec)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	items, after, err := h.getter.DocTypeList(ctx, q, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
 		return
 	}
+	handlers.WriteList(w, r, q, items, after, l)
+}
 
-	// convert to controller
-	controller, ok := db.(controllers.DocTypeGetter)
-	if !ok {
-		l.Error("object of type [DB] interface failed to covert to [DocTypeGetter] interface")
-		w.WriteHeader(http.StatusInternalServerError)
+// getDocType responds with 200 and a single item array for single value lookups, 404 in case it missed,
+// and with 207 and per-item statuses for batch lookups. Repo failures fail the whole request
+func (h *Handler) getDocType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	// validate URL query
+	vals := r.URL.Query()
+	if err := getDocTypeQuery.Validate(vals); err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if !vals.Has("doc") && !vals.Has("id") {
+		h.getDocTypeList(ctx, w, r, l)
+		return
 	}
 
 	// get results
-	result := getDocTypeQueryData(ctx, r.URL.Query(), controller, l)
+	result, err := getDocTypeQueryData(ctx, r.URL.Query(), h.getter, l)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if err := getDocTypeFailure(result); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
 
 	// return to caller
-	getDocTypeHideInternals(result, l)
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(result); err != nil {
-		l.Error(fmt.Errorf("cannot write responce to caller: %w", err).Error())
-		w.WriteHeader(http.StatusBadRequest)
+	if len(result) > 1 {
+		handlers.WriteBatch(w, r, getDocTypeBatch(r, result), l)
+		return
+	}
+	if result[0].err != nil {
+		handlers.RepoError(w, r, l, result[0].err)
 		return
 	}
+	handlers.Respond(w, r, http.StatusOK, []controllers.DocType{result[0].item}, l)
 }
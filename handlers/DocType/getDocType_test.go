@@ -2,47 +2,82 @@ package DocType
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"mis-catanddog/api"
 	"mis-catanddog/controllers"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 )
 
 type fakeDB struct {
 	docTypeId  map[int]string
 	docTypeDoc map[string]int
+	// failId makes lookups of the id fail with the error
+	failId map[int]error
+	// batchCalls counts batch lookups
+	batchCalls int
 }
 
-func (f *fakeDB) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) controllers.DocType {
+func (f *fakeDB) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.DocType, error) {
+	if err, ok := f.failId[id]; ok {
+		return controllers.DocType{}, err
+	}
 	val, ok := f.docTypeId[id]
 	if !ok {
-		return controllers.DocType{Id: 0, Doc: "", Err: ""}
+		return controllers.DocType{}, controllers.ErrNotFound
 	}
-	return controllers.DocType{Id: id, Doc: val, Err: ""}
+	return controllers.DocType{Id: id, Doc: val}, nil
 }
 
-func (f *fakeDB) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) controllers.DocType {
+func (f *fakeDB) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) (controllers.DocType, error) {
 	val, ok := f.docTypeDoc[doc]
 	if !ok {
-		return controllers.DocType{Id: 0, Doc: "", Err: ""}
+		return controllers.DocType{}, controllers.ErrNotFound
 	}
-	return controllers.DocType{Id: val, Doc: doc, Err: ""}
+	return controllers.DocType{Id: val, Doc: doc}, nil
 }
 
-/*
-func (f *fakeDB) New(uri string, timeout time.Duration) error {
-	return nil
+func (f *fakeDB) DocTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.DocType, error) {
+	var result []controllers.DocType
+	f.batchCalls++
+	for _, id := range ids {
+		if err, ok := f.failId[id]; ok {
+			return nil, err
+		}
+		doc, ok := f.docTypeId[id]
+		if !ok {
+			result = append(result, controllers.DocType{})
+			continue
+		}
+		result = append(result, controllers.DocType{Id: id, Doc: doc})
+	}
+	return result, nil
 }
-func (f *fakeDB) Get(ctx context.Context, r repos.DbReq) (*sql.Rows, error) {
-	return nil, nil
+
+func (f *fakeDB) DocTypeGetByDocs(ctx context.Context, docs []string, l *slog.Logger) ([]controllers.DocType, error) {
+	var result []controllers.DocType
+	f.batchCalls++
+	for _, doc := range docs {
+		if _, ok := f.docTypeDoc[doc]; !ok {
+			result = append(result, controllers.DocType{})
+			continue
+		}
+		result = append(result, controllers.DocType{Id: f.docTypeDoc[doc], Doc: doc})
+	}
+	return result, nil
 }
-func (f *fakeDB) Exec(ctx context.Context, rs []repos.DbReq) error {
-	return nil
+
+func (f *fakeDB) DocTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.DocType, []string, error) {
+	return nil, nil, nil
 }
-func (f *fakeDB) Close() {}
-*/
 
-func TestGetDocTypeValidateUrl(t *testing.T) {
+func TestGetDocTypeQuery(t *testing.T) {
 	type urlTest struct {
 		Url     url.Values
 		Err     string
@@ -72,19 +107,43 @@ func TestGetDocTypeValidateUrl(t *testing.T) {
 				"id":  {"1", "2"},
 				"doc": {"passport", "veterenary pasword"},
 			},
-			Err:     "ambiguous query; 'doc' and 'id' either together or not present; query [map[doc:[passport veterenary pasword] id:[1 2]]]",
+			Err:     "'id, doc' at most one of the parameters expected, got id, doc",
 			Message: "negative test ['doc' and 'id' both preset] failed",
 			Crit:    true,
 		},
+		{
+			Url:     map[string][]string{"limit": {"2"}, "doc[like]": {"pass*"}},
+			Err:     "",
+			Message: "positive test [list params] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": {"1", "fail"}},
+			Err:     "'id' failed to convert [fail] to an integer",
+			Message: "negative test [malformed id] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"id": strings.Split(strings.Repeat("1,", docTypeBatchMax+1), ",")[:docTypeBatchMax+1]},
+			Err:     fmt.Sprintf("'id' at most %d values expected, got %d", docTypeBatchMax, docTypeBatchMax+1),
+			Message: "negative test [too many ids] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"name": {"passport"}},
+			Err:     "'name' unknown parameter",
+			Message: "negative test [unknown parameter] failed",
+			Crit:    true,
+		},
 	}
 
 	for _, val := range arr {
-		err := getDocTypeValidateUrl(val.Url)
-		if err != nil && err.Error() != val.Err {
+		err := getDocTypeQuery.Validate(val.Url)
+		if (err == nil && val.Err != "") || (err != nil && err.Error() != val.Err) {
 			if val.Crit {
 				fail = true
 			}
-			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, err.Error())
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
 		}
 	}
 
@@ -93,14 +152,18 @@ func TestGetDocTypeValidateUrl(t *testing.T) {
 	}
 }
 
-func compare(a, b []controllers.DocType) bool {
+func compare(a []docTypeLookup, b []controllers.DocType) bool {
 	var ln = len(a)
 	if ln != len(b) {
 		return false
 	}
 
 	for i := 0; i < ln; i++ {
-		if a[i] != b[i] {
+		if a[i].item != b[i] {
+			return false
+		}
+		// empty expected item means a miss
+		if (b[i].Id == 0) != errors.Is(a[i].err, controllers.ErrNotFound) {
 			return false
 		}
 	}
@@ -112,6 +175,7 @@ func TestGetDocTypeQueryData(t *testing.T) {
 	type queryTest struct {
 		Url     url.Values
 		Result  []controllers.DocType
+		IsErr   bool
 		Message string
 		Crit    bool
 	}
@@ -131,123 +195,51 @@ func TestGetDocTypeQueryData(t *testing.T) {
 	}
 	var arr = []queryTest{
 		{
-			Url: map[string][]string{
-				"id": {"1"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  1,
-					Doc: "passport",
-					Err: "",
-				},
-			},
-			Message: "positive test [ Url map[id:[1]] Result [{1 passport }] ] failed",
-			Crit:    true,
-		},
-		{
-			Url: map[string][]string{
-				"id": {"1", "2"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  1,
-					Doc: "passport",
-					Err: "",
-				},
-				{
-					Id:  2,
-					Doc: "veterinary passport",
-					Err: "",
-				},
-			},
-			Message: "positive test [ Url map[id:[1 2]] Result [{1 passport } {2 veterinary passport }] ] failed",
+			Url:     map[string][]string{"id": {"1"}},
+			Result:  []controllers.DocType{{Id: 1, Doc: "passport"}},
+			Message: "positive test [ Url map[id:[1]] Result [{1 passport}] ] failed",
 			Crit:    true,
 		},
 		{
-			Url: map[string][]string{
-				"doc": {"passport"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  1,
-					Doc: "passport",
-					Err: "",
-				},
-			},
-			Message: "positive test [ Url map[doc:[passport]] Result [{1 passport }] ] failed",
+			Url:     map[string][]string{"id": {"2", "1"}},
+			Result:  []controllers.DocType{{Id: 2, Doc: "veterinary passport"}, {Id: 1, Doc: "passport"}},
+			Message: "positive test [ Url map[id:[2 1]] Result [{2 veterinary passport} {1 passport}] ] failed",
 			Crit:    true,
 		},
 		{
-			Url: map[string][]string{
-				"doc": {"passport", "veterinary passport"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  1,
-					Doc: "passport",
-					Err: "",
-				},
-				{
-					Id:  2,
-					Doc: "veterinary passport",
-					Err: "",
-				},
-			},
-			Message: "positive test [ Url map[doc:[passport veterinary passport]] Result [{1 passport } {2 veterinary passport }] ] failed",
+			Url:     map[string][]string{"doc": {"passport", "veterinary passport"}},
+			Result:  []controllers.DocType{{Id: 1, Doc: "passport"}, {Id: 2, Doc: "veterinary passport"}},
+			Message: "positive test [ Url map[doc:[passport veterinary passport]] Result [{1 passport} {2 veterinary passport}] ] failed",
 			Crit:    true,
 		},
 		{
-			Url: map[string][]string{
-				"doc": {"document"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  0,
-					Doc: "",
-					Err: "",
-				},
-			},
-			Message: "negative test [ Url map[doc:[document]] Result [{0  Empty result}] ] failed",
+			Url:     map[string][]string{"doc": {"document"}},
+			Result:  []controllers.DocType{{}},
+			Message: "negative test [ Url map[doc:[document]] Result [miss] ] failed",
 			Crit:    true,
 		},
 		{
-			Url: map[string][]string{
-				"id": {"3"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  0,
-					Doc: "",
-					Err: "",
-				},
-			},
-			Message: "negative test [ Url map[id:[3]] Result [{0  Empty result}] ] failed",
+			Url:     map[string][]string{"id": {"1", "3"}},
+			Result:  []controllers.DocType{{Id: 1, Doc: "passport"}, {}},
+			Message: "negative test [ Url map[id:[1 3]] Result [{1 passport} miss] ] failed",
 			Crit:    true,
 		},
 		{
-			Url: map[string][]string{
-				"id": {"fail"},
-			},
-			Result: []controllers.DocType{
-				{
-					Id:  0,
-					Doc: "",
-					Err: "failed to convert id [fail] to an integer1",
-				},
-			},
-			Message: "negative test [ Url map[id:[fail]] Result [{0  failed to convert id [fail] to an integer}] ] failed",
+			Url:     map[string][]string{"id": {"1", "fail"}},
+			IsErr:   true,
+			Message: "negative test [ Url map[id:[1 fail]] malformed id ] failed",
 			Crit:    true,
 		},
 	}
 
 	for _, val := range arr {
-		result := getDocTypeQueryData(ctx, val.Url, db, log)
-		if !compare(result, val.Result) {
+		db.batchCalls = 0
+		result, err := getDocTypeQueryData(ctx, val.Url, db, log)
+		if (err != nil) != val.IsErr || (err == nil && !compare(result, val.Result)) || (err == nil && db.batchCalls != 1) {
 			if val.Crit {
 				fail = true
 			}
-			//t.Logf("%v || %v || %v", result, val.Result, val.Url)
-			t.Logf("crit: %t; %s", val.Crit, val.Message)
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
 		}
 	}
 
@@ -256,61 +248,70 @@ func TestGetDocTypeQueryData(t *testing.T) {
 	}
 }
 
-func TestGetDocTypeHideInternals(t *testing.T) {
-	var fail = false
-	var log = &slog.Logger{}
-	var arrMutated = []controllers.DocType{
-		{
-			Id:  1,
-			Doc: "passport",
-			Err: "",
-		},
-		{
-			Id:  0,
-			Doc: "",
-			Err: "",
-		},
-		{
-			Id:  5,
-			Doc: "",
-			Err: "error",
-		},
+func TestGetDocType(t *testing.T) {
+	type getTest struct {
+		Url     string
+		Status  int
+		Code    string
+		Message string
+		Crit    bool
 	}
-	var arrInitial = make([]controllers.DocType, len(arrMutated))
-	var arrResult = []controllers.DocType{
-		{
-			Id:  1,
-			Doc: "passport",
-			Err: "",
-		},
-		{
-			Id:  0,
-			Doc: "",
-			Err: "Empty result",
-		},
-		{
-			Id:  5,
-			Doc: "",
-			Err: "Bad request",
+
+	var fail = false
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &fakeDB{
+		docTypeId:  map[int]string{1: "passport", 2: "veterinary passport"},
+		docTypeDoc: map[string]int{"passport": 1, "veterinary passport": 2},
+		failId: map[int]error{
+			13: fmt.Errorf("bad DB query: %w", controllers.ErrUnavailable),
+			66: fmt.Errorf("bad DB query: no such table: doc_type"),
 		},
 	}
-	if len(arrMutated) != len(arrResult) && len(arrMutated) != len(arrInitial) {
-		t.Fatalf("All three initial arrays must be of the same len")
+	var h = &Handler{getter: db, writer: db, log: log}
+	var arr = []getTest{
+		{Url: "/doc_type?id=1", Status: http.StatusOK, Message: "positive test [single hit] failed", Crit: true},
+		{Url: "/doc_type?id=3", Status: http.StatusNotFound, Code: api.CodeNotFound, Message: "negative test [single miss] failed", Crit: true},
+		{Url: "/doc_type?doc=document", Status: http.StatusNotFound, Code: api.CodeNotFound, Message: "negative test [single doc miss] failed", Crit: true},
+		{Url: "/doc_type?id=fail", Status: http.StatusBadRequest, Code: api.CodeValidation, Message: "negative test [malformed id] failed", Crit: true},
+		{Url: "/doc_type?id=1&doc=passport", Status: http.StatusBadRequest, Code: api.CodeValidation, Message: "negative test [ambiguous query] failed", Crit: true},
+		{Url: "/doc_type?id=1&id=3", Status: http.StatusMultiStatus, Message: "positive test [batch with a miss] failed", Crit: true},
+		{Url: "/doc_type?id=13", Status: http.StatusServiceUnavailable, Code: api.CodeUnavailable, Message: "negative test [repo unavailable] failed", Crit: true},
+		{Url: "/doc_type?id=1&id=66", Status: http.StatusInternalServerError, Code: api.CodeInternal, Message: "negative test [repo failure in batch] failed", Crit: true},
 	}
-	copy(arrInitial, arrMutated)
-	getDocTypeHideInternals(arrMutated, log)
-	for i := 0; i < len(arrInitial); i++ {
-		if arrMutated[i] != arrResult[i] {
-			fail = true
-			t.Logf("input: %v; output: %v; expected: %v", arrInitial[i], arrMutated[i], arrResult[i])
+
+	for _, val := range arr {
+		var p api.Problem
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, val.Url, nil))
+		if val.Code != "" {
+			_ = json.NewDecoder(w.Body).Decode(&p)
+		}
+		if w.Code != val.Status || p.Code != val.Code {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, fmt.Sprintf("got status %d code [%s], expected %d [%s]", w.Code, p.Code, val.Status, val.Code))
 		}
 	}
 
+	// batch reports every item in request order
+	var items []struct {
+		Status  int
+		Item    controllers.DocType
+		Problem *api.Problem
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doc_type?id=3&id=1", nil))
+	if err := json.NewDecoder(w.Body).Decode(&items); err != nil {
+		t.Fatalf("cannot decode batch response: %s", err.Error())
+	}
+	if len(items) != 2 || items[0].Status != http.StatusNotFound || items[0].Problem == nil || items[0].Problem.Code != api.CodeNotFound ||
+		items[1].Status != http.StatusOK || items[1].Item.Doc != "passport" {
+		fail = true
+		t.Logf("crit: true; positive test [batch items] failed; got %+v", items)
+	}
+
 	if fail {
 		t.Fatalf("Critical tests failed")
 	}
 }
-
-func TestGetDocType(t *testing.T) {
-	// TODO: test
-}
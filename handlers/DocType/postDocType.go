@@ -0,0 +1,49 @@
+package DocType
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"strings"
+)
+
+// docTypeReadBody decodes DocType object from the request body
+func docTypeReadBody(r *http.Request) (controllers.DocType, error) {
+	var d controllers.DocType
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		return d, fmt.Errorf("cannot decode request body: %w", err)
+	}
+	d.Doc = strings.TrimSpace(d.Doc)
+	if d.Doc == "" {
+		return d, api.FieldError{Field: "Doc", Detail: "must not be empty"}
+	}
+	if d.Id < 0 {
+		return d, api.FieldError{Field: "Id", Detail: "must not be negative"}
+	}
+	return d, nil
+}
+
+func (h *Handler) postDocType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	d, err := docTypeReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.writer.DocTypeCreate(ctx, d, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusCreated, result, l)
+}
@@ -0,0 +1,121 @@
+package DocType
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func (f *fakeDB) DocTypeCreate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	if _, ok := f.docTypeDoc[d.Doc]; ok {
+		return controllers.DocType{}, controllers.ErrAlreadyExists
+	}
+	if d.Id == 0 {
+		d.Id = len(f.docTypeId) + 1
+	}
+	f.docTypeId[d.Id] = d.Doc
+	f.docTypeDoc[d.Doc] = d.Id
+	return d, nil
+}
+
+func (f *fakeDB) DocTypeUpdate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	old, ok := f.docTypeId[d.Id]
+	if !ok {
+		return controllers.DocType{}, controllers.ErrNotFound
+	}
+	delete(f.docTypeDoc, old)
+	f.docTypeId[d.Id] = d.Doc
+	f.docTypeDoc[d.Doc] = d.Id
+	return d, nil
+}
+
+func (f *fakeDB) DocTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	old, ok := f.docTypeId[id]
+	if !ok {
+		return controllers.ErrNotFound
+	}
+	delete(f.docTypeDoc, old)
+	delete(f.docTypeId, id)
+	return nil
+}
+
+func TestDocTypeWrite(t *testing.T) {
+	type writeTest struct {
+		Method  string
+		Url     string
+		Body    string
+		Status  int
+		Message string
+		Crit    bool
+	}
+
+	var fail = false
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &fakeDB{
+		docTypeId: map[int]string{
+			1: "passport",
+		},
+		docTypeDoc: map[string]int{
+			"passport": 1,
+		},
+	}
+	h, err := New(&handlers.App{DocTypeGetter: db, DocTypeWriter: db, Log: log})
+	if err != nil {
+		t.Fatalf("cannot create handler: %s", err.Error())
+	}
+	var arr = []writeTest{
+		{Method: http.MethodPost, Url: "/doc_type", Body: `{"Doc":"military passport"}`, Status: http.StatusCreated, Message: "positive test [POST new doc] failed", Crit: true},
+		{Method: http.MethodPost, Url: "/doc_type", Body: `{"Doc":"passport"}`, Status: http.StatusConflict, Message: "negative test [POST existing doc] failed", Crit: true},
+		{Method: http.MethodPost, Url: "/doc_type", Body: `{"Doc":"  "}`, Status: http.StatusBadRequest, Message: "negative test [POST empty doc] failed", Crit: true},
+		{Method: http.MethodPut, Url: "/doc_type?id=1", Body: `{"Doc":"foreign passport"}`, Status: http.StatusOK, Message: "positive test [PUT id 1] failed", Crit: true},
+		{Method: http.MethodPatch, Url: "/doc_type?id=7", Body: `{"Doc":"foreign passport"}`, Status: http.StatusNotFound, Message: "negative test [PATCH missing id] failed", Crit: true},
+		{Method: http.MethodPut, Url: "/doc_type?id=1", Body: `{"Id":2,"Doc":"foreign passport"}`, Status: http.StatusBadRequest, Message: "negative test [PUT ids mismatch] failed", Crit: true},
+		{Method: http.MethodDelete, Url: "/doc_type?id=fail", Status: http.StatusBadRequest, Message: "negative test [DELETE malformed id] failed", Crit: true},
+		{Method: http.MethodDelete, Url: "/doc_type?id=1", Status: http.StatusNoContent, Message: "positive test [DELETE id 1] failed", Crit: true},
+		{Method: http.MethodDelete, Url: "/doc_type?id=1", Status: http.StatusNotFound, Message: "negative test [DELETE deleted id 1] failed", Crit: true},
+		{Method: http.MethodHead, Url: "/doc_type?id=1", Status: http.StatusMethodNotAllowed, Message: "negative test [HEAD] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		r := httptest.NewRequest(val.Method, val.Url, strings.NewReader(val.Body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+		if w.Code != val.Status {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, fmt.Sprintf("got status %d, expected %d", w.Code, val.Status))
+		}
+		if val.Status == http.StatusMethodNotAllowed && w.Header().Get("Allow") == "" {
+			fail = true
+			t.Logf("crit: %t; %s; Allow header is not set", val.Crit, val.Message)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestNew(t *testing.T) {
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &fakeDB{}
+
+	if _, err := New(&handlers.App{DocTypeGetter: db, Log: log}); err == nil {
+		t.Fatalf("negative test [New without DocTypeWriter] failed")
+	}
+	if _, err := New(&handlers.App{DocTypeGetter: db, DocTypeWriter: db}); err == nil {
+		t.Fatalf("negative test [New without logger] failed")
+	}
+	if _, err := New(&handlers.App{DocTypeGetter: db, DocTypeWriter: db, Log: log}); err != nil {
+		t.Fatalf("positive test [New] failed; %s", err.Error())
+	}
+}
@@ -0,0 +1,61 @@
+package DocType
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+)
+
+// docTypeIdQuery is the query contract of requests addressing a single doc_type
+var docTypeIdQuery = handlers.QuerySchema{
+	Params: []handlers.Param{{Name: "id", Type: handlers.ParamInt, Range: handlers.Positive}},
+	Rules:  []handlers.Rule{{Mode: handlers.ModeRequired, Params: []string{"id"}}},
+}
+
+// docTypeIdFromQuery returns single integer id from the request URL
+func docTypeIdFromQuery(vals url.Values) (int, error) {
+	if err := docTypeIdQuery.Validate(vals); err != nil {
+		return 0, err
+	}
+	return handlers.QueryInt(vals, "id"), nil
+}
+
+// putDocType addresses the record by the 'id' query parameter, see putDocTypeId
+func (h *Handler) putDocType(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	id, err := docTypeIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.putDocTypeId(ctx, w, r, id, l)
+}
+
+// putDocTypeId handles both PUT and PATCH, since doc is the only mutable field
+func (h *Handler) putDocTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	d, err := docTypeReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if d.Id != 0 && d.Id != id {
+		handlers.BadRequest(w, r, l, api.FieldError{Field: "Id", Detail: fmt.Sprintf("[%d] differs from the addressed id [%d]", d.Id, id)})
+		return
+	}
+	d.Id = id
+
+	result, err := h.writer.DocTypeUpdate(ctx, d, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
@@ -0,0 +1,93 @@
+package DocType
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// docTypeListQuery is the query contract of the v1 list
+var docTypeListQuery = handlers.QuerySchema{List: &controllers.DocTypeListSpec}
+
+// getDocTypeId responds with the doc type, 404 in case it does not exist
+func (h *Handler) getDocTypeId(ctx context.Context, w http.ResponseWriter, r *http.Request, id int, l *slog.Logger) {
+	result, err := h.getter.DocTypeGetById(ctx, id, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
+
+// V1Routes returns routes of doc types in the v1 resource API, paths are relative to the version prefix
+func (h *Handler) V1Routes() []handlers.Route {
+	var one = []handlers.Response{{Status: http.StatusOK, Description: "The doc type", Bodies: []any{controllers.DocType{}}}}
+	var put = func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		h.putDocTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+	}
+
+	return []handlers.Route{
+		{
+			Path:    "/doc-types",
+			Summary: "Doc types",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "List doc types",
+					Query:     docTypeListQuery,
+					Responses: []handlers.Response{{Status: http.StatusOK, Description: "Page of the list", Bodies: []any{handlers.ListPage{Items: []controllers.DocType{}}}, List: true}},
+					Handler:   h.getDocTypeList,
+				},
+				{
+					Method:    http.MethodPost,
+					Summary:   "Create doc type",
+					Body:      controllers.DocType{},
+					Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created doc type", Bodies: []any{controllers.DocType{}}}},
+					Handler:   h.postDocType,
+				},
+			},
+		},
+		{
+			Path:    "/doc-types/{id}",
+			Summary: "Doc type",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "Get doc type",
+					Path:      handlers.PathId,
+					Responses: one,
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.getDocTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+				{
+					Method:    http.MethodPut,
+					Summary:   "Replace doc type",
+					Path:      handlers.PathId,
+					Body:      controllers.DocType{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodPatch,
+					Summary:   "Replace doc type, same as PUT since doc is the only mutable field",
+					Path:      handlers.PathId,
+					Body:      controllers.DocType{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodDelete,
+					Summary:   "Delete doc type",
+					Path:      handlers.PathId,
+					Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.deleteDocTypeId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+			},
+		},
+	}
+}
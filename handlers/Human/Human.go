@@ -0,0 +1,94 @@
+package Human
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/lg"
+	"net/http"
+)
+
+// route documents the /human url
+var route = handlers.Route{
+	Path:    "/human",
+	Summary: "Humans",
+	Operations: []handlers.Operation{
+		{
+			Method:  http.MethodGet,
+			Summary: "List or look up humans",
+			Query:   getHumanQuery,
+			Responses: []handlers.Response{
+				{Status: http.StatusOK, Description: "Page of the list, the human with doc_id, or search results", Bodies: []any{handlers.ListPage{Items: []controllers.Human{}}, controllers.Human{}, []controllers.Human{}}, List: true},
+			},
+		},
+		{
+			Method:    http.MethodPost,
+			Summary:   "Create human",
+			Body:      controllers.Human{},
+			Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created human", Bodies: []any{controllers.Human{}}}},
+		},
+		{
+			Method:    http.MethodPut,
+			Summary:   "Replace human",
+			Query:     humanDocIdQuery,
+			Body:      controllers.Human{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated human", Bodies: []any{controllers.Human{}}}},
+		},
+		{
+			Method:    http.MethodPatch,
+			Summary:   "Update non-empty fields of human set in the body",
+			Query:     humanDocIdQuery,
+			Body:      controllers.Human{},
+			Responses: []handlers.Response{{Status: http.StatusOK, Description: "Updated human", Bodies: []any{controllers.Human{}}}},
+		},
+		{
+			Method:    http.MethodDelete,
+			Summary:   "Delete human",
+			Query:     humanDocIdQuery,
+			Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+		},
+	},
+}
+
+// allowedMethods lists methods served by the /human url
+var allowedMethods = route.Methods()
+
+// Handler handles CRUD operation for the /human url
+type Handler struct {
+	humans   controllers.HumanRepo
+	docTypes controllers.DocTypeGetter
+	log      *slog.Logger
+}
+
+// New returns Handler built from app. Returns error in case any dependency is missing
+func New(app *handlers.App) (*Handler, error) {
+	if app == nil || app.HumanRepo == nil || app.DocTypeGetter == nil || app.Log == nil {
+		return nil, fmt.Errorf("human handler requires HumanRepo, DocTypeGetter and logger")
+	}
+	return &Handler{humans: app.HumanRepo, docTypes: app.DocTypeGetter, log: app.Log}, nil
+}
+
+// Route documents the /human url
+func (h *Handler) Route() handlers.Route {
+	return route
+}
+
+// ServeHTTP selects handler by the request method
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := lg.FromContext(r.Context(), h.log)
+
+	// select handler
+	switch r.Method {
+	case http.MethodGet:
+		h.getHuman(r.Context(), w, r, log)
+	case http.MethodPost:
+		h.postHuman(r.Context(), w, r, log)
+	case http.MethodPut, http.MethodPatch:
+		h.putHuman(r.Context(), w, r, log)
+	case http.MethodDelete:
+		h.deleteHuman(r.Context(), w, r, log)
+	default:
+		handlers.MethodNotAllowed(w, r, log, allowedMethods)
+	}
+}
@@ -0,0 +1,28 @@
+package Human
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// deleteHuman addresses the record by the 'doc_id' query parameter, see deleteHumanId
+func (h *Handler) deleteHuman(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	docId, err := humanDocIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.deleteHumanId(ctx, w, r, docId, l)
+}
+
+// deleteHumanId deletes the record and responds with http.StatusNoContent
+func (h *Handler) deleteHumanId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	if err := h.humans.HumanDelete(ctx, docId, l); err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package Human
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+)
+
+// humanSearchParams lists query parameters accepted by the search
+var humanSearchParams = []string{"doc_type", "first_name", "last_name", "birth_date"}
+
+// getHumanQuery is the query contract of GET. Either 'doc_id' or any of the search params are expected,
+// neither of them means the list is requested
+var getHumanQuery = handlers.QuerySchema{
+	Params: []handlers.Param{
+		{Name: "doc_id", Type: handlers.ParamInt, Range: handlers.Positive},
+		{Name: "doc_type", Type: handlers.ParamInt},
+		{Name: "first_name", Type: handlers.ParamString},
+		{Name: "last_name", Type: handlers.ParamString},
+		{Name: "birth_date", Type: handlers.ParamDate},
+	},
+	Rules: []handlers.Rule{
+		{Mode: handlers.ModeOneOf, Params: []string{"doc_id", "doc_type"}},
+		{Mode: handlers.ModeOneOf, Params: []string{"doc_id", "first_name"}},
+		{Mode: handlers.ModeOneOf, Params: []string{"doc_id", "last_name"}},
+		{Mode: handlers.ModeOneOf, Params: []string{"doc_id", "birth_date"}},
+	},
+	List: &controllers.HumanListSpec,
+}
+
+// getHumanFilter converts URL query matching getHumanQuery to the search filter
+func getHumanFilter(vals url.Values) controllers.HumanFilter {
+	return controllers.HumanFilter{
+		DocType:   handlers.QueryInt(vals, "doc_type"),
+		FirstName: vals.Get("first_name"),
+		LastName:  vals.Get("last_name"),
+		BirthDate: vals.Get("birth_date"),
+	}
+}
+
+// getHumanSearchRequested reports whether any of the legacy search params is present
+func getHumanSearchRequested(vals url.Values) bool {
+	for _, val := range humanSearchParams {
+		if vals.Has(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// getHumanList responds with a page of the list
+func (h *Handler) getHumanList(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	q, err := handlers.ParseList(r.URL.Query(), controllers.HumanListSpec)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	items, after, err := h.humans.HumanList(ctx, q, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.WriteList(w, r, q, items, after, l)
+}
+
+func (h *Handler) getHuman(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	// validate URL query
+	if err := getHumanQuery.Validate(r.URL.Query()); err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if !r.URL.Query().Has("doc_id") && !getHumanSearchRequested(r.URL.Query()) {
+		h.getHumanList(ctx, w, r, l)
+		return
+	}
+
+	// single record
+	if r.URL.Query().Has("doc_id") {
+		result, err := h.humans.HumanGet(ctx, handlers.QueryInt(r.URL.Query(), "doc_id"), l)
+		if err != nil {
+			handlers.RepoError(w, r, l, err)
+			return
+		}
+		handlers.Respond(w, r, http.StatusOK, result, l)
+		return
+	}
+
+	// search
+	result, err := h.humans.HumanSearch(ctx, getHumanFilter(r.URL.Query()), l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	if result == nil {
+		result = make([]controllers.Human, 0)
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
@@ -0,0 +1,80 @@
+package Human
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetHumanQuery(t *testing.T) {
+	type urlTest struct {
+		Url     url.Values
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []urlTest{
+		{
+			Url:     map[string][]string{"doc_id": {"1"}},
+			IsErr:   false,
+			Message: "positive test [doc_id 1] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"last_name": {"Iv"}, "first_name": {"Al"}},
+			IsErr:   false,
+			Message: "positive test [last_name Iv first_name Al] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"doc_id": {"1"}, "last_name": {"Iv"}},
+			IsErr:   true,
+			Message: "negative test ['doc_id' and search params both present] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"doc_id": {"1", "2"}},
+			IsErr:   true,
+			Message: "negative test [two doc_id] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{},
+			IsErr:   false,
+			Message: "positive test [empty query lists humans] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"birth_date": {"30.03.1992"}},
+			IsErr:   true,
+			Message: "negative test [malformed birth_date] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"doc_id": {"0"}},
+			IsErr:   true,
+			Message: "negative test [doc_id out of range] failed",
+			Crit:    true,
+		},
+		{
+			Url:     map[string][]string{"middle_name": {"Al"}},
+			IsErr:   true,
+			Message: "negative test [unknown parameter] failed",
+			Crit:    true,
+		},
+	}
+
+	for _, val := range arr {
+		err := getHumanQuery.Validate(val.Url)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
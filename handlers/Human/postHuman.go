@@ -0,0 +1,84 @@
+package Human
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// humanReadBody decodes Human object from the request body
+func humanReadBody(r *http.Request) (controllers.Human, error) {
+	var h controllers.Human
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		return h, fmt.Errorf("cannot decode request body: %w", err)
+	}
+	h.FirstName = strings.TrimSpace(h.FirstName)
+	h.MiddleName = strings.TrimSpace(h.MiddleName)
+	h.LastName = strings.TrimSpace(h.LastName)
+	return h, nil
+}
+
+// humanValidate checks Human object fields. doc_type must reference an existing doc_type record.
+// Errors other than api.FieldError mean the repo failed
+func humanValidate(ctx context.Context, h controllers.Human, db controllers.DocTypeGetter, l *slog.Logger) error {
+	if h.DocId <= 0 {
+		return api.FieldError{Field: "DocId", Detail: "must be a positive integer"}
+	}
+	if h.FirstName == "" {
+		return api.FieldError{Field: "FirstName", Detail: "must not be empty"}
+	}
+	if h.LastName == "" {
+		return api.FieldError{Field: "LastName", Detail: "must not be empty"}
+	}
+	birthDate, err := time.Parse(controllers.DateLayout, h.BirthDate)
+	if err != nil {
+		return api.FieldError{Field: "BirthDate", Detail: fmt.Sprintf("[%s] is not a %s date", h.BirthDate, controllers.DateLayout)}
+	}
+	if birthDate.After(time.Now()) {
+		return api.FieldError{Field: "BirthDate", Detail: fmt.Sprintf("[%s] is in the future", h.BirthDate)}
+	}
+	if h.DocType <= 0 {
+		return api.FieldError{Field: "DocType", Detail: "must be a positive integer"}
+	}
+	_, err = db.DocTypeGetById(ctx, h.DocType, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return api.FieldError{Field: "DocType", Detail: fmt.Sprintf("[%d] does not reference an existing doc_type", h.DocType)}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot look up doc_type [%d]: %w", h.DocType, err)
+	}
+	return nil
+}
+
+func (h *Handler) postHuman(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	human, err := humanReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+
+	if err := humanValidate(ctx, human, h.docTypes, l); err != nil {
+		handlers.InvalidRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.humans.HumanCreate(ctx, human, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusCreated, result, l)
+}
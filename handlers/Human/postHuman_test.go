@@ -0,0 +1,85 @@
+package Human
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"testing"
+)
+
+type fakeDocTypes map[int]string
+
+func (f fakeDocTypes) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.DocType, error) {
+	val, ok := f[id]
+	if !ok {
+		return controllers.DocType{}, controllers.ErrNotFound
+	}
+	return controllers.DocType{Id: id, Doc: val}, nil
+}
+
+func (f fakeDocTypes) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) (controllers.DocType, error) {
+	for id, val := range f {
+		if val == doc {
+			return controllers.DocType{Id: id, Doc: val}, nil
+		}
+	}
+	return controllers.DocType{}, controllers.ErrNotFound
+}
+
+func (f fakeDocTypes) DocTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.DocType, error) {
+	var result []controllers.DocType
+	for _, id := range ids {
+		d, _ := f.DocTypeGetById(ctx, id, l)
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (f fakeDocTypes) DocTypeGetByDocs(ctx context.Context, docs []string, l *slog.Logger) ([]controllers.DocType, error) {
+	var result []controllers.DocType
+	for _, doc := range docs {
+		d, _ := f.DocTypeGetByDoc(ctx, doc, l)
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (f fakeDocTypes) DocTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.DocType, []string, error) {
+	return nil, nil, nil
+}
+
+func TestHumanValidate(t *testing.T) {
+	type validateTest struct {
+		Human   controllers.Human
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var log = &slog.Logger{}
+	var db = fakeDocTypes{1: "passport"}
+	var valid = controllers.Human{DocId: 1234, DocType: 1, FirstName: "Alexey", LastName: "Ivanov", BirthDate: "1992-03-30"}
+	var arr = []validateTest{
+		{Human: valid, IsErr: false, Message: "positive test [valid human] failed", Crit: true},
+		{Human: humanMerge(valid, controllers.Human{DocType: 2}), IsErr: true, Message: "negative test [unknown doc_type] failed", Crit: true},
+		{Human: humanMerge(valid, controllers.Human{BirthDate: "30.03.1992"}), IsErr: true, Message: "negative test [malformed birth date] failed", Crit: true},
+		{Human: humanMerge(valid, controllers.Human{BirthDate: "2999-01-01"}), IsErr: true, Message: "negative test [birth date in the future] failed", Crit: true},
+		{Human: controllers.Human{DocType: 1, FirstName: "Alexey", LastName: "Ivanov", BirthDate: "1992-03-30"}, IsErr: true, Message: "negative test [no doc_id] failed", Crit: true},
+		{Human: controllers.Human{DocId: 1234, DocType: 1, LastName: "Ivanov", BirthDate: "1992-03-30"}, IsErr: true, Message: "negative test [no first name] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		err := humanValidate(context.TODO(), val.Human, db, log)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,96 @@
+package Human
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+	"net/url"
+)
+
+// humanDocIdQuery is the query contract of requests addressing a single human
+var humanDocIdQuery = handlers.QuerySchema{
+	Params: []handlers.Param{{Name: "doc_id", Type: handlers.ParamInt, Range: handlers.Positive}},
+	Rules:  []handlers.Rule{{Mode: handlers.ModeRequired, Params: []string{"doc_id"}}},
+}
+
+// humanDocIdFromQuery returns single integer doc_id from the request URL
+func humanDocIdFromQuery(vals url.Values) (int, error) {
+	if err := humanDocIdQuery.Validate(vals); err != nil {
+		return 0, err
+	}
+	return handlers.QueryInt(vals, "doc_id"), nil
+}
+
+// humanMerge overlays non-empty fields of patch onto h
+func humanMerge(h, patch controllers.Human) controllers.Human {
+	if patch.DocType != 0 {
+		h.DocType = patch.DocType
+	}
+	if patch.FirstName != "" {
+		h.FirstName = patch.FirstName
+	}
+	if patch.MiddleName != "" {
+		h.MiddleName = patch.MiddleName
+	}
+	if patch.LastName != "" {
+		h.LastName = patch.LastName
+	}
+	if patch.BirthDate != "" {
+		h.BirthDate = patch.BirthDate
+	}
+	return h
+}
+
+// putHuman addresses the record by the 'doc_id' query parameter, see putHumanId
+func (h *Handler) putHuman(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	docId, err := humanDocIdFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	h.putHumanId(ctx, w, r, docId, l)
+}
+
+// putHumanId replaces the record on PUT and merges non-empty fields on PATCH
+func (h *Handler) putHumanId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	if err := handlers.ValidateContentType(w, r, l); err != nil {
+		return
+	}
+
+	human, err := humanReadBody(r)
+	if err != nil {
+		handlers.BadRequest(w, r, l, err)
+		return
+	}
+	if human.DocId != 0 && human.DocId != docId {
+		handlers.BadRequest(w, r, l, api.FieldError{Field: "DocId", Detail: fmt.Sprintf("[%d] differs from the addressed doc_id [%d]", human.DocId, docId)})
+		return
+	}
+	human.DocId = docId
+
+	if r.Method == http.MethodPatch {
+		current, err := h.humans.HumanGet(ctx, docId, l)
+		if err != nil {
+			handlers.RepoError(w, r, l, err)
+			return
+		}
+		human = humanMerge(current, human)
+	}
+
+	if err := humanValidate(ctx, human, h.docTypes, l); err != nil {
+		handlers.InvalidRequest(w, r, l, err)
+		return
+	}
+
+	result, err := h.humans.HumanUpdate(ctx, human, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
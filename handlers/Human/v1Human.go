@@ -0,0 +1,93 @@
+package Human
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"net/http"
+)
+
+// humanListQuery is the query contract of the v1 list. Filters of the list replace the search
+var humanListQuery = handlers.QuerySchema{List: &controllers.HumanListSpec}
+
+// getHumanId responds with the human, 404 in case it does not exist
+func (h *Handler) getHumanId(ctx context.Context, w http.ResponseWriter, r *http.Request, docId int, l *slog.Logger) {
+	result, err := h.humans.HumanGet(ctx, docId, l)
+	if err != nil {
+		handlers.RepoError(w, r, l, err)
+		return
+	}
+	handlers.Respond(w, r, http.StatusOK, result, l)
+}
+
+// V1Routes returns routes of humans in the v1 resource API, paths are relative to the version prefix
+func (h *Handler) V1Routes() []handlers.Route {
+	var one = []handlers.Response{{Status: http.StatusOK, Description: "The human", Bodies: []any{controllers.Human{}}}}
+	var put = func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		h.putHumanId(ctx, w, r, handlers.PathInt(r, "id"), l)
+	}
+
+	return []handlers.Route{
+		{
+			Path:    "/humans",
+			Summary: "Humans",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "List humans",
+					Query:     humanListQuery,
+					Responses: []handlers.Response{{Status: http.StatusOK, Description: "Page of the list", Bodies: []any{handlers.ListPage{Items: []controllers.Human{}}}, List: true}},
+					Handler:   h.getHumanList,
+				},
+				{
+					Method:    http.MethodPost,
+					Summary:   "Create human",
+					Body:      controllers.Human{},
+					Responses: []handlers.Response{{Status: http.StatusCreated, Description: "Created human", Bodies: []any{controllers.Human{}}}},
+					Handler:   h.postHuman,
+				},
+			},
+		},
+		{
+			Path:    "/humans/{id}",
+			Summary: "Human addressed by the doc id",
+			Operations: []handlers.Operation{
+				{
+					Method:    http.MethodGet,
+					Summary:   "Get human",
+					Path:      handlers.PathId,
+					Responses: one,
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.getHumanId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+				{
+					Method:    http.MethodPut,
+					Summary:   "Replace human",
+					Path:      handlers.PathId,
+					Body:      controllers.Human{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodPatch,
+					Summary:   "Update non-empty fields of human set in the body",
+					Path:      handlers.PathId,
+					Body:      controllers.Human{},
+					Responses: one,
+					Handler:   put,
+				},
+				{
+					Method:    http.MethodDelete,
+					Summary:   "Delete human",
+					Path:      handlers.PathId,
+					Responses: []handlers.Response{{Status: http.StatusNoContent, Description: "Deleted"}},
+					Handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+						h.deleteHumanId(ctx, w, r, handlers.PathInt(r, "id"), l)
+					},
+				},
+			},
+		},
+	}
+}
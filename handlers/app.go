@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// App holds dependencies shared by all handlers. It is built once on start,
+// so handlers never look their dependencies up per request
+type App struct {
+	DB               repos.DB
+	DocTypeGetter    controllers.DocTypeGetter
+	DocTypeWriter    controllers.DocTypeWriter
+	AnimalTypeGetter controllers.AnimalTypeGetter
+	AnimalTypeWriter controllers.AnimalTypeWriter
+	HumanRepo        controllers.HumanRepo
+	AnimalRepo       controllers.AnimalRepo
+	AuditReader      controllers.AuditReader
+	Log              *slog.Logger
+}
+
+// NewApp converts db to every controller interface. Returns error in case db lacks any of them
+func NewApp(db repos.DB, l *slog.Logger) (*App, error) {
+	var app = &App{DB: db, Log: l}
+	var ok bool
+
+	if db == nil || l == nil {
+		return nil, fmt.Errorf("db and logger must be set")
+	}
+	if app.DocTypeGetter, ok = db.(controllers.DocTypeGetter); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [DocTypeGetter] interface")
+	}
+	if app.DocTypeWriter, ok = db.(controllers.DocTypeWriter); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [DocTypeWriter] interface")
+	}
+	if app.AnimalTypeGetter, ok = db.(controllers.AnimalTypeGetter); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [AnimalTypeGetter] interface")
+	}
+	if app.AnimalTypeWriter, ok = db.(controllers.AnimalTypeWriter); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [AnimalTypeWriter] interface")
+	}
+	if app.HumanRepo, ok = db.(controllers.HumanRepo); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [HumanRepo] interface")
+	}
+	if app.AnimalRepo, ok = db.(controllers.AnimalRepo); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [AnimalRepo] interface")
+	}
+	if app.AuditReader, ok = db.(controllers.AuditReader); !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [AuditReader] interface")
+	}
+
+	return app, nil
+}
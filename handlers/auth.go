@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"mis-catanddog/lg"
+	"net/http"
+	"strings"
+)
+
+// unauthorizedDetail is the only detail callers get about rejected credentials, the reason is logged
+const unauthorizedDetail = "missing or invalid credentials"
+
+// Unauthorized logs err and responds with 401 along with the accepted schemes
+func Unauthorized(w http.ResponseWriter, r *http.Request, l *slog.Logger, err error) {
+	l.Warn(err.Error())
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mis-catanddog"`)
+	WriteProblem(w, r, api.Problem{Status: http.StatusUnauthorized, Code: api.CodeUnauthorized, Detail: unauthorizedDetail}, l)
+}
+
+// authenticate checks credentials of r. API key wins in case both an API key and a token are presented
+func authenticate(a *auth.Authenticator, r *http.Request, l *slog.Logger) (auth.Principal, error) {
+	if key := r.Header.Get(api.APIKeyHeader); key != "" {
+		return a.APIKey(r.Context(), key, l)
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return a.Bearer(strings.TrimSpace(token))
+	}
+	return auth.Principal{}, fmt.Errorf("no credentials presented: %w", auth.ErrUnauthenticated)
+}
+
+// WithAuth rejects requests without valid credentials unless public reports them as public. The principal
+// is stored in the request context along with the audit actor, its subject is added to the request-scoped logger
+func WithAuth(a *auth.Authenticator, public func(*http.Request) bool, l *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if public(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			log := lg.FromContext(r.Context(), l)
+
+			p, err := authenticate(a, r, log)
+			if errors.Is(err, auth.ErrUnauthenticated) {
+				Unauthorized(w, r, log, err)
+				return
+			}
+			if err != nil {
+				RepoError(w, r, log, fmt.Errorf("failed to authenticate: %w", err))
+				return
+			}
+
+			log = log.With("subject", p.Subject, "auth", p.Method)
+			ctx := lg.WithLogger(auth.WithPrincipal(r.Context(), p), log)
+			// repos record the caller in the audit log along with every change
+			ctx = controllers.WithActor(ctx, controllers.Actor{Subject: p.Subject, RequestId: api.RequestId(ctx)})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
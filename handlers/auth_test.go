@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// keyRepo is APIKeyRepo of a single key
+type keyRepo struct {
+	key controllers.APIKey
+	err error
+}
+
+func (k keyRepo) APIKeyGetByHash(ctx context.Context, hash string, l *slog.Logger) (controllers.APIKey, error) {
+	if k.err != nil {
+		return controllers.APIKey{}, k.err
+	}
+	if hash != k.key.Hash {
+		return controllers.APIKey{}, fmt.Errorf("api_key: %w", controllers.ErrNotFound)
+	}
+	return k.key, nil
+}
+
+func (k keyRepo) APIKeyCreate(ctx context.Context, key controllers.APIKey, l *slog.Logger) (controllers.APIKey, error) {
+	return key, nil
+}
+
+func (k keyRepo) APIKeyRevoke(ctx context.Context, name string, l *slog.Logger) error {
+	return nil
+}
+
+func TestWithAuth(t *testing.T) {
+	type authTest struct {
+		Url     string
+		Repo    keyRepo
+		Header  string
+		Value   string
+		Status  int
+		Result  string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var repo = keyRepo{key: controllers.APIKey{Id: 1, Name: "billing", Hash: auth.HashKey("mcd_key")}}
+	var public = func(r *http.Request) bool { return r.URL.Path == HealthzPath }
+
+	var arr = []authTest{
+		{Url: "/human?id=1", Repo: repo, Header: api.APIKeyHeader, Value: "mcd_key", Status: http.StatusOK, Result: "billing api_key", Message: "positive test [api key] failed", Crit: true},
+		{Url: HealthzPath, Repo: repo, Status: http.StatusOK, Message: "positive test [public route] failed", Crit: true},
+		{Url: "/human?id=1", Repo: repo, Status: http.StatusUnauthorized, Result: `"code":"unauthorized"`, Message: "negative test [no credentials] failed", Crit: true},
+		{Url: "/human?id=1", Repo: repo, Header: api.APIKeyHeader, Value: "mcd_other", Status: http.StatusUnauthorized, Result: unauthorizedDetail, Message: "negative test [unknown key] failed", Crit: true},
+		{Url: "/human?id=1", Repo: repo, Header: "Authorization", Value: "Bearer a.b.c", Status: http.StatusUnauthorized, Message: "negative test [token without jwt config] failed", Crit: true},
+		{Url: "/human?id=1", Repo: repo, Header: "Authorization", Value: "Basic dXNlcjpwYXNz", Status: http.StatusUnauthorized, Message: "negative test [unsupported scheme] failed", Crit: true},
+		{Url: "/human?id=1", Repo: keyRepo{err: controllers.ErrUnavailable}, Header: api.APIKeyHeader, Value: "mcd_key", Status: http.StatusServiceUnavailable, Message: "negative test [repo unavailable] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		a, err := auth.New(val.Repo, nil)
+		if err != nil {
+			t.Fatalf("failed to create authenticator: %s", err.Error())
+		}
+		h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, _ := auth.FromContext(r.Context())
+			fmt.Fprintf(w, "%s %s", p.Subject, p.Method)
+		}), WithAuth(a, public, log))
+
+		r := httptest.NewRequest(http.MethodGet, val.Url, nil)
+		if val.Header != "" {
+			r.Header.Set(val.Header, val.Value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != val.Status || !strings.Contains(w.Body.String(), val.Result) || w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"log/slog"
+	"mis-catanddog/api"
+	"net/http"
+)
+
+// BatchItem is the per-item result of a batch request. Either Item or Problem is set
+type BatchItem struct {
+	Status  int
+	Item    any          `json:",omitempty"`
+	Problem *api.Problem `json:",omitempty"`
+}
+
+// WriteBatch responds with http.StatusMultiStatus and per-item results in request order
+func WriteBatch(w http.ResponseWriter, r *http.Request, items []BatchItem, l *slog.Logger) {
+	Respond(w, r, http.StatusMultiStatus, items, l)
+}
@@ -3,30 +3,32 @@ package handlers
 import (
 	"fmt"
 	"log/slog"
+	"mime"
+	"mis-catanddog/api"
 	"net/http"
-	"net/url"
-	"slices"
+	"strings"
 )
 
-// validateUrl validetes query URL according to the required logic. Valid modes are
-// oneOf: returns values that exists in query from all the available in an array. In case more than one available returns error
-func validateUrl(q url.Values, mode string, array ...string) {
-
-}
-
-// validateRequest checks all necessary mumbo-jumbo. In case any errors it logs them, sets http.StatusBadRequest
-// and returns "" error as a sign that request is bad. Returns nil in case all is fine.
-func validateContentType(w http.ResponseWriter, r *http.Request, l *slog.Logger) error {
-	val, ok := r.Header["Content-Type"]
-	if !ok {
-		l.Error("content type not set")
-		w.WriteHeader(http.StatusBadRequest)
-		return fmt.Errorf("")
+// ValidateContentType checks all necessary mumbo-jumbo. In case any errors it logs them, responds with
+// http.StatusBadRequest problem and returns the error as a sign that request is bad. Returns nil in case all is fine.
+// Media type parameters are accepted, charset must be utf-8 in case it is set
+func ValidateContentType(w http.ResponseWriter, r *http.Request, l *slog.Logger) error {
+	val := r.Header.Get("Content-Type")
+	if val == "" {
+		err := api.FieldError{Field: "Content-Type", Detail: "header is not set"}
+		BadRequest(w, r, l, err)
+		return err
+	}
+	mt, params, err := mime.ParseMediaType(val)
+	if err != nil || mt != MediaJSON {
+		err := api.FieldError{Field: "Content-Type", Detail: fmt.Sprintf("[%s] is not supported. Expected: %s", val, MediaJSON)}
+		BadRequest(w, r, l, err)
+		return err
 	}
-	if slices.IndexFunc(val, func(s string) bool { return s == "application/json" }) < 0 {
-		l.Error(fmt.Sprintf("unsupprted content types %v", val))
-		w.WriteHeader(http.StatusBadRequest)
-		return fmt.Errorf("")
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		err := api.FieldError{Field: "Content-Type", Detail: fmt.Sprintf("charset [%s] is not supported. Expected: utf-8", charset)}
+		BadRequest(w, r, l, err)
+		return err
 	}
 	return nil
 }
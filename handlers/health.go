@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/repos"
+	"mis-catanddog/repos/migrate"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// probe urls of load balancers and orchestrators, they stay outside the versioned API
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// statuses of Health
+const (
+	StatusOk      = "ok"
+	StatusFailing = "failing"
+)
+
+// errDraining is reported by readiness once graceful shutdown has begun
+var errDraining = errors.New("server is shutting down")
+
+// details of failing readiness. Errors behind them are logged only, the probes are public
+const (
+	DetailDraining       = "draining"
+	DetailDBUnavailable  = "db_unavailable"
+	DetailSchemaNotReady = "schema_not_ready"
+)
+
+// Health is the body of the probes. DBVersion is reported by readiness only, since liveness never touches the db.
+// Detail is one of the Detail constants in case readiness fails
+type Health struct {
+	Status    string `json:"status"`
+	Backend   string `json:"backend"`
+	DBVersion string `json:"db_version,omitempty"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"go_version"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// HealthHandler serves liveness and readiness probes. Readiness pings the db, checks its schema
+// is up to date and fails for good after Drain
+type HealthHandler struct {
+	db       migrate.Source
+	backend  string
+	timeout  time.Duration
+	draining atomic.Bool
+	build    Health
+	log      *slog.Logger
+}
+
+// NewHealthHandler returns probes of db. backend is the configured db type, timeout limits every readiness check
+func NewHealthHandler(db repos.DB, backend string, timeout time.Duration, l *slog.Logger) (*HealthHandler, error) {
+	src, ok := db.(migrate.Source)
+	if !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [migrate.Source] interface")
+	}
+	return &HealthHandler{db: src, backend: backend, timeout: timeout, build: buildInfo(), log: l}, nil
+}
+
+// buildInfo fills version fields of Health from the info embedded in the binary
+func buildInfo() Health {
+	var h = Health{Version: "(devel)", GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return h
+	}
+	if info.Main.Version != "" {
+		h.Version = info.Main.Version
+	}
+	for _, val := range info.Settings {
+		if val.Key == "vcs.revision" {
+			h.Revision = val.Value
+		}
+	}
+	return h
+}
+
+// Drain flips readiness to failing, so load balancers stop routing traffic while in-flight requests finish
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// ready runs readiness checks and returns version of the db server. detail tells which check failed.
+// The checks only read the db
+func (h *HealthHandler) ready(ctx context.Context) (version string, detail string, err error) {
+	if h.draining.Load() {
+		return "", DetailDraining, errDraining
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	if err := h.db.Ping(ctx); err != nil {
+		return "", DetailDBUnavailable, err
+	}
+	if err := migrate.Check(ctx, h.db); err != nil {
+		return "", DetailSchemaNotReady, err
+	}
+	version, err = h.db.Version(ctx)
+	if err != nil {
+		return "", DetailDBUnavailable, err
+	}
+	return version, "", nil
+}
+
+// getHealthz responds as long as the process is able to serve requests
+func (h *HealthHandler) getHealthz(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	var result = h.build
+	result.Status = StatusOk
+	result.Backend = h.backend
+	Respond(w, r, http.StatusOK, result, l)
+}
+
+// getReadyz responds with 503 in case the db is unreachable, its schema is outdated or the server is draining
+func (h *HealthHandler) getReadyz(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+	var result = h.build
+	result.Backend = h.backend
+
+	version, detail, err := h.ready(ctx)
+	if err != nil {
+		l.Warn(fmt.Errorf("readiness check failed: %w", err).Error(), "detail", detail)
+		result.Status = StatusFailing
+		result.Detail = detail
+		Respond(w, r, http.StatusServiceUnavailable, result, l)
+		return
+	}
+	result.Status = StatusOk
+	result.DBVersion = version
+	Respond(w, r, http.StatusOK, result, l)
+}
+
+// Routes returns the probes, paths are absolute
+func (h *HealthHandler) Routes() []Route {
+	return []Route{
+		{
+			Path:    HealthzPath,
+			Summary: "Liveness probe",
+			Public:  true,
+			Operations: []Operation{{
+				Method:    http.MethodGet,
+				Summary:   "Report the process is alive",
+				Responses: []Response{{Status: http.StatusOK, Description: "Alive", Bodies: []any{Health{}}}},
+				Handler:   h.getHealthz,
+			}},
+		},
+		{
+			Path:    ReadyzPath,
+			Summary: "Readiness probe",
+			Public:  true,
+			Operations: []Operation{{
+				Method:  http.MethodGet,
+				Summary: "Report the db is reachable and its schema is up to date",
+				Responses: []Response{
+					{Status: http.StatusOK, Description: "Ready to serve", Bodies: []any{Health{}}},
+					{Status: http.StatusServiceUnavailable, Description: "Not ready or shutting down", Bodies: []any{Health{}}},
+				},
+				Handler: h.getReadyz,
+			}},
+		},
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// list page size limits
+const (
+	listLimitDefault = 20
+	listLimitMax     = 100
+)
+
+// listParams are query parameters of list endpoints besides filters
+var listParams = []string{"limit", "sort", "cursor"}
+
+// ListPage is the list response body. NextCursor and Next are empty on the last page
+type ListPage struct {
+	Items      any
+	NextCursor string `json:",omitempty"`
+	Next       string `json:",omitempty"`
+}
+
+// listCursor is the opaque cursor content. Sort binds the cursor to the order it was issued for
+type listCursor struct {
+	Sort  string   `json:"s"`
+	After []string `json:"a"`
+}
+
+// listValue checks that value fits the field type
+func listValue(f controllers.ListField, param string, value string) error {
+	switch f.Type {
+	case controllers.ListInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return api.FieldError{Field: param, Detail: fmt.Sprintf("failed to convert [%s] to an integer", value)}
+		}
+	case controllers.ListDate:
+		if _, err := time.Parse(controllers.DateLayout, value); err != nil {
+			return api.FieldError{Field: param, Detail: fmt.Sprintf("[%s] is not a %s date", value, controllers.DateLayout)}
+		}
+	}
+	return nil
+}
+
+// listSort parses comma separated sort fields, '-' prefix means descending. The key field is appended
+// in case it is missing, so the order is total
+func listSort(val string, spec controllers.ListSpec) ([]controllers.SortField, error) {
+	var result []controllers.SortField
+	var seen = map[string]bool{}
+
+	for _, name := range strings.Split(val, ",") {
+		var sf controllers.SortField
+		sf.Field, sf.Desc = strings.CutPrefix(strings.TrimSpace(name), "-")
+		if _, ok := spec.Field(sf.Field); !ok {
+			return nil, api.FieldError{Field: "sort", Detail: fmt.Sprintf("unknown field [%s]", sf.Field)}
+		}
+		if seen[sf.Field] {
+			return nil, api.FieldError{Field: "sort", Detail: fmt.Sprintf("field [%s] is repeated", sf.Field)}
+		}
+		seen[sf.Field] = true
+		result = append(result, sf)
+		if sf.Field == spec.Key {
+			break
+		}
+	}
+	if !seen[spec.Key] {
+		result = append(result, controllers.SortField{Field: spec.Key})
+	}
+	return result, nil
+}
+
+// listSortString is the canonical form of sort, used to bind cursors
+func listSortString(sort []controllers.SortField) string {
+	var s = make([]string, 0, len(sort))
+	for _, val := range sort {
+		if val.Desc {
+			s = append(s, "-"+val.Field)
+			continue
+		}
+		s = append(s, val.Field)
+	}
+	return strings.Join(s, ",")
+}
+
+// listFilter parses filter parameter field[op]=value
+func listFilter(param string, vals []string, spec controllers.ListSpec) ([]controllers.Filter, error) {
+	var result []controllers.Filter
+
+	name, op, ok := strings.Cut(strings.TrimSuffix(param, "]"), "[")
+	if !ok || !strings.HasSuffix(param, "]") {
+		return nil, api.FieldError{Field: param, Detail: "unknown parameter"}
+	}
+	f, ok := spec.Field(name)
+	if !ok {
+		return nil, api.FieldError{Field: param, Detail: fmt.Sprintf("unknown field [%s]", name)}
+	}
+	switch controllers.ListOp(op) {
+	case controllers.OpEq:
+	case controllers.OpLike:
+		if f.Type != controllers.ListText {
+			return nil, api.FieldError{Field: param, Detail: fmt.Sprintf("operation [%s] applies to text fields only", op)}
+		}
+	case controllers.OpGte, controllers.OpLte:
+		if f.Type == controllers.ListText {
+			return nil, api.FieldError{Field: param, Detail: fmt.Sprintf("operation [%s] applies to integer and date fields only", op)}
+		}
+	default:
+		return nil, api.FieldError{Field: param, Detail: fmt.Sprintf("unknown operation [%s]", op)}
+	}
+
+	for _, val := range vals {
+		if controllers.ListOp(op) != controllers.OpLike {
+			if err := listValue(f, param, val); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, controllers.Filter{Field: name, Op: controllers.ListOp(op), Value: val})
+	}
+	return result, nil
+}
+
+// listCursorDecode decodes cursor and checks it was issued for sort
+func listCursorDecode(val string, sort []controllers.SortField, spec controllers.ListSpec) ([]string, error) {
+	var c listCursor
+	var bad = api.FieldError{Field: "cursor", Detail: "malformed cursor"}
+
+	raw, err := base64.RawURLEncoding.DecodeString(val)
+	if err != nil {
+		return nil, bad
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.After) != len(sort) {
+		return nil, bad
+	}
+	if c.Sort != listSortString(sort) {
+		return nil, api.FieldError{Field: "cursor", Detail: "cursor was issued for a different sort order"}
+	}
+	for i, val := range sort {
+		f, _ := spec.Field(val.Field)
+		if err := listValue(f, "cursor", c.After[i]); err != nil {
+			return nil, bad
+		}
+	}
+	return c.After, nil
+}
+
+// listCursorEncode returns cursor of the page following after
+func listCursorEncode(sort []controllers.SortField, after []string) string {
+	raw, _ := json.Marshal(listCursor{Sort: listSortString(sort), After: after})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseList parses list query parameters: limit, sort, cursor and field[op] filters. Parameters listed
+// in extra are left for the caller, any other parameter is an error
+func ParseList(vals url.Values, spec controllers.ListSpec, extra ...string) (controllers.ListQuery, error) {
+	var q = controllers.ListQuery{Limit: listLimitDefault}
+	var err error
+
+	for key, val := range vals {
+		if slices.Contains(listParams, key) {
+			if len(val) > 1 {
+				return q, api.FieldError{Field: key, Detail: "single value expected"}
+			}
+			continue
+		}
+		if slices.Contains(extra, key) {
+			continue
+		}
+		filters, err := listFilter(key, val, spec)
+		if err != nil {
+			return q, err
+		}
+		q.Filters = append(q.Filters, filters...)
+	}
+	// map order is random, keep query text stable
+	slices.SortFunc(q.Filters, func(a, b controllers.Filter) int {
+		return strings.Compare(a.Field+string(a.Op)+a.Value, b.Field+string(b.Op)+b.Value)
+	})
+
+	if val := vals.Get("limit"); val != "" {
+		q.Limit, err = strconv.Atoi(val)
+		if err != nil || q.Limit < 1 || q.Limit > listLimitMax {
+			return q, api.FieldError{Field: "limit", Detail: fmt.Sprintf("integer from 1 to %d expected, got [%s]", listLimitMax, val)}
+		}
+	}
+
+	q.Sort = []controllers.SortField{{Field: spec.Key}}
+	if val := vals.Get("sort"); val != "" {
+		if q.Sort, err = listSort(val, spec); err != nil {
+			return q, err
+		}
+	}
+
+	if val := vals.Get("cursor"); val != "" {
+		if q.After, err = listCursorDecode(val, q.Sort, spec); err != nil {
+			return q, err
+		}
+	}
+
+	return q, nil
+}
+
+// WriteList responds with a page of items in the media type negotiated with the client. In case after
+// is not empty the next page cursor is set in the Link header and, except CSV, in the body
+func WriteList(w http.ResponseWriter, r *http.Request, q controllers.ListQuery, items any, after []string, l *slog.Logger) {
+	mt, ok := negotiate(w, r, listMedia, l)
+	if !ok {
+		return
+	}
+
+	// nil slices are encoded as null, the list is always an array
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	var page = ListPage{Items: items}
+	if len(after) > 0 {
+		page.NextCursor = listCursorEncode(q.Sort, after)
+		next := r.URL.Query()
+		next.Set("cursor", page.NextCursor)
+		page.Next = r.URL.Path + "?" + next.Encode()
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", page.Next))
+	}
+
+	if mt == MediaCSV {
+		w.Header().Set("Content-Disposition", csvFilename(r))
+		write(w, http.StatusOK, mt, items, l)
+		return
+	}
+	write(w, http.StatusOK, mt, page, l)
+}
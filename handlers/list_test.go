@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log/slog"
+	"mis-catanddog/controllers"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	type listTest struct {
+		Url     url.Values
+		Limit   int
+		Sort    string
+		Filters int
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var spec = controllers.HumanListSpec
+	var cursor = listCursorEncode([]controllers.SortField{{Field: "last_name"}, {Field: "doc_id"}}, []string{"Ivanov", "7"})
+	var arr = []listTest{
+		{Url: url.Values{}, Limit: listLimitDefault, Sort: "doc_id", Message: "positive test [defaults] failed", Crit: true},
+		{Url: url.Values{"limit": {"5"}, "sort": {"last_name,-birth_date"}}, Limit: 5, Sort: "last_name,-birth_date,doc_id", Message: "positive test [sort appends key] failed", Crit: true},
+		{Url: url.Values{"sort": {"-doc_id,last_name"}}, Limit: listLimitDefault, Sort: "-doc_id", Message: "positive test [sort stops at key] failed", Crit: true},
+		{Url: url.Values{"last_name[like]": {"Iv*"}, "birth_date[gte]": {"1990-01-01"}, "doc_type[eq]": {"1", "2"}}, Limit: listLimitDefault, Sort: "doc_id", Filters: 4, Message: "positive test [filters] failed", Crit: true},
+		{Url: url.Values{"sort": {"last_name"}, "cursor": {cursor}}, Limit: listLimitDefault, Sort: "last_name,doc_id", Message: "positive test [cursor] failed", Crit: true},
+		{Url: url.Values{"sort": {"-last_name"}, "cursor": {cursor}}, IsErr: true, Message: "negative test [cursor of another sort] failed", Crit: true},
+		{Url: url.Values{"cursor": {"bm90IGpzb24"}}, IsErr: true, Message: "negative test [malformed cursor] failed", Crit: true},
+		{Url: url.Values{"limit": {"0"}}, IsErr: true, Message: "negative test [zero limit] failed", Crit: true},
+		{Url: url.Values{"limit": {"101"}}, IsErr: true, Message: "negative test [limit over max] failed", Crit: true},
+		{Url: url.Values{"sort": {"middle_name"}}, IsErr: true, Message: "negative test [unknown sort field] failed", Crit: true},
+		{Url: url.Values{"sort": {"last_name,-last_name"}}, IsErr: true, Message: "negative test [repeated sort field] failed", Crit: true},
+		{Url: url.Values{"doc_type[like]": {"1"}}, IsErr: true, Message: "negative test [like on integer] failed", Crit: true},
+		{Url: url.Values{"last_name[gte]": {"I"}}, IsErr: true, Message: "negative test [gte on text] failed", Crit: true},
+		{Url: url.Values{"birth_date[eq]": {"30.03.1992"}}, IsErr: true, Message: "negative test [bad date] failed", Crit: true},
+		{Url: url.Values{"last_name[ne]": {"Ivanov"}}, IsErr: true, Message: "negative test [unknown operation] failed", Crit: true},
+		{Url: url.Values{"expand": {"owner"}}, IsErr: true, Message: "negative test [unknown parameter] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		q, err := ParseList(val.Url, spec)
+		if (err != nil) != val.IsErr || (err == nil && (q.Limit != val.Limit || listSortString(q.Sort) != val.Sort || len(q.Filters) != val.Filters)) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v; %+v", val.Crit, val.Message, err, q)
+		}
+	}
+
+	if _, err := ParseList(url.Values{"expand": {"owner"}}, spec, "expand"); err != nil {
+		t.Logf("crit: true; positive test [extra parameter] failed; %v", err)
+		fail = true
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var q = controllers.ListQuery{Limit: 1, Sort: []controllers.SortField{{Field: "doc_id"}}}
+
+	w := httptest.NewRecorder()
+	WriteList(w, httptest.NewRequest("GET", "/human?limit=1", nil), q, []controllers.Human{{DocId: 7}}, []string{"7"}, log)
+	next, _ := url.Parse(strings.TrimSuffix(strings.TrimPrefix(w.Header().Get("Link"), "<"), `>; rel="next"`))
+	if next == nil || next.Path != "/human" || next.Query().Get("limit") != "1" {
+		t.Fatalf("expected next page link keeping the query; got [%s]", w.Header().Get("Link"))
+	}
+	after, err := listCursorDecode(next.Query().Get("cursor"), q.Sort, controllers.HumanListSpec)
+	if err != nil || !slices.Equal(after, []string{"7"}) {
+		t.Fatalf("expected cursor to round trip; got %v, %v", after, err)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/human?limit=1", nil)
+	r.Header.Set("Accept", "text/csv")
+	WriteList(w, r, q, []controllers.Human{{DocId: 7}}, []string{"7"}, log)
+	if w.Header().Get("Link") == "" || !strings.Contains(w.Header().Get("Content-Disposition"), "human.csv") || !strings.HasPrefix(w.Body.String(), "DocId,DocType,FirstName") {
+		t.Fatalf("expected csv export with next page link; got [%s] [%s] %s", w.Header().Get("Link"), w.Header().Get("Content-Disposition"), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	WriteList(w, httptest.NewRequest("GET", "/human", nil), q, []controllers.Human(nil), nil, log)
+	if w.Header().Get("Link") != "" || !strings.Contains(w.Body.String(), `"Items":[]`) {
+		t.Fatalf("expected last empty page; got [%s] %s", w.Header().Get("Link"), w.Body.String())
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"log/slog"
+	"mime"
+	"mis-catanddog/api"
+	"net/http"
+	"olympos.io/encoding/edn"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// supported media types
+const (
+	MediaJSON = api.MediaJSON
+	MediaYAML = "application/yaml"
+	MediaEDN  = "application/edn"
+	MediaCSV  = "text/csv"
+)
+
+// media types offered for single items and for lists, in order of preference
+var (
+	itemMedia = []string{MediaJSON, MediaYAML, MediaEDN}
+	listMedia = []string{MediaJSON, MediaYAML, MediaEDN, MediaCSV}
+)
+
+// mediaRange is a single parsed Accept header element
+type mediaRange struct {
+	typ string
+	sub string
+	q   float64
+}
+
+// specificity ranks exact types over type/* over */*
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.sub == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches reports whether media type t falls into the range
+func (m mediaRange) matches(t string) bool {
+	typ, sub, _ := strings.Cut(t, "/")
+	return (m.typ == "*" || m.typ == typ) && (m.sub == "*" || m.sub == sub)
+}
+
+// parseAccept parses Accept header values. Malformed elements are skipped
+func parseAccept(accept []string) []mediaRange {
+	var result []mediaRange
+	for _, val := range accept {
+		for _, item := range strings.Split(val, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+			if err != nil {
+				continue
+			}
+			typ, sub, ok := strings.Cut(mt, "/")
+			if !ok || (typ == "*" && sub != "*") {
+				continue
+			}
+			var m = mediaRange{typ: typ, sub: sub, q: 1}
+			if q, ok := params["q"]; ok {
+				if m.q, err = strconv.ParseFloat(q, 64); err != nil || m.q < 0 || m.q > 1 {
+					continue
+				}
+			}
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Negotiate picks the offer the client prefers according to Accept header values. The most specific range
+// matching an offer sets its quality, ties are resolved by the order of offers. Missing Accept means
+// the first offer. Returns false in case the client accepts none of the offers
+func Negotiate(accept []string, offers ...string) (string, bool) {
+	var ranges = parseAccept(accept)
+	if len(offers) == 0 {
+		return "", false
+	}
+	if len(ranges) == 0 {
+		return offers[0], true
+	}
+
+	var best string
+	var bestQ float64
+	for _, offer := range offers {
+		var q float64
+		var specificity = -1
+		for _, val := range ranges {
+			if val.matches(offer) && val.specificity() > specificity {
+				q, specificity = val.q, val.specificity()
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// plain converts v to maps, slices and scalars the way encoding/json sees it, so every
+// encoding shares field names and omitempty rules with JSON. Integers stay integers
+func plain(v any) (any, error) {
+	var result any
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return plainNumbers(result), nil
+}
+
+// plainNumbers replaces json.Number with int64 or float64
+func plainNumbers(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		for k, item := range val {
+			val[k] = plainNumbers(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = plainNumbers(item)
+		}
+	}
+	return v
+}
+
+// ednKeywords turns map keys into keywords, the way EDN maps are usually keyed
+func ednKeywords(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		var result = make(map[edn.Keyword]any, len(val))
+		for k, item := range val {
+			result[edn.Keyword(k)] = ednKeywords(item)
+		}
+		return result
+	case []any:
+		for i, item := range val {
+			val[i] = ednKeywords(item)
+		}
+	}
+	return v
+}
+
+// csvFormula lists characters spreadsheets treat as a formula start
+const csvFormula = "=+-@\t\r"
+
+// csvColumns returns column names and field index paths of struct t. Nested structs and pointers to them
+// are flattened with dotted names, slices and maps are skipped
+func csvColumns(t reflect.Type, prefix string, index []int) ([]string, [][]int) {
+	var names []string
+	var paths [][]int
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return []string{strings.TrimSuffix(prefix, ".")}, [][]int{index}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		switch f.Type.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			continue
+		}
+		n, p := csvColumns(f.Type, prefix+name+".", append(slices.Clone(index), i))
+		names = append(names, n...)
+		paths = append(paths, p...)
+	}
+	return names, paths
+}
+
+// csvCell returns the value at index path of v. Nil pointers on the way mean an empty cell
+func csvCell(v reflect.Value, path []int) string {
+	for _, i := range path {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		s := v.String()
+		if s != "" && strings.ContainsRune(csvFormula, rune(s[0])) {
+			return "'" + s
+		}
+		return s
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// writeCSV writes slice of structs items as CSV with a header row
+func writeCSV(w io.Writer, items any) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot encode %T as csv", items)
+	}
+	names, paths := csvColumns(v.Type().Elem(), "", nil)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		var row = make([]string, 0, len(paths))
+		for _, val := range paths {
+			row = append(row, csvCell(v.Index(i), val))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// encode writes v in media type mt
+func encode(w io.Writer, mt string, v any) error {
+	switch mt {
+	case MediaYAML:
+		p, err := plain(v)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+		return enc.Close()
+	case MediaEDN:
+		p, err := plain(v)
+		if err != nil {
+			return err
+		}
+		raw, err := edn.MarshalPPrint(ednKeywords(p), nil)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(raw, '\n'))
+		return err
+	case MediaCSV:
+		return writeCSV(w, v)
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false) // keep '&' of links readable
+		return enc.Encode(v)
+	}
+}
+
+// negotiate picks the response media type among offers. In case the client accepts none of them safe requests
+// get http.StatusNotAcceptable problem and false is returned. Other requests have already taken effect, so they
+// get JSON rather than an error that would hide the outcome
+func negotiate(w http.ResponseWriter, r *http.Request, offers []string, l *slog.Logger) (string, bool) {
+	w.Header().Add("Vary", "Accept")
+	mt, ok := Negotiate(r.Header.Values("Accept"), offers...)
+	if ok {
+		return mt, true
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return MediaJSON, true
+	}
+	WriteProblem(w, r, api.Problem{
+		Status: http.StatusNotAcceptable,
+		Code:   api.CodeNotAcceptable,
+		Detail: fmt.Sprintf("none of the accepted media types is available. Available: %s", strings.Join(offers, ", ")),
+	}, l)
+	return "", false
+}
+
+// write encodes v in media type mt with the status
+func write(w http.ResponseWriter, status int, mt string, v any, l *slog.Logger) {
+	var buf bytes.Buffer
+	if err := encode(&buf, mt, v); err != nil {
+		l.Error(fmt.Errorf("cannot encode responce as %s: %w", mt, err).Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ct := mt
+	if mt != MediaJSON {
+		ct = mime.FormatMediaType(mt, map[string]string{"charset": "utf-8"})
+	}
+	w.Header().Set("Content-Type", ct)
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		l.Error(fmt.Errorf("cannot write responce to caller: %w", err).Error())
+	}
+}
+
+// Respond encodes v in the media type negotiated with the client and writes it with the status
+func Respond(w http.ResponseWriter, r *http.Request, status int, v any, l *slog.Logger) {
+	mt, ok := negotiate(w, r, itemMedia, l)
+	if !ok {
+		return
+	}
+	write(w, status, mt, v, l)
+}
+
+// csvFilename suggests the download name of a list export
+func csvFilename(r *http.Request) string {
+	name := path.Base(r.URL.Path)
+	if name == "/" || name == "." {
+		name = "export"
+	}
+	return mime.FormatMediaType("attachment", map[string]string{"filename": name + ".csv"})
+}
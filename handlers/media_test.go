@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"log/slog"
+	"mis-catanddog/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	type acceptTest struct {
+		Accept  []string
+		Offers  []string
+		Result  string
+		Ok      bool
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var arr = []acceptTest{
+		{Accept: nil, Offers: listMedia, Result: MediaJSON, Ok: true, Message: "positive test [no Accept] failed", Crit: true},
+		{Accept: []string{"*/*"}, Offers: listMedia, Result: MediaJSON, Ok: true, Message: "positive test [*/*] failed", Crit: true},
+		{Accept: []string{"text/csv"}, Offers: listMedia, Result: MediaCSV, Ok: true, Message: "positive test [text/csv] failed", Crit: true},
+		{Accept: []string{"text/*;q=0.9, application/yaml;q=0.5"}, Offers: listMedia, Result: MediaCSV, Ok: true, Message: "positive test [q values] failed", Crit: true},
+		{Accept: []string{"application/edn", "application/json;q=0.1"}, Offers: itemMedia, Result: MediaEDN, Ok: true, Message: "positive test [repeated header] failed", Crit: true},
+		{Accept: []string{"application/*, application/json;q=0"}, Offers: itemMedia, Result: MediaYAML, Ok: true, Message: "positive test [specific range excludes] failed", Crit: true},
+		{Accept: []string{"application/json; charset=utf-8"}, Offers: itemMedia, Result: MediaJSON, Ok: true, Message: "positive test [range with parameter] failed", Crit: true},
+		{Accept: []string{"text/csv"}, Offers: itemMedia, Ok: false, Message: "negative test [csv of an item] failed", Crit: true},
+		{Accept: []string{"text/html, */*;q=0"}, Offers: listMedia, Ok: false, Message: "negative test [nothing acceptable] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		result, ok := Negotiate(val.Accept, val.Offers...)
+		if ok != val.Ok || (ok && result != val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %s %t", val.Crit, val.Message, result, ok)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestValidateContentType(t *testing.T) {
+	type typeTest struct {
+		ContentType string
+		IsErr       bool
+		Message     string
+		Crit        bool
+	}
+
+	var fail bool
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var arr = []typeTest{
+		{ContentType: "application/json", Message: "positive test [application/json] failed", Crit: true},
+		{ContentType: "application/json; charset=utf-8", Message: "positive test [charset utf-8] failed", Crit: true},
+		{ContentType: "Application/JSON; charset=UTF-8", Message: "positive test [case insensitive] failed", Crit: true},
+		{ContentType: "", IsErr: true, Message: "negative test [not set] failed", Crit: true},
+		{ContentType: "application/json; charset=windows-1251", IsErr: true, Message: "negative test [charset] failed", Crit: true},
+		{ContentType: "text/plain", IsErr: true, Message: "negative test [text/plain] failed", Crit: true},
+		{ContentType: "application/json;;", IsErr: true, Message: "negative test [malformed] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		r := httptest.NewRequest(http.MethodPost, "/doc_type", nil)
+		if val.ContentType != "" {
+			r.Header.Set("Content-Type", val.ContentType)
+		}
+		w := httptest.NewRecorder()
+		err := ValidateContentType(w, r, log)
+		if (err != nil) != val.IsErr || (err != nil && w.Code != http.StatusBadRequest) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestRespond(t *testing.T) {
+	type owner struct {
+		DocId    int
+		LastName string
+	}
+	type pet struct {
+		DocId int
+		Name  string
+		Tags  []string
+		Owner *owner `json:",omitempty"`
+	}
+	type respondTest struct {
+		Method  string
+		Accept  string
+		Status  int
+		Type    string
+		Body    string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var item = pet{DocId: 7, Name: "Rex", Owner: &owner{DocId: 1, LastName: "Ivanov"}}
+	var arr = []respondTest{
+		{Method: http.MethodGet, Accept: "", Status: http.StatusOK, Type: "application/json", Body: `{"DocId":7,"Name":"Rex","Tags":null,"Owner":{"DocId":1,"LastName":"Ivanov"}}`, Message: "positive test [json] failed", Crit: true},
+		{Method: http.MethodGet, Accept: "application/yaml", Status: http.StatusOK, Type: "application/yaml; charset=utf-8", Body: "DocId: 7\nName: Rex\nOwner:\n    DocId: 1\n    LastName: Ivanov\nTags: null\n", Message: "positive test [yaml] failed", Crit: true},
+		{Method: http.MethodGet, Accept: "application/edn", Status: http.StatusOK, Type: "application/edn; charset=utf-8", Body: ":DocId 7", Message: "positive test [edn] failed", Crit: true},
+		{Method: http.MethodGet, Accept: "text/csv", Status: http.StatusNotAcceptable, Type: api.ProblemContentType, Body: api.CodeNotAcceptable, Message: "negative test [csv item] failed", Crit: true},
+		{Method: http.MethodPost, Accept: "text/csv", Status: http.StatusCreated, Type: "application/json", Body: `"DocId":7`, Message: "positive test [unacceptable write falls back to json] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		r := httptest.NewRequest(val.Method, "/animal", nil)
+		if val.Accept != "" {
+			r.Header.Set("Accept", val.Accept)
+		}
+		w := httptest.NewRecorder()
+		status := http.StatusOK
+		if val.Method == http.MethodPost {
+			status = http.StatusCreated
+		}
+		Respond(w, r, status, item, log)
+		if w.Code != val.Status || w.Header().Get("Content-Type") != val.Type || !strings.Contains(w.Body.String(), val.Body) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %d %s %q", val.Crit, val.Message, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	// csv flattens nested structs, skips slices and defuses formulas
+	var sb strings.Builder
+	items := []pet{item, {DocId: 8, Name: "=HYPERLINK(\"x\")"}}
+	if err := writeCSV(&sb, items); err != nil {
+		t.Logf("crit: true; positive test [csv] failed; %v", err)
+		fail = true
+	}
+	expected := "DocId,Name,Owner.DocId,Owner.LastName\n7,Rex,1,Ivanov\n8,\"'=HYPERLINK(\"\"x\"\")\",,\n"
+	if sb.String() != expected {
+		t.Logf("crit: true; positive test [csv] failed; got %q", sb.String())
+		fail = true
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
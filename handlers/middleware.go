@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/lg"
+	"net/http"
+	"time"
+)
+
+// sensitiveHeaders carry credentials, their values never reach the logs
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", api.APIKeyHeader}
+
+// redacted replaces values of sensitive headers
+const redacted = "[REDACTED]"
+
+// RedactHeaders returns copy of h with values of sensitive headers replaced
+func RedactHeaders(h http.Header) http.Header {
+	var result = h.Clone()
+	for _, val := range sensitiveHeaders {
+		if _, ok := result[http.CanonicalHeaderKey(val)]; ok {
+			result[http.CanonicalHeaderKey(val)] = []string{redacted}
+		}
+	}
+	return result
+}
+
+// requestIdMaxLen limits incoming request id, so callers can't flood the logs
+const requestIdMaxLen = 128
+
+// Middleware wraps http.Handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with mws. The first middleware is the outermost one
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// validRequestId accepts printable ASCII ids of a sane length only, since the id ends up in logs and headers
+func validRequestId(id string) bool {
+	if id == "" || len(id) > requestIdMaxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestId honors incoming X-Request-ID or generates a new one, echoes it in the response
+// and stores it in the request context together with the request-scoped logger
+func WithRequestId(l *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(api.RequestIdHeader)
+			if !validRequestId(id) {
+				id = uuid.NewString()
+			}
+			w.Header().Set(api.RequestIdHeader, id)
+
+			ctx := api.WithRequestId(r.Context(), id)
+			ctx = lg.WithLogger(ctx, l.With("request_id", id))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// statusWriter remembers the status code written by the handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// WithAccessLog logs every request and its outcome with the request-scoped logger.
+// Headers are logged on debug level with credentials redacted
+func WithAccessLog(l *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := lg.FromContext(r.Context(), l)
+			log.Info("request", "Method", r.Method, "Host", r.Host, "URL", r.URL)
+			if log.Enabled(r.Context(), slog.LevelDebug) {
+				log.Debug("request headers", "Header", RedactHeaders(r.Header))
+			}
+
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			log.Info("response", "Status", sw.status, "Duration", time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/lg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithRequestId(t *testing.T) {
+	type idTest struct {
+		Incoming string
+		Kept     bool
+		Message  string
+		Crit     bool
+	}
+
+	var fail bool
+	var arr = []idTest{
+		{Incoming: "abc-123", Kept: true, Message: "positive test [incoming id honored] failed", Crit: true},
+		{Incoming: "", Kept: false, Message: "positive test [missing id generated] failed", Crit: true},
+		{Incoming: "bad id\twith spaces", Kept: false, Message: "negative test [malformed id replaced] failed", Crit: true},
+		{Incoming: strings.Repeat("a", requestIdMaxLen+1), Kept: false, Message: "negative test [too long id replaced] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		var buf strings.Builder
+		var seen string
+		log := slog.New(slog.NewTextHandler(&buf, nil))
+		h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = api.RequestId(r.Context())
+			lg.FromContext(r.Context(), nil).Info("handled")
+		}), WithRequestId(log))
+
+		r := httptest.NewRequest(http.MethodGet, "/doc_type?id=1", nil)
+		if val.Incoming != "" {
+			r.Header.Set(api.RequestIdHeader, val.Incoming)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		var errs []string
+		echoed := w.Header().Get(api.RequestIdHeader)
+		if echoed == "" || echoed != seen {
+			errs = append(errs, fmt.Sprintf("echoed id [%s] differs from context id [%s]", echoed, seen))
+		}
+		if (echoed == val.Incoming) != val.Kept {
+			errs = append(errs, fmt.Sprintf("incoming id [%s], echoed id [%s]", val.Incoming, echoed))
+		}
+		if !strings.Contains(buf.String(), "request_id="+echoed) {
+			errs = append(errs, fmt.Sprintf("request-scoped logger does not carry the id; log [%s]", buf.String()))
+		}
+		if len(errs) > 0 {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, strings.Join(errs, "; "))
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestWithAccessLog(t *testing.T) {
+	var buf strings.Builder
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), WithRequestId(log), WithAccessLog(log))
+
+	r := httptest.NewRequest(http.MethodGet, "/doc_type?id=1", nil)
+	r.Header.Set(api.RequestIdHeader, "abc-123")
+	r.Header.Set("Authorization", "secret")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	out := buf.String()
+	if !strings.Contains(out, "Status=418") || strings.Count(out, "request_id=abc-123") != 2 {
+		t.Fatalf("expected request and response records with request id and status; got [%s]", out)
+	}
+	if strings.Contains(out, "secret") {
+		t.Fatalf("headers must not be logged; got [%s]", out)
+	}
+
+	// debug level logs headers with credentials redacted
+	buf.Reset()
+	log = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	h = Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), WithAccessLog(log))
+	r.Header.Set(api.APIKeyHeader, "secret")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("Accept", "text/csv")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	out = buf.String()
+	if strings.Contains(out, "secret") || !strings.Contains(out, redacted) || !strings.Contains(out, "text/csv") {
+		t.Fatalf("expected headers with credentials redacted; got [%s]", out)
+	}
+}
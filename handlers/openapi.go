@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OpenAPIPath is where the API description is served
+const OpenAPIPath = "/openapi.json"
+
+// openAPIVersion is the version of the API described by the document
+const openAPIVersion = "1.0.0"
+
+// obj is a JSON object of the document
+type obj = map[string]any
+
+// schemas builds JSON schemas of Go values and collects named ones as components
+type schemas map[string]any
+
+// ref returns reference to the component name, building it with build in case it is missing
+func (s schemas) ref(name string, build func() obj) obj {
+	if _, ok := s[name]; !ok {
+		s[name] = obj{} // stops recursion
+		s[name] = build()
+	}
+	return obj{"$ref": "#/components/schemas/" + name}
+}
+
+// of returns schema of v. Interface fields are described by their dynamic values, nil ones as any value
+func (s schemas) of(v reflect.Value) obj {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return s.of(reflect.Zero(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	// raw JSON is embedded as is, audit entries carry objects or null there
+	if v.Type() == reflect.TypeOf(json.RawMessage(nil)) {
+		return obj{"type": "object", "nullable": true}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return obj{}
+		}
+		return s.of(v.Elem())
+	case reflect.Bool:
+		return obj{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return obj{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return obj{"type": "number"}
+	case reflect.String:
+		return obj{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		item := reflect.Zero(v.Type().Elem())
+		if v.Len() > 0 {
+			item = v.Index(0)
+		}
+		return obj{"type": "array", "items": s.of(item)}
+	case reflect.Map:
+		return obj{"type": "object", "additionalProperties": s.of(reflect.Zero(v.Type().Elem()))}
+	case reflect.Struct:
+		name := v.Type().Name()
+		// pages differ by item type only
+		if page, ok := v.Interface().(ListPage); ok {
+			name = reflect.TypeOf(page.Items).Elem().Name() + "Page"
+		}
+		if name == "" {
+			return s.object(v)
+		}
+		return s.ref(name, func() obj { return s.object(v) })
+	}
+	return obj{}
+}
+
+// object returns schema of struct v. Field names follow encoding/json
+func (s schemas) object(v reflect.Value) obj {
+	var props = obj{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.of(v.Field(i))
+	}
+	return obj{"type": "object", "properties": props}
+}
+
+// paramSchema returns schema of a single value of p
+func paramSchema(p Param) obj {
+	var result obj
+	switch p.Type {
+	case ParamInt:
+		result = obj{"type": "integer"}
+		if p.Range != nil {
+			result["minimum"] = p.Range.Min
+			if p.Range.Max != Positive.Max {
+				result["maximum"] = p.Range.Max
+			}
+		}
+	case ParamDate:
+		result = obj{"type": "string", "format": "date"}
+	default:
+		result = obj{"type": "string"}
+		if p.Range != nil {
+			result["minLength"], result["maxLength"] = p.Range.Min, p.Range.Max
+		}
+	}
+	return result
+}
+
+// queryParameters describes parameters of q. Rules other than required are put into the returned description
+func queryParameters(q QuerySchema) ([]obj, string) {
+	var result []obj
+	var notes []string
+	var required []string
+
+	for _, val := range q.Rules {
+		switch val.Mode {
+		case ModeRequired:
+			required = append(required, val.Params...)
+		case ModeOneOf:
+			notes = append(notes, "At most one of: "+strings.Join(val.Params, ", ")+".")
+		case ModeAnyOf:
+			notes = append(notes, "At least one of: "+strings.Join(val.Params, ", ")+".")
+		case ModeAllOf:
+			notes = append(notes, "Either all or none of: "+strings.Join(val.Params, ", ")+".")
+		}
+	}
+
+	for _, val := range q.Params {
+		schema := paramSchema(val)
+		if val.MaxCount > 1 {
+			schema = obj{"type": "array", "items": schema, "maxItems": val.MaxCount}
+		}
+		result = append(result, obj{"name": val.Name, "in": "query", "required": slices.Contains(required, val.Name), "schema": schema, "explode": true})
+	}
+
+	if q.List != nil {
+		result = append(result, listParameters(*q.List)...)
+		notes = append(notes, "Without lookup parameters a page of the list is returned.")
+	}
+	return result, strings.Join(notes, " ")
+}
+
+// listParameters describes parameters parsed by ParseList
+func listParameters(spec controllers.ListSpec) []obj {
+	var names []string
+	for _, val := range spec.Fields {
+		names = append(names, val.Name)
+	}
+	var result = []obj{
+		{"name": "limit", "in": "query", "schema": obj{"type": "integer", "minimum": 1, "maximum": listLimitMax, "default": listLimitDefault}},
+		{"name": "sort", "in": "query", "description": "Comma separated fields, '-' prefix sorts descending. Fields: " + strings.Join(names, ", "), "schema": obj{"type": "string"}},
+		{"name": "cursor", "in": "query", "description": "NextCursor of the previous page", "schema": obj{"type": "string"}},
+	}
+
+	for _, val := range spec.Fields {
+		var ops = []controllers.ListOp{controllers.OpEq, controllers.OpLike}
+		var schema = obj{"type": "string"}
+		switch val.Type {
+		case controllers.ListInt:
+			ops, schema = []controllers.ListOp{controllers.OpEq, controllers.OpGte, controllers.OpLte}, obj{"type": "integer"}
+		case controllers.ListDate:
+			ops, schema = []controllers.ListOp{controllers.OpEq, controllers.OpGte, controllers.OpLte}, obj{"type": "string", "format": "date"}
+		}
+		for _, op := range ops {
+			p := obj{"name": fmt.Sprintf("%s[%s]", val.Name, op), "in": "query", "schema": schema}
+			if op == controllers.OpLike {
+				p["description"] = "'*' matches any sequence of characters"
+			}
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// content describes body in every offered media type
+func content(s schemas, bodies []any, list bool) obj {
+	var schema obj
+	if len(bodies) == 1 {
+		schema = s.of(reflect.ValueOf(bodies[0]))
+	} else {
+		var oneOf []obj
+		for _, val := range bodies {
+			oneOf = append(oneOf, s.of(reflect.ValueOf(val)))
+		}
+		schema = obj{"oneOf": oneOf}
+	}
+
+	var result = obj{}
+	for _, val := range itemMedia {
+		result[val] = obj{"schema": schema}
+	}
+	if list {
+		result[MediaCSV] = obj{"schema": obj{"type": "string"}}
+	}
+	return result
+}
+
+// securitySchemes documents credentials accepted by WithAuth
+var securitySchemes = obj{
+	"apiKey": obj{"type": "apiKey", "in": "header", "name": api.APIKeyHeader},
+	"bearer": obj{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+}
+
+// OpenAPI returns OpenAPI 3 document describing routes. Routes that are not public require credentials
+func OpenAPI(routes []Route) obj {
+	var s = schemas{}
+	var paths = obj{}
+
+	problem := obj{"description": "Problem details, see RFC 7807", "content": obj{api.ProblemContentType: obj{"schema": s.of(reflect.ValueOf(api.Problem{}))}}}
+	for _, route := range routes {
+		var item = obj{"summary": route.Summary}
+		for _, op := range route.Operations {
+			var params []obj
+			for _, val := range op.Path {
+				params = append(params, obj{"name": val.Name, "in": "path", "required": true, "schema": paramSchema(val)})
+			}
+			query, notes := queryParameters(op.Query)
+			params = append(params, query...)
+			var o = obj{"summary": op.Summary, "responses": obj{"default": problem}}
+			if route.Public {
+				o["security"] = []obj{}
+			}
+			if notes != "" {
+				o["description"] = notes
+			}
+			if len(params) > 0 {
+				o["parameters"] = params
+			}
+			if op.Body != nil {
+				o["requestBody"] = obj{"required": true, "content": obj{MediaJSON: obj{"schema": s.of(reflect.ValueOf(op.Body))}}}
+			}
+			for _, val := range op.Responses {
+				resp := obj{"description": val.Description}
+				if len(val.Bodies) > 0 {
+					resp["content"] = content(s, val.Bodies, val.List)
+				}
+				o["responses"].(obj)[strconv.Itoa(val.Status)] = resp
+			}
+			item[strings.ToLower(op.Method)] = o
+		}
+		paths[route.Path] = item
+	}
+
+	return obj{
+		"openapi":    "3.0.3",
+		"info":       obj{"title": "mis-catanddog", "version": openAPIVersion},
+		"paths":      paths,
+		"security":   []obj{{"apiKey": []string{}}, {"bearer": []string{}}},
+		"components": obj{"schemas": s, "securitySchemes": securitySchemes},
+	}
+}
+
+// openAPIRoute documents the document itself
+var openAPIRoute = Route{
+	Path:    OpenAPIPath,
+	Summary: "API description",
+	Public:  true,
+	Operations: []Operation{{
+		Method:    http.MethodGet,
+		Summary:   "OpenAPI 3 document of every route",
+		Responses: []Response{{Status: http.StatusOK, Description: "OpenAPI document", Bodies: []any{obj{}}}},
+	}},
+}
+
+// OpenAPIHandler serves OpenAPI document of the routes collected by rt. The document is built on the first
+// request, so it covers every route registered by then
+type OpenAPIHandler struct {
+	rt   *Router
+	once sync.Once
+	doc  []byte
+	err  error
+	log  *slog.Logger
+}
+
+// NewOpenAPIHandler returns handler serving document of routes collected by rt
+func NewOpenAPIHandler(rt *Router, l *slog.Logger) *OpenAPIHandler {
+	return &OpenAPIHandler{rt: rt, log: l}
+}
+
+// Route documents the handler
+func (h *OpenAPIHandler) Route() Route {
+	return openAPIRoute
+}
+
+// ServeHTTP writes the document
+func (h *OpenAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		MethodNotAllowed(w, r, h.log, openAPIRoute.Methods())
+		return
+	}
+	h.once.Do(func() { h.doc, h.err = json.Marshal(OpenAPI(h.rt.Routes())) })
+	if h.err != nil {
+		InternalError(w, r, h.log, fmt.Errorf("cannot build openapi document: %w", h.err))
+		return
+	}
+	w.Header().Set("Content-Type", MediaJSON)
+	if _, err := w.Write(h.doc); err != nil {
+		h.log.Error(fmt.Errorf("cannot write responce to caller: %w", err).Error())
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestQueryParameters(t *testing.T) {
+	type paramTest struct {
+		Schema   QuerySchema
+		Name     string
+		Param    obj
+		Required bool
+		Message  string
+		Crit     bool
+	}
+
+	var fail bool
+	var arr = []paramTest{
+		{
+			Schema:  QuerySchema{Params: []Param{{Name: "id", Type: ParamInt, Range: Positive}}},
+			Name:    "id",
+			Param:   obj{"type": "integer", "minimum": 1},
+			Message: "positive test [positive int] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{Params: []Param{{Name: "name", Type: ParamString, Range: &Range{Min: 1, Max: 8}}}},
+			Name:    "name",
+			Param:   obj{"type": "string", "minLength": 1, "maxLength": 8},
+			Message: "positive test [string length] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{Params: []Param{{Name: "id", Type: ParamInt, MaxCount: 5}}},
+			Name:    "id",
+			Param:   obj{"type": "array", "items": obj{"type": "integer"}, "maxItems": 5},
+			Message: "positive test [repeated values] failed", Crit: true,
+		},
+		{
+			Schema:   QuerySchema{Params: []Param{{Name: "d", Type: ParamDate}}, Rules: []Rule{{Mode: ModeRequired, Params: []string{"d"}}}},
+			Name:     "d",
+			Param:    obj{"type": "string", "format": "date"},
+			Required: true,
+			Message:  "positive test [required date] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{List: &controllers.DocTypeListSpec},
+			Name:    "limit",
+			Param:   obj{"type": "integer", "minimum": 1, "maximum": listLimitMax, "default": listLimitDefault},
+			Message: "positive test [list limit] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{List: &controllers.DocTypeListSpec},
+			Name:    "doc[like]",
+			Param:   obj{"type": "string"},
+			Message: "positive test [text filter] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{List: &controllers.DocTypeListSpec},
+			Name:    "id[gte]",
+			Param:   obj{"type": "integer"},
+			Message: "positive test [int filter] failed", Crit: true,
+		},
+		{
+			Schema:  QuerySchema{List: &controllers.DocTypeListSpec},
+			Name:    "id[like]",
+			Message: "negative test [like of int field] failed", Crit: true,
+		},
+	}
+
+	for _, val := range arr {
+		var found obj
+		params, _ := queryParameters(val.Schema)
+		for _, p := range params {
+			if p["name"] == val.Name {
+				found = p
+			}
+		}
+		switch {
+		case val.Param == nil && found != nil:
+		case val.Param != nil && (found == nil || !reflect.DeepEqual(found["schema"], val.Param) || (found["required"] == true) != val.Required):
+		default:
+			continue
+		}
+		if val.Crit {
+			fail = true
+		}
+		t.Logf("crit: %t; %s; got %v", val.Crit, val.Message, found)
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	type docTest struct {
+		Path    []string
+		Result  any
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var route = Route{
+		Path: "/item",
+		Operations: []Operation{
+			{
+				Method:    http.MethodGet,
+				Query:     QuerySchema{Params: []Param{{Name: "a", Type: ParamInt}, {Name: "b", Type: ParamInt}}, Rules: []Rule{{Mode: ModeOneOf, Params: []string{"a", "b"}}}},
+				Responses: []Response{{Status: http.StatusOK, Bodies: []any{ListPage{Items: []controllers.Animal{}}, controllers.Animal{}}, List: true}},
+			},
+			{Method: http.MethodPost, Body: controllers.DocType{}, Responses: []Response{{Status: http.StatusCreated, Bodies: []any{controllers.DocType{}}}}},
+			{Method: http.MethodDelete, Responses: []Response{{Status: http.StatusNoContent}}},
+		},
+	}
+
+	// compare the document the way clients see it
+	var doc any
+	var probe = Route{Path: "/probe", Public: true, Operations: []Operation{{Method: http.MethodGet}}}
+	raw, err := json.Marshal(OpenAPI([]Route{route, probe}))
+	if err != nil {
+		t.Fatalf("cannot encode document: %s", err.Error())
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("cannot decode document: %s", err.Error())
+	}
+
+	var arr = []docTest{
+		{Path: []string{"openapi"}, Result: "3.0.3", Message: "positive test [version] failed", Crit: true},
+		{Path: []string{"paths", "/item", "get", "description"}, Result: "At most one of: a, b.", Message: "positive test [rule description] failed", Crit: true},
+		{Path: []string{"paths", "/item", "get", "responses", "200", "content", "text/csv", "schema", "type"}, Result: "string", Message: "positive test [csv of list] failed", Crit: true},
+		{Path: []string{"paths", "/item", "get", "responses", "200", "content", "application/json", "schema", "oneOf"}, Result: []any{map[string]any{"$ref": "#/components/schemas/AnimalPage"}, map[string]any{"$ref": "#/components/schemas/Animal"}}, Message: "positive test [oneOf bodies] failed", Crit: true},
+		{Path: []string{"paths", "/item", "get", "responses", "default", "content", api.ProblemContentType, "schema", "$ref"}, Result: "#/components/schemas/Problem", Message: "positive test [problem response] failed", Crit: true},
+		{Path: []string{"paths", "/item", "post", "requestBody", "content", "application/json", "schema", "$ref"}, Result: "#/components/schemas/DocType", Message: "positive test [request body] failed", Crit: true},
+		{Path: []string{"paths", "/item", "delete", "responses", "204", "content"}, Result: nil, Message: "positive test [no content] failed", Crit: true},
+		{Path: []string{"components", "schemas", "AnimalPage", "properties", "Items", "items", "$ref"}, Result: "#/components/schemas/Animal", Message: "positive test [page items] failed", Crit: true},
+		{Path: []string{"components", "schemas", "Animal", "properties", "Owner", "$ref"}, Result: "#/components/schemas/Human", Message: "positive test [nested struct] failed", Crit: true},
+		{Path: []string{"components", "schemas", "Problem", "properties", "request_id", "type"}, Result: "string", Message: "positive test [json tag name] failed", Crit: true},
+		{Path: []string{"components", "securitySchemes", "apiKey", "name"}, Result: api.APIKeyHeader, Message: "positive test [api key scheme] failed", Crit: true},
+		{Path: []string{"security"}, Result: []any{map[string]any{"apiKey": []any{}}, map[string]any{"bearer": []any{}}}, Message: "positive test [global security] failed", Crit: true},
+		{Path: []string{"paths", "/item", "get", "security"}, Result: nil, Message: "positive test [secured operation] failed", Crit: true},
+		{Path: []string{"paths", "/probe", "get", "security"}, Result: []any{}, Message: "positive test [public operation] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		var got = doc
+		for _, key := range val.Path {
+			m, _ := got.(map[string]any)
+			got = m[key]
+		}
+		if !reflect.DeepEqual(got, val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %v", val.Crit, val.Message, got)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
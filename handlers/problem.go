@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"strings"
+)
+
+// internalDetail is the only detail callers get about server side failures
+const internalDetail = "the server failed to process the request; report request_id to support"
+
+// retryAfter is the Retry-After header value in seconds sent along with http.StatusServiceUnavailable
+const retryAfter = "1"
+
+// fill sets type, title, instance and request id of p in case they are empty
+func fill(p api.Problem, r *http.Request) api.Problem {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestId == "" {
+		p.RequestId = api.RequestId(r.Context())
+	}
+	return p
+}
+
+// WriteProblem writes p to the caller. Type, title, instance and request id are filled in when empty
+func WriteProblem(w http.ResponseWriter, r *http.Request, p api.Problem, l *slog.Logger) {
+	p = fill(p, r)
+	if p.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+
+	w.Header().Set("Content-Type", api.ProblemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		l.Error(fmt.Errorf("cannot write responce to caller: %w", err).Error())
+	}
+}
+
+// fieldErrors collects every api.FieldError wrapped or joined into err
+func fieldErrors(err error) []api.FieldError {
+	var result []api.FieldError
+	var fe api.FieldError
+	switch e := err.(type) {
+	case api.FieldError:
+		return append(result, e)
+	case interface{ Unwrap() []error }:
+		for _, val := range e.Unwrap() {
+			result = append(result, fieldErrors(val)...)
+		}
+		return result
+	}
+	if errors.As(err, &fe) {
+		result = append(result, fe)
+	}
+	return result
+}
+
+// BadRequest logs err and responds with 400. err is expected to come from request validation,
+// so its text is safe to share. FieldErrors found in err are reported field by field
+func BadRequest(w http.ResponseWriter, r *http.Request, l *slog.Logger, err error) {
+	l.Error(err.Error())
+	var p = api.Problem{Status: http.StatusBadRequest, Code: api.CodeBadRequest, Detail: err.Error()}
+	if fields := fieldErrors(err); len(fields) > 0 {
+		var details = make([]string, 0, len(fields))
+		for _, val := range fields {
+			details = append(details, val.Error())
+		}
+		p.Code = api.CodeValidation
+		p.Detail = strings.Join(details, "; ")
+		p.Errors = fields
+	}
+	WriteProblem(w, r, p, l)
+}
+
+// RepoProblem maps repository error to the problem. Internal details are never shared
+func RepoProblem(r *http.Request, err error) api.Problem {
+	var p api.Problem
+	switch {
+	case errors.Is(err, controllers.ErrForbidden):
+		p = api.Problem{Status: http.StatusForbidden, Code: api.CodeForbidden, Detail: "the caller lacks permission for the operation"}
+	case errors.Is(err, controllers.ErrNotFound):
+		p = api.Problem{Status: http.StatusNotFound, Code: api.CodeNotFound, Detail: "requested record does not exist"}
+	case errors.Is(err, controllers.ErrAlreadyExists):
+		p = api.Problem{Status: http.StatusConflict, Code: api.CodeConflict, Detail: "record already exists"}
+	case errors.Is(err, controllers.ErrReference):
+		p = api.Problem{Status: http.StatusConflict, Code: api.CodeReference, Detail: "the change breaks a reference between records"}
+	case errors.Is(err, controllers.ErrUnavailable):
+		p = api.Problem{Status: http.StatusServiceUnavailable, Code: api.CodeUnavailable, Detail: "the database is temporarily unavailable; retry later"}
+	default:
+		p = api.Problem{Status: http.StatusInternalServerError, Code: api.CodeInternal, Detail: internalDetail}
+	}
+	return fill(p, r)
+}
+
+// RepoError logs err and responds with the status matching it. Internal details are never shared
+func RepoError(w http.ResponseWriter, r *http.Request, l *slog.Logger, err error) {
+	l.Error(err.Error())
+	WriteProblem(w, r, RepoProblem(r, err), l)
+}
+
+// InvalidRequest reports validation err. FieldErrors are the caller's fault, anything else is a repository failure
+func InvalidRequest(w http.ResponseWriter, r *http.Request, l *slog.Logger, err error) {
+	if len(fieldErrors(err)) > 0 {
+		BadRequest(w, r, l, err)
+		return
+	}
+	RepoError(w, r, l, err)
+}
+
+// InternalError logs err and responds with 500 without sharing any details
+func InternalError(w http.ResponseWriter, r *http.Request, l *slog.Logger, err error) {
+	l.Error(err.Error())
+	WriteProblem(w, r, api.Problem{Status: http.StatusInternalServerError, Code: api.CodeInternal, Detail: internalDetail}, l)
+}
+
+// MethodNotAllowed sets Allow header and responds with 405
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, l *slog.Logger, allowed []string) {
+	l.Error(fmt.Sprintf("unexpected method %s", r.Method))
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	WriteProblem(w, r, api.Problem{Status: http.StatusMethodNotAllowed, Code: api.CodeMethodNotAllowed, Detail: fmt.Sprintf("method %s is not allowed", r.Method)}, l)
+}
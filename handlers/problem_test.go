@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	type problemTest struct {
+		Write   func(w http.ResponseWriter, r *http.Request, l *slog.Logger)
+		Status  int
+		Code    string
+		Fields  int
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var secret = errors.New("dial tcp 10.0.0.1:5432: connection refused")
+	var arr = []problemTest{
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				BadRequest(w, r, l, fmt.Errorf("ambiguous query"))
+			},
+			Status: http.StatusBadRequest, Code: api.CodeBadRequest, Fields: 0,
+			Message: "positive test [plain bad request] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				BadRequest(w, r, l, errors.Join(api.FieldError{Field: "Doc", Detail: "must not be empty"}, api.FieldError{Field: "Id", Detail: "must not be negative"}))
+			},
+			Status: http.StatusBadRequest, Code: api.CodeValidation, Fields: 2,
+			Message: "positive test [joined field errors] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				RepoError(w, r, l, fmt.Errorf("doc_type id 7: %w", controllers.ErrNotFound))
+			},
+			Status: http.StatusNotFound, Code: api.CodeNotFound, Fields: 0,
+			Message: "positive test [not found] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				RepoError(w, r, l, fmt.Errorf("doc_type passport: %w", controllers.ErrAlreadyExists))
+			},
+			Status: http.StatusConflict, Code: api.CodeConflict, Fields: 0,
+			Message: "positive test [conflict] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				RepoError(w, r, l, fmt.Errorf("doc_type id 1: %w", controllers.ErrReference))
+			},
+			Status: http.StatusConflict, Code: api.CodeReference, Fields: 0,
+			Message: "positive test [reference violation] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				RepoError(w, r, l, secret)
+			},
+			Status: http.StatusInternalServerError, Code: api.CodeInternal, Fields: 0,
+			Message: "positive test [repo failure] failed", Crit: true,
+		},
+		{
+			Write: func(w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+				MethodNotAllowed(w, r, l, []string{http.MethodGet})
+			},
+			Status: http.StatusMethodNotAllowed, Code: api.CodeMethodNotAllowed, Fields: 0,
+			Message: "positive test [method not allowed] failed", Crit: true,
+		},
+	}
+
+	for _, val := range arr {
+		var p api.Problem
+		var errs []string
+		h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			val.Write(w, r, log)
+		}), WithRequestId(log))
+		r := httptest.NewRequest(http.MethodGet, "/doc_type?id=7", nil)
+		r.Header.Set(api.RequestIdHeader, "abc-123")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			errs = append(errs, fmt.Sprintf("cannot decode body: %s", err.Error()))
+		}
+		if w.Code != val.Status || p.Status != val.Status || p.Code != val.Code || len(p.Errors) != val.Fields {
+			errs = append(errs, fmt.Sprintf("got status %d, problem %+v", w.Code, p))
+		}
+		if w.Header().Get("Content-Type") != api.ProblemContentType || p.RequestId != "abc-123" || p.Instance != "/doc_type" {
+			errs = append(errs, fmt.Sprintf("unexpected envelope; content type [%s], problem %+v", w.Header().Get("Content-Type"), p))
+		}
+		if strings.Contains(p.Detail, secret.Error()) {
+			errs = append(errs, "internal error details leaked to the caller")
+		}
+		if len(errs) > 0 {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %s", val.Crit, val.Message, strings.Join(errs, "; "))
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mis-catanddog/api"
+	"mis-catanddog/controllers"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ParamType tells how values of a query parameter are parsed
+type ParamType int
+
+const (
+	ParamString ParamType = iota
+	ParamInt
+	ParamDate
+)
+
+// Range bounds int values and string lengths, both ends included
+type Range struct {
+	Min int
+	Max int
+}
+
+// Positive is the range of ids
+var Positive = &Range{Min: 1, Max: int(^uint(0) >> 1)}
+
+// Param describes a single query parameter. Range is not checked in case it is nil.
+// MaxCount caps the number of values, 0 means a single value
+type Param struct {
+	Name     string
+	Type     ParamType
+	Range    *Range
+	MaxCount int
+}
+
+// rule modes of validateUrl
+const (
+	ModeOneOf    = "oneOf"    // at most one of the params is present
+	ModeAnyOf    = "anyOf"    // at least one of the params is present
+	ModeAllOf    = "allOf"    // either all of the params are present or none of them
+	ModeRequired = "required" // every param is present
+)
+
+// Rule applies Mode to presence of Params
+type Rule struct {
+	Mode   string
+	Params []string
+}
+
+// QuerySchema is the query contract of a request. Parameters not declared in Params are rejected,
+// except list parameters and field[op] filters of List in case it is set, those are left for ParseList
+type QuerySchema struct {
+	Params []Param
+	Rules  []Rule
+	List   *controllers.ListSpec
+}
+
+// validateUrl validetes query URL according to the required logic. Valid modes are
+// oneOf: at most one of array is present in query. In case more than one is present returns error
+// anyOf: at least one of array is present
+// allOf: either all of array are present or none of them
+// required: every item of array is present
+func validateUrl(q url.Values, mode string, array ...string) error {
+	var present []string
+	for _, val := range array {
+		if q.Has(val) {
+			present = append(present, val)
+		}
+	}
+	field := strings.Join(array, ", ")
+
+	switch mode {
+	case ModeOneOf:
+		if len(present) > 1 {
+			return api.FieldError{Field: field, Detail: fmt.Sprintf("at most one of the parameters expected, got %s", strings.Join(present, ", "))}
+		}
+	case ModeAnyOf:
+		if len(present) == 0 {
+			return api.FieldError{Field: field, Detail: "at least one of the parameters expected"}
+		}
+	case ModeAllOf:
+		if len(present) != 0 && len(present) != len(array) {
+			return api.FieldError{Field: field, Detail: fmt.Sprintf("either all or none of the parameters expected, got %s", strings.Join(present, ", "))}
+		}
+	case ModeRequired:
+		for _, val := range array {
+			if !q.Has(val) {
+				return api.FieldError{Field: val, Detail: "parameter is required"}
+			}
+		}
+	default:
+		return fmt.Errorf("unknown validation mode [%s]", mode)
+	}
+	return nil
+}
+
+// validateParam checks multiplicity, type and range of p values
+func validateParam(p Param, vals []string) error {
+	if max := max(p.MaxCount, 1); len(vals) > max {
+		return api.FieldError{Field: p.Name, Detail: fmt.Sprintf("at most %d values expected, got %d", max, len(vals))}
+	}
+
+	for _, val := range vals {
+		var n int
+		switch p.Type {
+		case ParamInt:
+			var err error
+			if n, err = strconv.Atoi(val); err != nil {
+				return api.FieldError{Field: p.Name, Detail: fmt.Sprintf("failed to convert [%s] to an integer", val)}
+			}
+		case ParamDate:
+			if _, err := time.Parse(controllers.DateLayout, val); err != nil {
+				return api.FieldError{Field: p.Name, Detail: fmt.Sprintf("[%s] is not a %s date", val, controllers.DateLayout)}
+			}
+			continue
+		default:
+			n = utf8.RuneCountInString(val)
+		}
+
+		if p.Range == nil || (n >= p.Range.Min && n <= p.Range.Max) {
+			continue
+		}
+		if p.Type == ParamInt {
+			return api.FieldError{Field: p.Name, Detail: fmt.Sprintf("[%s] is out of range [%d, %d]", val, p.Range.Min, p.Range.Max)}
+		}
+		return api.FieldError{Field: p.Name, Detail: fmt.Sprintf("length of [%s] is out of range [%d, %d]", val, p.Range.Min, p.Range.Max)}
+	}
+	return nil
+}
+
+// listParam reports whether name is left for ParseList
+func (s QuerySchema) listParam(name string) bool {
+	if s.List == nil {
+		return false
+	}
+	if slices.Contains(listParams, name) {
+		return true
+	}
+	field, _, ok := strings.Cut(name, "[")
+	_, okField := s.List.Field(field)
+	return ok && okField && strings.HasSuffix(name, "]")
+}
+
+// Validate checks q against the schema. Returns every violation found as joined api.FieldError values
+func (s QuerySchema) Validate(q url.Values) error {
+	var errs []error
+
+	for key := range q {
+		if slices.IndexFunc(s.Params, func(p Param) bool { return p.Name == key }) < 0 && !s.listParam(key) {
+			errs = append(errs, api.FieldError{Field: key, Detail: "unknown parameter"})
+		}
+	}
+	// map order is random, keep error order stable
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+
+	for _, val := range s.Params {
+		if vals, ok := q[val.Name]; ok {
+			if err := validateParam(val, vals); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, val := range s.Rules {
+		if err := validateUrl(q, val.Mode, val.Params...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PathInt returns path value name validated as ParamInt. Returns 0 in case it is absent
+func PathInt(r *http.Request, name string) int {
+	n, _ := strconv.Atoi(r.PathValue(name))
+	return n
+}
+
+// QueryInt returns the first value of a parameter validated as ParamInt. Returns 0 in case it is absent
+func QueryInt(q url.Values, name string) int {
+	n, _ := strconv.Atoi(q.Get(name))
+	return n
+}
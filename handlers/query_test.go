@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"mis-catanddog/api"
+	"net/url"
+	"testing"
+)
+
+func TestValidateUrl(t *testing.T) {
+	type modeTest struct {
+		Url     url.Values
+		Mode    string
+		Array   []string
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var arr = []modeTest{
+		{Url: url.Values{"id": {"1"}}, Mode: ModeOneOf, Array: []string{"id", "doc"}, Message: "positive test [oneOf single] failed", Crit: true},
+		{Url: url.Values{}, Mode: ModeOneOf, Array: []string{"id", "doc"}, Message: "positive test [oneOf none] failed", Crit: true},
+		{Url: url.Values{"id": {"1"}, "doc": {"passport"}}, Mode: ModeOneOf, Array: []string{"id", "doc"}, IsErr: true, Message: "negative test [oneOf both] failed", Crit: true},
+		{Url: url.Values{"doc": {"passport"}}, Mode: ModeAnyOf, Array: []string{"id", "doc"}, Message: "positive test [anyOf single] failed", Crit: true},
+		{Url: url.Values{}, Mode: ModeAnyOf, Array: []string{"id", "doc"}, IsErr: true, Message: "negative test [anyOf none] failed", Crit: true},
+		{Url: url.Values{}, Mode: ModeAllOf, Array: []string{"from", "to"}, Message: "positive test [allOf none] failed", Crit: true},
+		{Url: url.Values{"from": {"1"}, "to": {"2"}}, Mode: ModeAllOf, Array: []string{"from", "to"}, Message: "positive test [allOf all] failed", Crit: true},
+		{Url: url.Values{"from": {"1"}}, Mode: ModeAllOf, Array: []string{"from", "to"}, IsErr: true, Message: "negative test [allOf part] failed", Crit: true},
+		{Url: url.Values{"id": {"1"}}, Mode: ModeRequired, Array: []string{"id"}, Message: "positive test [required] failed", Crit: true},
+		{Url: url.Values{}, Mode: ModeRequired, Array: []string{"id"}, IsErr: true, Message: "negative test [required missing] failed", Crit: true},
+		{Url: url.Values{}, Mode: "noneOf", Array: []string{"id"}, IsErr: true, Message: "negative test [unknown mode] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		err := validateUrl(val.Url, val.Mode, val.Array...)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestQuerySchema(t *testing.T) {
+	type schemaTest struct {
+		Url     url.Values
+		Fields  []string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var schema = QuerySchema{
+		Params: []Param{
+			{Name: "id", Type: ParamInt, Range: Positive, MaxCount: 3},
+			{Name: "name", Type: ParamString, Range: &Range{Min: 2, Max: 5}},
+			{Name: "from", Type: ParamDate},
+			{Name: "to", Type: ParamDate},
+		},
+		Rules: []Rule{{Mode: ModeAllOf, Params: []string{"from", "to"}}},
+	}
+	var arr = []schemaTest{
+		{Url: url.Values{"id": {"1", "2", "3"}, "name": {"Рекс"}}, Message: "positive test [multiple ids, utf-8 name] failed", Crit: true},
+		{Url: url.Values{"from": {"2020-01-01"}, "to": {"2021-01-01"}}, Message: "positive test [dates] failed", Crit: true},
+		{Url: url.Values{"id": {"1", "2", "3", "4"}}, Fields: []string{"id"}, Message: "negative test [too many ids] failed", Crit: true},
+		{Url: url.Values{"id": {"0"}}, Fields: []string{"id"}, Message: "negative test [id out of range] failed", Crit: true},
+		{Url: url.Values{"name": {"R"}}, Fields: []string{"name"}, Message: "negative test [name too short] failed", Crit: true},
+		{Url: url.Values{"name": {"a", "b"}}, Fields: []string{"name"}, Message: "negative test [repeated single value] failed", Crit: true},
+		{Url: url.Values{"from": {"2020-13-01"}}, Fields: []string{"from", "from, to"}, Message: "negative test [bad date, missing pair] failed", Crit: true},
+		{Url: url.Values{"limit": {"1"}, "id": {"x"}}, Fields: []string{"limit", "id"}, Message: "negative test [unknown parameter and bad id] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		err := schema.Validate(val.Url)
+		fields := fieldErrors(err)
+		ok := len(fields) == len(val.Fields) && (err == nil) == (len(val.Fields) == 0)
+		for i := 0; ok && i < len(fields); i++ {
+			ok = fields[i].Field == val.Fields[i]
+		}
+		if !ok {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	// every violation is an api.FieldError, so it is reported as validation_failed
+	var fe api.FieldError
+	if err := schema.Validate(url.Values{"id": {"x"}}); !errors.As(err, &fe) {
+		t.Logf("crit: true; negative test [field error] failed; %v", err)
+		fail = true
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mis-catanddog/lg"
+	"net/http"
+	"slices"
+)
+
+// V1 is the path prefix of the first version of the resource API
+const V1 = "/api/v1"
+
+// PathId is the path wildcard of routes addressing a single record
+var PathId = []Param{{Name: "id", Type: ParamInt, Range: Positive}}
+
+// OperationFunc serves an operation. l is the request-scoped logger
+type OperationFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger)
+
+// Route documents a registered path. Operations are listed in the order methods appear in the Allow header.
+// Public routes are served without authentication
+type Route struct {
+	Path       string
+	Summary    string
+	Public     bool
+	Operations []Operation
+}
+
+// Methods returns methods of all operations
+func (r Route) Methods() []string {
+	var result = make([]string, 0, len(r.Operations))
+	for _, val := range r.Operations {
+		result = append(result, val.Method)
+	}
+	return result
+}
+
+// Operation documents a single method of a route. Body is a value of the request body type, nil if there is none.
+// Every operation may respond with an api.Problem, so problems are not listed in Responses.
+// Path lists wildcards of the route path. Handler serves operations of routes registered with Router.HandleRoutes,
+// it gets the request with Path and Query already validated
+type Operation struct {
+	Method    string
+	Summary   string
+	Path      []Param
+	Query     QuerySchema
+	Body      any
+	Responses []Response
+	Handler   OperationFunc
+}
+
+// Response documents a successful response. Bodies are values of possible body types, nil means no body.
+// List means the body is a ListPage, that is also offered as CSV
+type Response struct {
+	Status      int
+	Description string
+	Bodies      []any
+	List        bool
+}
+
+// Documented is a handler that describes its route
+type Documented interface {
+	http.Handler
+	Route() Route
+}
+
+// Router registers handlers on mux and collects routes of the documented ones
+type Router struct {
+	mux      *http.ServeMux
+	patterns []string
+	routes   []Route
+	public   map[string]bool
+	log      *slog.Logger
+}
+
+// NewRouter returns Router registering handlers on mux
+func NewRouter(mux *http.ServeMux, l *slog.Logger) *Router {
+	return &Router{mux: mux, public: map[string]bool{}, log: l}
+}
+
+// Handle registers h for pattern. Route of h is collected in case h is Documented
+func (rt *Router) Handle(pattern string, h http.Handler) {
+	rt.mux.Handle(pattern, h)
+	rt.patterns = append(rt.patterns, pattern)
+	if d, ok := h.(Documented); ok {
+		rt.routes = append(rt.routes, d.Route())
+		rt.public[pattern] = d.Route().Public
+	}
+}
+
+// Patterns returns every registered pattern
+func (rt *Router) Patterns() []string {
+	return slices.Clone(rt.patterns)
+}
+
+// Routes returns routes of the documented handlers
+func (rt *Router) Routes() []Route {
+	return slices.Clone(rt.routes)
+}
+
+// HandleRoutes registers routes with paths under prefix. Requests are routed by the method to Handler
+// of the operation, methods without an operation get http.StatusMethodNotAllowed
+func (rt *Router) HandleRoutes(prefix string, routes ...Route) {
+	for _, val := range routes {
+		val.Path = prefix + val.Path
+		rt.mux.Handle(val.Path, rt.dispatch(val))
+		rt.patterns = append(rt.patterns, val.Path)
+		rt.routes = append(rt.routes, val)
+		rt.public[val.Path] = val.Public
+	}
+}
+
+// IsPublic reports whether r is routed to a public route. Requests matching no route are not public
+func (rt *Router) IsPublic(r *http.Request) bool {
+	_, pattern := rt.mux.Handler(r)
+	return rt.public[pattern]
+}
+
+// dispatch returns handler selecting the operation of route by the request method
+func (rt *Router) dispatch(route Route) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := lg.FromContext(r.Context(), rt.log)
+
+		i := slices.IndexFunc(route.Operations, func(op Operation) bool { return op.Method == r.Method })
+		if i < 0 {
+			MethodNotAllowed(w, r, log, route.Methods())
+			return
+		}
+		op := route.Operations[i]
+
+		var errs []error
+		for _, val := range op.Path {
+			if err := validateParam(val, []string{r.PathValue(val.Name)}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		errs = append(errs, op.Query.Validate(r.URL.Query()))
+		if err := errors.Join(errs...); err != nil {
+			BadRequest(w, r, log, err)
+			return
+		}
+
+		op.Handler(r.Context(), w, r, log)
+	})
+}
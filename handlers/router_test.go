@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleRoutes(t *testing.T) {
+	type routeTest struct {
+		Method  string
+		Url     string
+		Status  int
+		Result  string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var log = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var echo = func(ctx context.Context, w http.ResponseWriter, r *http.Request, l *slog.Logger) {
+		fmt.Fprintf(w, "%s %d", r.Method, PathInt(r, "id"))
+	}
+
+	mux := http.NewServeMux()
+	rt := NewRouter(mux, log)
+	rt.HandleRoutes("/v9", Route{
+		Path: "/items/{id}",
+		Operations: []Operation{
+			{Method: http.MethodGet, Path: PathId, Query: QuerySchema{Params: []Param{{Name: "full", Type: ParamInt}}}, Handler: echo},
+			{Method: http.MethodDelete, Path: PathId, Handler: echo},
+		},
+	})
+
+	var arr = []routeTest{
+		{Method: http.MethodGet, Url: "/v9/items/7", Status: http.StatusOK, Result: "GET 7", Message: "positive test [GET] failed", Crit: true},
+		{Method: http.MethodDelete, Url: "/v9/items/7", Status: http.StatusOK, Result: "DELETE 7", Message: "positive test [DELETE] failed", Crit: true},
+		{Method: http.MethodGet, Url: "/v9/items/7?full=1", Status: http.StatusOK, Result: "GET 7", Message: "positive test [declared query] failed", Crit: true},
+		{Method: http.MethodPut, Url: "/v9/items/7", Status: http.StatusMethodNotAllowed, Result: api.CodeMethodNotAllowed, Message: "negative test [undeclared method] failed", Crit: true},
+		{Method: http.MethodHead, Url: "/v9/items/7", Status: http.StatusMethodNotAllowed, Message: "negative test [HEAD] failed", Crit: true},
+		{Method: http.MethodGet, Url: "/v9/items/x", Status: http.StatusBadRequest, Result: "'id' failed to convert [x] to an integer", Message: "negative test [malformed path value] failed", Crit: true},
+		{Method: http.MethodGet, Url: "/v9/items/-1", Status: http.StatusBadRequest, Result: "out of range", Message: "negative test [negative path value] failed", Crit: true},
+		{Method: http.MethodGet, Url: "/v9/items/7?full=x", Status: http.StatusBadRequest, Result: "'full' failed to convert", Message: "negative test [malformed query] failed", Crit: true},
+		{Method: http.MethodDelete, Url: "/v9/items/7?full=1", Status: http.StatusBadRequest, Result: "'full' unknown parameter", Message: "negative test [undeclared query] failed", Crit: true},
+		{Method: http.MethodGet, Url: "/items/7", Status: http.StatusNotFound, Message: "negative test [no prefix] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(val.Method, val.Url, nil))
+		if w.Code != val.Status || !strings.Contains(w.Body.String(), val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+	if allow := "GET, DELETE"; len(rt.Routes()) != 1 || strings.Join(rt.Routes()[0].Methods(), ", ") != allow || rt.Patterns()[0] != "/v9/items/{id}" {
+		fail = true
+		t.Logf("crit: true; positive test [collected route] failed; got %v %v", rt.Routes(), rt.Patterns())
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
@@ -0,0 +1,86 @@
+// Package routes wires every handler of the service to its url
+package routes
+
+import (
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/handlers/Animal"
+	"mis-catanddog/handlers/AnimalType"
+	"mis-catanddog/handlers/Audit"
+	"mis-catanddog/handlers/DocType"
+	"mis-catanddog/handlers/Human"
+	"mis-catanddog/policy"
+	"mis-catanddog/repos"
+	"net/http"
+)
+
+// guard puts the policy between handlers and the controllers of app
+func guard(app *handlers.App, db repos.DB) error {
+	roles, ok := db.(controllers.RoleRepo)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [RoleRepo] interface")
+	}
+	p, err := policy.New(roles)
+	if err != nil {
+		return err
+	}
+	app.DocTypeGetter = p.DocTypeGetter(app.DocTypeGetter)
+	app.DocTypeWriter = p.DocTypeWriter(app.DocTypeWriter)
+	app.AnimalTypeGetter = p.AnimalTypeGetter(app.AnimalTypeGetter)
+	app.AnimalTypeWriter = p.AnimalTypeWriter(app.AnimalTypeWriter)
+	app.HumanRepo = p.HumanRepo(app.HumanRepo)
+	app.AnimalRepo = p.AnimalRepo(app.AnimalRepo)
+	app.AuditReader = p.AuditReader(app.AuditReader)
+	return nil
+}
+
+// Register builds handlers with their dependencies guarded by the policy and registers them on mux. Query-style urls
+// of the first release stay along with the versioned resource API.
+// Probes of health are served at the root. Returns router that has collected routes of the registered handlers
+func Register(mux *http.ServeMux, db repos.DB, health *handlers.HealthHandler, l *slog.Logger) (*handlers.Router, error) {
+	app, err := handlers.NewApp(db, l)
+	if err != nil {
+		return nil, err
+	}
+	if err := guard(app, db); err != nil {
+		return nil, err
+	}
+
+	docType, err := DocType.New(app)
+	if err != nil {
+		return nil, err
+	}
+	animalType, err := AnimalType.New(app)
+	if err != nil {
+		return nil, err
+	}
+	human, err := Human.New(app)
+	if err != nil {
+		return nil, err
+	}
+	animal, err := Animal.New(app)
+	if err != nil {
+		return nil, err
+	}
+
+	audit, err := Audit.New(app)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := handlers.NewRouter(mux, l)
+	rt.Handle("/doc_type", docType)
+	rt.Handle("/animal_type", animalType)
+	rt.Handle("/human", human)
+	rt.Handle("/animal", animal)
+	rt.HandleRoutes(handlers.V1, docType.V1Routes()...)
+	rt.HandleRoutes(handlers.V1, animalType.V1Routes()...)
+	rt.HandleRoutes(handlers.V1, human.V1Routes()...)
+	rt.HandleRoutes(handlers.V1, animal.V1Routes()...)
+	rt.HandleRoutes(handlers.V1, audit.V1Routes()...)
+	rt.HandleRoutes("", health.Routes()...)
+	rt.Handle(handlers.OpenAPIPath, handlers.NewOpenAPIHandler(rt, l))
+	return rt, nil
+}
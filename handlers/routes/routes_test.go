@@ -0,0 +1,432 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/api"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"mis-catanddog/handlers"
+	"mis-catanddog/repos/migrate"
+	"mis-catanddog/repos/sqlite3"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSubject is the api key name granted the admin role by testServer
+const testSubject = "routes test"
+
+// testRouter registers handlers backed by a migrated sqlite db. Requests run on behalf of testSubject
+func testRouter(t *testing.T) (http.Handler, *handlers.Router) {
+	mux, rt, _, _ := testServer(t)
+	return as(mux, testSubject), rt
+}
+
+// as runs requests to h on behalf of the api key named name, the way WithAuth does for authenticated callers
+func as(h http.Handler, name string) http.Handler {
+	var subject = auth.APIKeySubject(name)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := auth.WithPrincipal(r.Context(), auth.Principal{Subject: subject, Method: auth.MethodAPIKey})
+		h.ServeHTTP(w, r.WithContext(controllers.WithActor(ctx, controllers.Actor{Subject: subject})))
+	})
+}
+
+// testServer registers handlers backed by a migrated sqlite db and returns the db and the probes as well.
+// Requests to the returned mux are anonymous
+func testServer(t *testing.T) (*http.ServeMux, *handlers.Router, *sqlite3.SqLiteDB, *handlers.HealthHandler) {
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var db = &sqlite3.SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	t.Cleanup(db.Close)
+	if _, err := migrate.Up(context.Background(), db, l); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+	if err := db.RoleGrant(context.Background(), auth.APIKeySubject(testSubject), "admin", l); err != nil {
+		t.Fatalf("failed to grant role: %s", err.Error())
+	}
+
+	health, err := handlers.NewHealthHandler(db, "sqlite", time.Second, l)
+	if err != nil {
+		t.Fatalf("failed to create probes: %s", err.Error())
+	}
+	mux := http.NewServeMux()
+	rt, err := Register(mux, db, health, l)
+	if err != nil {
+		t.Fatalf("failed to register handlers: %s", err.Error())
+	}
+	return mux, rt, db, health
+}
+
+// patternPath strips the method and host of a ServeMux pattern, leaving the path the spec is keyed by
+func patternPath(pattern string) string {
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	return pattern[strings.Index(pattern, "/"):]
+}
+
+// TestOpenAPICoverage fails in case a route is registered without a spec entry
+func TestOpenAPICoverage(t *testing.T) {
+	var doc struct {
+		OpenAPI string
+		Paths   map[string]map[string]any
+	}
+	mux, rt := testRouter(t)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, handlers.OpenAPIPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: got status %d", handlers.OpenAPIPath, w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("%s: malformed document: %s", handlers.OpenAPIPath, err.Error())
+	}
+
+	for _, pattern := range rt.Patterns() {
+		if _, ok := doc.Paths[patternPath(pattern)]; !ok {
+			t.Errorf("pattern [%s] is registered without a spec entry", pattern)
+		}
+	}
+	if len(rt.Routes()) != len(rt.Patterns()) {
+		t.Errorf("every registered handler must be documented; got %d routes of %d patterns", len(rt.Routes()), len(rt.Patterns()))
+	}
+}
+
+// wildcard matches path wildcards of ServeMux patterns
+var wildcard = regexp.MustCompile(`\{[^}]*\}`)
+
+// TestOpenAPIMethods checks handlers serve exactly the methods of their spec entries
+func TestOpenAPIMethods(t *testing.T) {
+	var methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	mux, rt := testRouter(t)
+
+	for _, route := range rt.Routes() {
+		documented := route.Methods()
+		path := wildcard.ReplaceAllString(route.Path, "1")
+		for _, method := range methods {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			switch {
+			case slices.Contains(documented, method) && w.Code == http.StatusMethodNotAllowed:
+				t.Errorf("%s %s: documented method is not allowed", method, route.Path)
+			case !slices.Contains(documented, method) && w.Code != http.StatusMethodNotAllowed:
+				t.Errorf("%s %s: undocumented method got status %d", method, route.Path, w.Code)
+			case w.Code == http.StatusMethodNotAllowed && w.Header().Get("Allow") != strings.Join(documented, ", "):
+				t.Errorf("%s %s: got Allow [%s]; expected [%s]", method, route.Path, w.Header().Get("Allow"), strings.Join(documented, ", "))
+			}
+		}
+	}
+}
+
+func TestV1(t *testing.T) {
+	type v1Test struct {
+		Method  string
+		Path    string
+		Body    string
+		Status  int
+		Result  string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	mux, _ := testRouter(t)
+
+	// requests run in order, later ones rely on records created by the earlier ones
+	var arr = []v1Test{
+		{Method: http.MethodPost, Path: "/api/v1/doc-types", Body: `{"Doc":"passport"}`, Status: http.StatusCreated, Result: `"Id":1`, Message: "positive test [create doc type] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types/1", Status: http.StatusOK, Result: `{"Id":1,"Doc":"passport"}`, Message: "positive test [get doc type] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types/2", Status: http.StatusNotFound, Result: `"code":"not_found"`, Message: "negative test [missing doc type] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types/abc", Status: http.StatusBadRequest, Result: `"field":"id"`, Message: "negative test [malformed id] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types/0", Status: http.StatusBadRequest, Result: `out of range`, Message: "negative test [zero id] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types/1?doc=passport", Status: http.StatusBadRequest, Result: `unknown parameter`, Message: "negative test [query lookup on item] failed", Crit: true},
+		{Method: http.MethodPatch, Path: "/api/v1/doc-types/1", Body: `{"Doc":"id card"}`, Status: http.StatusOK, Result: `"Doc":"id card"`, Message: "positive test [patch doc type] failed", Crit: true},
+		{Method: http.MethodPut, Path: "/api/v1/doc-types/1", Body: `{"Id":2,"Doc":"passport"}`, Status: http.StatusBadRequest, Result: `differs from the addressed id`, Message: "negative test [put with other id] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/doc-types?doc[like]=id*", Status: http.StatusOK, Result: `"Items":[{"Id":1,"Doc":"id card"}]`, Message: "positive test [list doc types] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/doc_type?id=1", Status: http.StatusOK, Result: `[{"Id":1,"Doc":"id card"}]`, Message: "positive test [query lookup stays] failed", Crit: true},
+		{Method: http.MethodPost, Path: "/api/v1/animal-types", Body: `{"Type":"dog"}`, Status: http.StatusCreated, Message: "positive test [create animal type] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/animal-types/5", Status: http.StatusNotFound, Message: "negative test [missing animal type] failed", Crit: true},
+		{Method: http.MethodPost, Path: "/api/v1/humans", Body: `{"DocId":10,"DocType":1,"FirstName":"Ann","LastName":"Lee","BirthDate":"1990-01-02"}`, Status: http.StatusCreated, Message: "positive test [create human] failed", Crit: true},
+		{Method: http.MethodPost, Path: "/api/v1/animals", Body: `{"DocId":20,"DocType":1,"Name":"Rex","BirthDate":"2020-03-04","AnimalType":1,"Breed":"husky","OwnerDocId":10}`, Status: http.StatusCreated, Message: "positive test [create animal] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/animals/20?expand=owner", Status: http.StatusOK, Result: `"Owner":{"DocId":10`, Message: "positive test [get animal expanded] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/animals/20/owner", Status: http.StatusOK, Result: `{"DocId":10,"DocType":1,"FirstName":"Ann"`, Message: "positive test [owner of animal] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/humans/10/animals", Status: http.StatusOK, Result: `[{"DocId":20`, Message: "positive test [animals of human] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/humans/11/animals", Status: http.StatusNotFound, Message: "negative test [animals of missing human] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/animals?name[eq]=Rex&expand=type", Status: http.StatusOK, Result: `"Type":{"Id":1,"Type":"dog"`, Message: "positive test [list animals expanded] failed", Crit: true},
+		{Method: http.MethodDelete, Path: "/api/v1/animals/20", Status: http.StatusNoContent, Message: "positive test [delete animal] failed", Crit: true},
+		{Method: http.MethodGet, Path: "/api/v1/animals/20/owner", Status: http.StatusNotFound, Message: "negative test [owner of deleted animal] failed", Crit: true},
+		{Method: http.MethodPost, Path: "/api/v1/humans/10", Status: http.StatusMethodNotAllowed, Result: `"code":"method_not_allowed"`, Message: "negative test [post to item] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		r := httptest.NewRequest(val.Method, val.Path, strings.NewReader(val.Body))
+		if val.Body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != val.Status || !strings.Contains(w.Body.String(), val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	type healthTest struct {
+		Path    string
+		Prepare func()
+		Status  int
+		Result  string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	mux, _, db, health := testServer(t)
+
+	// cases run in order, each one breaks readiness a bit more
+	var arr = []healthTest{
+		{Path: handlers.HealthzPath, Status: http.StatusOK, Result: `"status":"ok","backend":"sqlite"`, Message: "positive test [alive] failed", Crit: true},
+		{Path: handlers.ReadyzPath, Status: http.StatusOK, Result: `"db_version":"3.`, Message: "positive test [ready] failed", Crit: true},
+		{
+			Path:    handlers.ReadyzPath,
+			Prepare: func() { migrate.Down(context.Background(), db, l) },
+			Status:  http.StatusServiceUnavailable,
+			Result:  `"detail":"schema_not_ready"`,
+			Message: "negative test [pending migrations] failed",
+			Crit:    true,
+		},
+		{
+			Path:    handlers.ReadyzPath,
+			Prepare: func() { migrate.Up(context.Background(), db, l); health.Drain() },
+			Status:  http.StatusServiceUnavailable,
+			Result:  `"detail":"draining"`,
+			Message: "negative test [draining] failed",
+			Crit:    true,
+		},
+		{Path: handlers.HealthzPath, Status: http.StatusOK, Message: "positive test [alive while draining] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		if val.Prepare != nil {
+			val.Prepare()
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, val.Path, nil))
+		if w.Code != val.Status || !strings.Contains(w.Body.String(), val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+// TestAuth checks every route but the probes and the API description requires credentials
+func TestAuth(t *testing.T) {
+	var public = []string{handlers.HealthzPath, handlers.ReadyzPath, handlers.OpenAPIPath}
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	mux, rt, db, _ := testServer(t)
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate api key: %s", err.Error())
+	}
+	if _, err := db.APIKeyCreate(context.Background(), controllers.APIKey{Name: testSubject, Hash: hash}, l); err != nil {
+		t.Fatalf("failed to create api key: %s", err.Error())
+	}
+	authn, err := auth.New(db, nil)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %s", err.Error())
+	}
+	h := handlers.Chain(mux, handlers.WithAuth(authn, rt.IsPublic, l))
+
+	for _, route := range rt.Routes() {
+		path := wildcard.ReplaceAllString(route.Path, "1")
+		if route.Public != slices.Contains(public, route.Path) {
+			t.Errorf("%s: got public %t", route.Path, route.Public)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if !route.Public && w.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s: anonymous request got status %d", route.Path, w.Code)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set(api.APIKeyHeader, key)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code == http.StatusUnauthorized {
+			t.Errorf("GET %s: request with api key got status %d", route.Path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/no/such/route", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous request of an unknown route got status %d", w.Code)
+	}
+}
+
+// TestRBAC checks roles seeded by migrations get the rights clinic staff expect
+func TestRBAC(t *testing.T) {
+	type rbacTest struct {
+		Subject string
+		Method  string
+		Path    string
+		Body    string
+		Status  int
+		Result  string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	mux, _, db, _ := testServer(t)
+	for subject, role := range map[string]string{"desk": "receptionist", "doc": "vet"} {
+		if err := db.RoleGrant(context.Background(), auth.APIKeySubject(subject), role, l); err != nil {
+			t.Fatalf("failed to grant role: %s", err.Error())
+		}
+	}
+
+	// requests run in order, later ones rely on records created by the earlier ones
+	var arr = []rbacTest{
+		{Subject: testSubject, Method: http.MethodPost, Path: "/api/v1/doc-types", Body: `{"Doc":"passport"}`, Status: http.StatusCreated, Message: "positive test [admin writes dictionary] failed", Crit: true},
+		{Subject: testSubject, Method: http.MethodPost, Path: "/api/v1/animal-types", Body: `{"Type":"dog"}`, Status: http.StatusCreated, Message: "positive test [admin writes animal types] failed", Crit: true},
+		{Subject: "desk", Method: http.MethodPost, Path: "/api/v1/doc-types", Body: `{"Doc":"id card"}`, Status: http.StatusForbidden, Result: `"code":"forbidden"`, Message: "negative test [receptionist writes dictionary] failed", Crit: true},
+		{Subject: "doc", Method: http.MethodDelete, Path: "/api/v1/animal-types/1", Status: http.StatusForbidden, Result: `"code":"forbidden"`, Message: "negative test [vet writes animal types] failed", Crit: true},
+		{Subject: "desk", Method: http.MethodGet, Path: "/api/v1/doc-types/1", Status: http.StatusOK, Message: "positive test [receptionist reads dictionary] failed", Crit: true},
+		{Subject: "desk", Method: http.MethodPost, Path: "/api/v1/humans", Body: `{"DocId":10,"DocType":1,"FirstName":"Ann","LastName":"Lee","BirthDate":"1990-01-02"}`, Status: http.StatusCreated, Message: "positive test [receptionist registers human] failed", Crit: true},
+		{Subject: "doc", Method: http.MethodPut, Path: "/api/v1/humans/10", Body: `{"DocId":10,"DocType":1,"FirstName":"Ann","LastName":"Li","BirthDate":"1990-01-02"}`, Status: http.StatusForbidden, Message: "negative test [vet edits human] failed", Crit: true},
+		{Subject: "doc", Method: http.MethodPost, Path: "/api/v1/animals", Body: `{"DocId":20,"DocType":1,"Name":"Rex","BirthDate":"2020-03-04","AnimalType":1,"Breed":"husky","OwnerDocId":10}`, Status: http.StatusCreated, Message: "positive test [vet writes animal] failed", Crit: true},
+		{Subject: "doc", Method: http.MethodGet, Path: "/api/v1/animals/20?expand=owner", Status: http.StatusOK, Result: `"Owner":{"DocId":10`, Message: "positive test [vet reads owner] failed", Crit: true},
+		{Subject: "stranger", Method: http.MethodGet, Path: "/api/v1/humans/10", Status: http.StatusForbidden, Message: "negative test [subject without roles] failed", Crit: true},
+		{Subject: "stranger", Method: http.MethodGet, Path: "/animal_type?id=1", Status: http.StatusForbidden, Result: `"code":"forbidden"`, Message: "negative test [legacy animal type lookup] failed", Crit: true},
+		{Subject: "desk", Method: http.MethodGet, Path: "/human?last_name=Lee", Status: http.StatusOK, Result: `"LastName":"Lee"`, Message: "positive test [legacy human search] failed", Crit: true},
+		{Subject: "stranger", Method: http.MethodGet, Path: "/human?last_name=Lee", Status: http.StatusForbidden, Result: `"code":"forbidden"`, Message: "negative test [legacy human search] failed", Crit: true},
+		{Subject: "doc", Method: http.MethodGet, Path: "/animal?owner_doc_id=10", Status: http.StatusOK, Result: `"Name":"Rex"`, Message: "positive test [legacy animals of owner] failed", Crit: true},
+		{Subject: "stranger", Method: http.MethodGet, Path: "/animal?owner_doc_id=10", Status: http.StatusForbidden, Result: `"code":"forbidden"`, Message: "negative test [legacy animals of owner] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		r := httptest.NewRequest(val.Method, val.Path, strings.NewReader(val.Body))
+		if val.Body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		as(mux, val.Subject).ServeHTTP(w, r)
+		if w.Code != val.Status || !strings.Contains(w.Body.String(), val.Result) {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestAudit(t *testing.T) {
+	type auditTest struct {
+		Subject string
+		Method  string
+		Path    string
+		Body    string
+		Status  int
+		Result  []string
+		Message string
+		Crit    bool
+	}
+
+	var fail bool
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	mux, rt, db, _ := testServer(t)
+	authn, err := auth.New(db, nil)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %s", err.Error())
+	}
+	if _, err := db.DocTypeCreate(context.Background(), controllers.DocType{Id: 1, Doc: "passport"}, l); err != nil {
+		t.Fatalf("failed to create doc type: %s", err.Error())
+	}
+	var keys = map[string]string{}
+	for subject, role := range map[string]string{"registrar": "receptionist", "inspector": "auditor"} {
+		key, hash, err := auth.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %s", err.Error())
+		}
+		if _, err := db.APIKeyCreate(context.Background(), controllers.APIKey{Name: subject, Hash: hash}, l); err != nil {
+			t.Fatalf("failed to create key: %s", err.Error())
+		}
+		if err := db.RoleGrant(context.Background(), auth.APIKeySubject(subject), role, l); err != nil {
+			t.Fatalf("failed to grant role: %s", err.Error())
+		}
+		keys[subject] = key
+	}
+	// actor and request id reach the repos through the middleware
+	var h = handlers.Chain(mux, handlers.WithRequestId(l), handlers.WithAuth(authn, rt.IsPublic, l))
+
+	// requests run in order, the audit is read after the changes
+	var arr = []auditTest{
+		{Subject: "registrar", Method: http.MethodPost, Path: "/api/v1/humans", Body: `{"DocId":10,"DocType":1,"FirstName":"Ann","LastName":"Lee","BirthDate":"1990-01-02"}`, Status: http.StatusCreated, Message: "positive test [create human] failed", Crit: true},
+		{Subject: "registrar", Method: http.MethodPatch, Path: "/api/v1/humans/10", Body: `{"LastName":"Li"}`, Status: http.StatusOK, Message: "positive test [update human] failed", Crit: true},
+		{Subject: "registrar", Method: http.MethodGet, Path: "/api/v1/audit", Status: http.StatusForbidden, Message: "negative test [receptionist reads audit] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodGet, Path: "/api/v1/audit?entity[eq]=human&entity_id[eq]=10&sort=-id", Status: http.StatusOK, Result: []string{`"Action":"update","Before":{"LastName":"Lee"},"After":{"LastName":"Li"}`, `"Actor":"apikey:registrar","RequestId":"audit-2"`, `"Action":"create","Before":null`}, Message: "positive test [history of human] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodGet, Path: "/api/v1/audit?request_id[eq]=audit-1", Status: http.StatusOK, Result: []string{`"Items":[{"Id"`, `"Action":"create"`}, Message: "positive test [changes of request] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodGet, Path: "/api/v1/audit?actor[eq]=apikey:inspector", Status: http.StatusOK, Result: []string{`"Items":[]`}, Message: "positive test [reads are not audited] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodGet, Path: "/api/v1/audit?date[gte]=yesterday", Status: http.StatusBadRequest, Message: "negative test [malformed date] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodGet, Path: "/api/v1/audit?before[eq]=x", Status: http.StatusBadRequest, Message: "negative test [unknown field] failed", Crit: true},
+		{Subject: "inspector", Method: http.MethodDelete, Path: "/api/v1/audit", Status: http.StatusMethodNotAllowed, Message: "negative test [delete audit] failed", Crit: true},
+	}
+
+	for i, val := range arr {
+		r := httptest.NewRequest(val.Method, val.Path, strings.NewReader(val.Body))
+		if val.Body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		r.Header.Set(api.APIKeyHeader, keys[val.Subject])
+		r.Header.Set(api.RequestIdHeader, fmt.Sprintf("audit-%d", i+1))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		ok := w.Code == val.Status
+		for _, res := range val.Result {
+			ok = ok && strings.Contains(w.Body.String(), res)
+		}
+		if !ok {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %d %s", val.Crit, val.Message, w.Code, w.Body.String())
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
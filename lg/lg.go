@@ -27,3 +27,8 @@ func Init(format, level string) (*slog.Logger, error) {
 		return nil, fmt.Errorf("unexpecteed log format %s. Expected: text, json", format)
 	}
 }
+
+// Flush commits logs written to stderr. Errors are ignored since stderr is often a pipe that can't be synced
+func Flush() {
+	_ = os.Stderr.Sync()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/auth"
+	"mis-catanddog/config"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"os"
+	"os/user"
+	"slices"
+	"time"
+)
+
+// cliContext returns context of the subcommands, their changes are audited as made by the OS user
+func cliContext() context.Context {
+	var subject = "cli"
+	if u, err := user.Current(); err == nil {
+		subject += ":" + u.Username
+	}
+	return controllers.WithActor(context.Background(), controllers.Actor{Subject: subject})
+}
+
+// getAPIKeyCmd returns subcommand and key name following the apikey arg. ok is false if apikey arg is not presented
+func getAPIKeyCmd() (cmd, name string, ok bool, err error) {
+	i := slices.Index(os.Args, "apikey")
+	if i == -1 {
+		return "", "", false, nil
+	}
+	// i+3 means both subcommand and name are ahead of apikey arg
+	if len(os.Args) < i+3 || !slices.Contains([]string{"create", "revoke"}, os.Args[i+1]) {
+		return "", "", true, fmt.Errorf("apikey expects one of: create <name>, revoke <name>")
+	}
+	return os.Args[i+1], os.Args[i+2], true, nil
+}
+
+// runAPIKey executes apikey subcommand against db. The created key is printed once and never stored
+func runAPIKey(ctx context.Context, db repos.DB, cmd, name string, l *slog.Logger) error {
+	keys, ok := db.(controllers.APIKeyRepo)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [APIKeyRepo] interface")
+	}
+
+	switch cmd {
+	case "create":
+		key, hash, err := auth.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if _, err := keys.APIKeyCreate(ctx, controllers.APIKey{Name: name, Hash: hash}, l); err != nil {
+			return err
+		}
+		fmt.Printf("api key %s created, roles are granted to %s. Store it now, it is not shown again:\n%s\n", name, auth.APIKeySubject(name), key)
+	case "revoke":
+		if err := keys.APIKeyRevoke(ctx, name, l); err != nil {
+			return err
+		}
+		fmt.Printf("api key %s revoked\n", name)
+	}
+	return nil
+}
+
+// getRoleCmd returns subcommand, subject and role following the role arg. ok is false if role arg is not presented
+func getRoleCmd() (cmd, subject, role string, ok bool, err error) {
+	i := slices.Index(os.Args, "role")
+	if i == -1 {
+		return "", "", "", false, nil
+	}
+	// i+4 means subcommand, subject and role are ahead of role arg
+	if len(os.Args) < i+4 || !slices.Contains([]string{"grant", "revoke"}, os.Args[i+1]) {
+		return "", "", "", true, fmt.Errorf("role expects one of: grant <subject> <role>, revoke <subject> <role>")
+	}
+	if err := auth.ValidSubject(os.Args[i+2]); err != nil {
+		return "", "", "", true, err
+	}
+	return os.Args[i+1], os.Args[i+2], os.Args[i+3], true, nil
+}
+
+// runRole executes role subcommand against db. Subject is apikey:<name> for api keys or jwt:<iss>/<sub> for tokens
+func runRole(ctx context.Context, db repos.DB, cmd, subject, role string, l *slog.Logger) error {
+	roles, ok := db.(controllers.RoleRepo)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [RoleRepo] interface")
+	}
+
+	switch cmd {
+	case "grant":
+		if err := roles.RoleGrant(ctx, subject, role, l); err != nil {
+			return err
+		}
+		fmt.Printf("role %s granted to %s\n", role, subject)
+	case "revoke":
+		if err := roles.RoleRevoke(ctx, subject, role, l); err != nil {
+			return err
+		}
+		fmt.Printf("role %s revoked from %s\n", role, subject)
+	}
+	return nil
+}
+
+// initAuth builds authenticator of API keys stored in db and bearer tokens configured in cfg
+func initAuth(cfg config.Config, db repos.DB) (*auth.Authenticator, error) {
+	keys, ok := db.(controllers.APIKeyRepo)
+	if !ok {
+		return nil, fmt.Errorf("object of type [DB] interface failed to covert to [APIKeyRepo] interface")
+	}
+	jwt := cfg.Auth.JWT
+	if jwt.Alg == "" {
+		return auth.New(keys, nil)
+	}
+
+	var jc = auth.JWTConfig{
+		Alg:      jwt.Alg,
+		Secret:   []byte(jwt.Secret),
+		Issuer:   jwt.Issuer,
+		Audience: jwt.Audience,
+		Leeway:   time.Duration(jwt.Leeway) * time.Millisecond,
+	}
+	if jwt.PublicKey != "" {
+		data, err := os.ReadFile(jwt.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt public key: %w", err)
+		}
+		if jc.PublicKey, err = auth.ParseRSAPublicKey(data); err != nil {
+			return nil, err
+		}
+	}
+	verifier, err := auth.NewJWTVerifier(jc)
+	if err != nil {
+		return nil, err
+	}
+	return auth.New(keys, verifier)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/repos"
+	"os"
+	"slices"
+	"text/tabwriter"
+)
+
+// getCheckCmd returns subcommand following the check arg. ok is false if check arg is not presented
+func getCheckCmd() (cmd string, ok bool, err error) {
+	i := slices.Index(os.Args, "check")
+	if i == -1 {
+		return "", false, nil
+	}
+	// i+2 means something ahead of check arg exists
+	if len(os.Args) < i+2 || !slices.Contains([]string{"report", "repair"}, os.Args[i+1]) {
+		return "", true, fmt.Errorf("check expects one of: report, repair")
+	}
+	return os.Args[i+1], true, nil
+}
+
+// runCheck executes check subcommand against db. report fails in case any issue is found,
+// repair fails in case some issues are left for a manual fix. report runs against any schema version,
+// repair needs the migrated schema since its fixes are audited. Migrations do not fail on broken references
+// found in the db, so a legacy db is migrated first and repaired then
+func runCheck(ctx context.Context, db repos.DB, cmd string, l *slog.Logger) error {
+	checker, ok := db.(repos.Checker)
+	if !ok {
+		// the other backends enforce references and column types themselves
+		fmt.Println("nothing to check, the db enforces references and column types")
+		return nil
+	}
+
+	if cmd == "repair" {
+		if err := checkMigrations(ctx, db); err != nil {
+			return err
+		}
+		repaired, err := checker.RepairConsistency(ctx, l)
+		printIssues(repaired)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("repaired %d issues\n", len(repaired))
+	}
+
+	issues, err := checker.CheckConsistency(ctx, l)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Println("no issues found")
+		return nil
+	}
+	printIssues(issues)
+	return fmt.Errorf("%d issues found", len(issues))
+}
+
+// printIssues writes issues as a table, issues without a repair need a manual fix
+func printIssues(issues []repos.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PROBLEM\tENTITY\tDOC_ID\tCOLUMN\tVALUE\tREPAIR")
+	for _, val := range issues {
+		repair := val.Repair
+		if repair == "" {
+			repair = "manual"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%q\t%s\n", val.Problem, val.Entity, val.EntityId, val.Column, val.Value, repair)
+	}
+	w.Flush()
+}
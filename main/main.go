@@ -6,19 +6,37 @@ import (
 	"log"
 	"log/slog"
 	"mis-catanddog/config"
-	"mis-catanddog/handlers/DocType"
+	"mis-catanddog/handlers"
+	"mis-catanddog/handlers/routes"
 	"mis-catanddog/lg"
 	"mis-catanddog/repos"
+	"mis-catanddog/repos/pgsql"
 	"mis-catanddog/repos/sqlite3"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// exit codes
+const (
+	exitOk              = 0
+	exitFailure         = 1 // startup or server failure
+	exitUsage           = 2 // bad command line
+	exitShutdownTimeout = 3 // in-flight requests did not finish within the grace period
+)
+
 func main() {
+	os.Exit(run())
+}
+
+// run starts the app and returns exit code. Deferred cleanups run before the process exits
+func run() int {
 	var db repos.DB
 	var cfg config.Config
 	var logg *slog.Logger
@@ -27,62 +45,223 @@ func main() {
 	// read config
 	path, err := config.GetConfPath()
 	if err != nil {
-		log.Fatal(fmt.Errorf("config path error: %w", err))
+		log.Print(fmt.Errorf("config path error: %w", err))
+		return exitUsage
 	}
 	if err := cfg.New(path); err != nil {
-		log.Fatal(fmt.Errorf("reading config file: %w", err))
+		log.Print(fmt.Errorf("reading config file: %w", err))
+		return exitFailure
 	}
 
 	// init logger
 	logg, err = lg.Init(cfg.Log.Format, cfg.Log.Level)
 	if err != nil {
-		log.Fatal(fmt.Errorf("logger init error: %w", err))
+		log.Print(fmt.Errorf("logger init error: %w", err))
+		return exitFailure
 	}
+	defer lg.Flush()
 
-	logg.Debug(fmt.Sprintf("Config: %+v", cfg))
+	logg.Debug("config read", "config", cfg)
 
 	// create DB connection
 	db = initRepo(cfg, logg)
 	if db == nil {
-		os.Exit(1)
+		return exitFailure
+	}
+	defer func() {
+		db.Close()
+		logg.Info("db connection closed")
+	}()
+
+	// migrate subcommand runs instead of the server
+	cmd, ok, err := getMigrateCmd()
+	if err != nil {
+		logg.Error(err.Error())
+		return exitUsage
+	}
+	if ok {
+		if err := runMigrate(context.Background(), db, cmd, logg); err != nil {
+			logg.Error(fmt.Errorf("migrate %s failed: %w", cmd, err).Error())
+			return exitFailure
+		}
+		return exitOk
+	}
+
+	// check subcommand runs instead of the server. It goes ahead of the migration gate and the db init:
+	// a legacy db may need the report before it is migrated
+	checkCmd, ok, err := getCheckCmd()
+	if err != nil {
+		logg.Error(err.Error())
+		return exitUsage
+	}
+	if ok {
+		if err := runCheck(cliContext(), db, checkCmd, logg); err != nil {
+			logg.Error(fmt.Errorf("check %s failed: %w", checkCmd, err).Error())
+			return exitFailure
+		}
+		return exitOk
+	}
+
+	if cfg.DB.InitDB {
+		if err := initDB(context.Background(), db, cfg.DB.Seed, logg); err != nil {
+			logg.Error(fmt.Errorf("db init failed: %w", err).Error())
+			return exitFailure
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DB.Timeout)*time.Millisecond)
+	err = checkMigrations(ctx, db)
+	cancel()
+	if err != nil {
+		logg.Error(err.Error())
+		return exitFailure
+	}
+
+	// apikey subcommand runs instead of the server, it needs the migrated schema
+	keyCmd, keyName, ok, err := getAPIKeyCmd()
+	if err != nil {
+		logg.Error(err.Error())
+		return exitUsage
+	}
+	if ok {
+		if err := runAPIKey(cliContext(), db, keyCmd, keyName, logg); err != nil {
+			logg.Error(fmt.Errorf("apikey %s failed: %w", keyCmd, err).Error())
+			return exitFailure
+		}
+		return exitOk
+	}
+
+	// role subcommand runs instead of the server
+	roleCmd, subject, role, ok, err := getRoleCmd()
+	if err != nil {
+		logg.Error(err.Error())
+		return exitUsage
+	}
+	if ok {
+		if err := runRole(cliContext(), db, roleCmd, subject, role, logg); err != nil {
+			logg.Error(fmt.Errorf("role %s failed: %w", roleCmd, err).Error())
+			return exitFailure
+		}
+		return exitOk
+	}
+
+	authn, err := initAuth(cfg, db)
+	if err != nil {
+		logg.Error(fmt.Errorf("auth init failed: %w", err).Error())
+		return exitFailure
 	}
-	defer db.Close()
 
 	// init server
+	health, err := handlers.NewHealthHandler(db, cfg.DB.Type, time.Duration(cfg.DB.Timeout)*time.Millisecond, logg)
+	if err != nil {
+		logg.Error(fmt.Errorf("handlers init failed: %w", err).Error())
+		return exitFailure
+	}
+	mux := http.NewServeMux()
+	rt, err := routes.Register(mux, db, health, logg)
+	if err != nil {
+		logg.Error(fmt.Errorf("handlers init failed: %w", err).Error())
+		return exitFailure
+	}
 	server := &http.Server{
 		Addr:           ":" + strconv.Itoa(cfg.Web.Port),
-		Handler:        nil,
+		Handler:        handlers.Chain(mux, handlers.WithRequestId(logg), handlers.WithAccessLog(logg), handlers.WithAuth(authn, rt.IsPublic, logg)),
 		ReadTimeout:    time.Duration(cfg.Web.Timeout) * time.Millisecond,
 		WriteTimeout:   time.Duration(cfg.Web.Timeout) * time.Millisecond,
 		IdleTimeout:    time.Duration(cfg.Web.IdleTimeout) * time.Millisecond,
 		MaxHeaderBytes: 1 << 20, // 1Mb
-		BaseContext: func(l net.Listener) context.Context {
-			// Cant find parent context in documentation
-			ctx := context.WithValue(context.TODO(), "db", db)
-			return context.WithValue(ctx, "logger", logg)
-		},
 	}
-	http.HandleFunc("/doc_type", DocType.DocType)
 
-	logg.Info("Starting server")
-	err = server.ListenAndServe()
-	if err != nil {
-		logg.Error(fmt.Errorf("web server failed: %w", err).Error())
-		os.Exit(1)
+	return serve(server, health, time.Duration(cfg.Web.ShutdownTimeout)*time.Millisecond, logg)
+}
+
+// serve runs server until it fails or SIGINT/SIGTERM is received. On signal it stops accepting
+// connections and waits up to grace for in-flight requests to finish. Readiness of health fails from the signal on
+func serve(server *http.Server, health *handlers.HealthHandler, grace time.Duration, l *slog.Logger) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var inflight sync.WaitGroup
+	cancel := track(server, &inflight)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		l.Info("Starting server", "addr", server.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		l.Error(fmt.Errorf("web server failed: %w", err).Error())
+		return exitFailure
+	case <-ctx.Done():
+		l.Info("shutdown signal received, draining in-flight requests", "grace", grace)
 	}
+	// second signal kills the process right away
+	stop()
+	health.Drain()
+
+	return shutdown(server, &inflight, cancel, grace, l)
+}
+
+// track counts requests of server in inflight. Contexts of the requests derive from the one the returned
+// cancel cancels, so requests outliving the shutdown grace period are told to stop
+func track(server *http.Server, inflight *sync.WaitGroup) context.CancelFunc {
+	base, cancel := context.WithCancel(context.Background())
+	next := server.Handler
+	server.BaseContext = func(net.Listener) context.Context { return base }
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inflight.Add(1)
+		defer inflight.Done()
+		next.ServeHTTP(w, r)
+	})
+	return cancel
+}
+
+// shutdown stops server waiting up to grace for in-flight requests. Requests left after grace are cancelled,
+// shutdown returns only when all of them are done: the db is closed right after, while they may still use it
+func shutdown(server *http.Server, inflight *sync.WaitGroup, cancel context.CancelFunc, grace time.Duration, l *slog.Logger) int {
+	var code = exitOk
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), grace)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		l.Error(fmt.Errorf("graceful shutdown failed: %w", err).Error())
+		cancel()
+		server.Close()
+		code = exitShutdownTimeout
+	}
+	inflight.Wait()
+	l.Info("server stopped")
+	return code
 }
 
 func initRepo(cfg config.Config, l *slog.Logger) repos.DB {
 	switch cfg.DB.Type {
 	case "sqlite":
-		var db repos.DB = &sqlite3.SqLiteDB{}
+		var db repos.DB = &sqlite3.SqLiteDB{Opts: sqlite3.Options{
+			JournalMode:     cfg.DB.SQLite.JournalMode,
+			Synchronous:     cfg.DB.SQLite.Synchronous,
+			BusyTimeout:     time.Duration(cfg.DB.SQLite.BusyTimeout) * time.Millisecond,
+			CacheSize:       cfg.DB.SQLite.CacheSize,
+			MaxOpenConns:    cfg.DB.SQLite.MaxOpenConns,
+			MaxIdleConns:    cfg.DB.SQLite.MaxIdleConns,
+			ConnMaxLifetime: time.Duration(cfg.DB.SQLite.ConnMaxLifetime) * time.Millisecond,
+		}}
 		u, err := url.ParseRequestURI(cfg.DB.Uri)
 		if err != nil {
 			l.Error(fmt.Errorf("failed to parce uri: %w", err).Error())
 			return nil
 		}
-		_, err = os.Stat(u.Opaque)
-		if err != nil {
+		// file:db.sqlite is opaque, file:/path/db.sqlite is not
+		path := u.Opaque
+		if path == "" {
+			path = u.Path
+		}
+		// sqlite creates missing file on connect, that is desired only when we are about to init it
+		_, err = os.Stat(path)
+		if err != nil && !cfg.DB.InitDB {
 			l.Error(fmt.Errorf("sqlite db file does not exist: %w", err).Error())
 			return nil
 		}
@@ -93,8 +272,13 @@ func initRepo(cfg config.Config, l *slog.Logger) repos.DB {
 		}
 		return db
 	case "pgsql":
-		l.Error("not yet implemented")
-		return nil
+		var db repos.DB = &pgsql.PgSqlDB{}
+		err := db.New(cfg.DB.Uri, time.Duration(cfg.DB.Timeout)*time.Millisecond)
+		if err != nil {
+			l.Error(fmt.Errorf("repos connection error: %w", err).Error())
+			return nil
+		}
+		return db
 	default:
 		l.Error("unexpected db type")
 		return nil
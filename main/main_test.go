@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShutdown checks requests outliving the grace period are cancelled and waited for, the db is closed after them
+func TestShutdown(t *testing.T) {
+	var l = slog.New(slog.NewTextHandler(io.Discard, nil))
+	var started = make(chan struct{})
+	var done atomic.Bool
+	var inflight sync.WaitGroup
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		// the request still uses the db for a while after it is cancelled
+		time.Sleep(50 * time.Millisecond)
+		done.Store(true)
+	})}
+	cancel := track(server, &inflight)
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err.Error())
+	}
+	go server.Serve(ln)
+	go http.Get("http://" + ln.Addr().String())
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("request did not reach the handler")
+	}
+
+	if code := shutdown(server, &inflight, cancel, 10*time.Millisecond, l); code != exitShutdownTimeout {
+		t.Fatalf("shutdown: got exit code %d, expected %d", code, exitShutdownTimeout)
+	}
+	if !done.Load() {
+		t.Fatalf("shutdown returned before the in-flight request finished")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/repos"
+	"mis-catanddog/repos/migrate"
+	"os"
+	"slices"
+	"text/tabwriter"
+)
+
+// getMigrateCmd returns subcommand following the migrate arg. ok is false if migrate arg is not presented
+func getMigrateCmd() (cmd string, ok bool, err error) {
+	i := slices.Index(os.Args, "migrate")
+	if i == -1 {
+		return "", false, nil
+	}
+	// i+2 means something ahead of migrate arg exists
+	if len(os.Args) < i+2 || !slices.Contains([]string{"up", "down", "status"}, os.Args[i+1]) {
+		return "", true, fmt.Errorf("migrate expects one of: up, down, status")
+	}
+	return os.Args[i+1], true, nil
+}
+
+// runMigrate executes migrate subcommand against db
+func runMigrate(ctx context.Context, db repos.DB, cmd string, l *slog.Logger) error {
+	src, ok := db.(migrate.Source)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [migrate.Source] interface")
+	}
+
+	switch cmd {
+	case "up":
+		applied, err := migrate.Up(ctx, src, l)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", len(applied))
+	case "down":
+		version, err := migrate.Down(ctx, src, l)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			fmt.Println("nothing to revert")
+			return nil
+		}
+		fmt.Printf("reverted migration %d\n", version)
+	case "status":
+		states, err := migrate.Status(ctx, src)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, val := range states {
+			at := "pending"
+			if val.Applied {
+				at = val.AppliedAt
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", val.Version, val.Name, at)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// checkMigrations refuses to serve against a db with pending migrations
+func checkMigrations(ctx context.Context, db repos.DB) error {
+	src, ok := db.(migrate.Source)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [migrate.Source] interface")
+	}
+	if err := migrate.Check(ctx, src); err != nil {
+		return fmt.Errorf("%w; run with 'migrate up' first", err)
+	}
+	return nil
+}
+
+// initDB applies pending migrations and seeds dictionaries in case seedPath is set
+func initDB(ctx context.Context, db repos.DB, seedPath string, l *slog.Logger) error {
+	src, ok := db.(migrate.Source)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [migrate.Source] interface")
+	}
+	applied, err := migrate.Up(ctx, src, l)
+	if err != nil {
+		return err
+	}
+	l.Info("db schema is up to date", "applied", len(applied))
+
+	if seedPath == "" {
+		return nil
+	}
+	seed, err := repos.LoadSeed(seedPath)
+	if err != nil {
+		return err
+	}
+	seeder, ok := db.(repos.Seeder)
+	if !ok {
+		return fmt.Errorf("object of type [DB] interface failed to covert to [repos.Seeder] interface")
+	}
+	if err := seeder.SeedDictTables(ctx, seed, l); err != nil {
+		return err
+	}
+	l.Info("dictionary seed processed", "file", seedPath)
+	return nil
+}
@@ -0,0 +1,280 @@
+package policy
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/controllers"
+)
+
+// DocTypeGetter returns next guarded by DocTypeRead
+func (p *Policy) DocTypeGetter(next controllers.DocTypeGetter) controllers.DocTypeGetter {
+	return docTypeGetter{p: p, next: next}
+}
+
+type docTypeGetter struct {
+	p    *Policy
+	next controllers.DocTypeGetter
+}
+
+func (g docTypeGetter) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeRead, l); err != nil {
+		return controllers.DocType{}, err
+	}
+	return g.next.DocTypeGetById(ctx, id, l)
+}
+
+func (g docTypeGetter) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) (controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeRead, l); err != nil {
+		return controllers.DocType{}, err
+	}
+	return g.next.DocTypeGetByDoc(ctx, doc, l)
+}
+
+func (g docTypeGetter) DocTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.DocTypeGetByIds(ctx, ids, l)
+}
+
+func (g docTypeGetter) DocTypeGetByDocs(ctx context.Context, docs []string, l *slog.Logger) ([]controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.DocTypeGetByDocs(ctx, docs, l)
+}
+
+func (g docTypeGetter) DocTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.DocType, []string, error) {
+	if err := g.p.Check(ctx, DocTypeRead, l); err != nil {
+		return nil, nil, err
+	}
+	return g.next.DocTypeList(ctx, q, l)
+}
+
+// DocTypeWriter returns next guarded by DocTypeWrite
+func (p *Policy) DocTypeWriter(next controllers.DocTypeWriter) controllers.DocTypeWriter {
+	return docTypeWriter{p: p, next: next}
+}
+
+type docTypeWriter struct {
+	p    *Policy
+	next controllers.DocTypeWriter
+}
+
+func (g docTypeWriter) DocTypeCreate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeWrite, l); err != nil {
+		return controllers.DocType{}, err
+	}
+	return g.next.DocTypeCreate(ctx, d, l)
+}
+
+func (g docTypeWriter) DocTypeUpdate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	if err := g.p.Check(ctx, DocTypeWrite, l); err != nil {
+		return controllers.DocType{}, err
+	}
+	return g.next.DocTypeUpdate(ctx, d, l)
+}
+
+func (g docTypeWriter) DocTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	if err := g.p.Check(ctx, DocTypeWrite, l); err != nil {
+		return err
+	}
+	return g.next.DocTypeDelete(ctx, id, l)
+}
+
+// AnimalTypeGetter returns next guarded by AnimalTypeRead
+func (p *Policy) AnimalTypeGetter(next controllers.AnimalTypeGetter) controllers.AnimalTypeGetter {
+	return animalTypeGetter{p: p, next: next}
+}
+
+type animalTypeGetter struct {
+	p    *Policy
+	next controllers.AnimalTypeGetter
+}
+
+func (g animalTypeGetter) AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeRead, l); err != nil {
+		return controllers.AnimalType{}, err
+	}
+	return g.next.AnimalTypeGetById(ctx, id, l)
+}
+
+func (g animalTypeGetter) AnimalTypeGetByType(ctx context.Context, animalType string, l *slog.Logger) (controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeRead, l); err != nil {
+		return controllers.AnimalType{}, err
+	}
+	return g.next.AnimalTypeGetByType(ctx, animalType, l)
+}
+
+func (g animalTypeGetter) AnimalTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.AnimalTypeGetByIds(ctx, ids, l)
+}
+
+func (g animalTypeGetter) AnimalTypeGetByTypes(ctx context.Context, types []string, l *slog.Logger) ([]controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.AnimalTypeGetByTypes(ctx, types, l)
+}
+
+func (g animalTypeGetter) AnimalTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AnimalType, []string, error) {
+	if err := g.p.Check(ctx, AnimalTypeRead, l); err != nil {
+		return nil, nil, err
+	}
+	return g.next.AnimalTypeList(ctx, q, l)
+}
+
+// AnimalTypeWriter returns next guarded by AnimalTypeWrite
+func (p *Policy) AnimalTypeWriter(next controllers.AnimalTypeWriter) controllers.AnimalTypeWriter {
+	return animalTypeWriter{p: p, next: next}
+}
+
+type animalTypeWriter struct {
+	p    *Policy
+	next controllers.AnimalTypeWriter
+}
+
+func (g animalTypeWriter) AnimalTypeCreate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeWrite, l); err != nil {
+		return controllers.AnimalType{}, err
+	}
+	return g.next.AnimalTypeCreate(ctx, a, l)
+}
+
+func (g animalTypeWriter) AnimalTypeUpdate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	if err := g.p.Check(ctx, AnimalTypeWrite, l); err != nil {
+		return controllers.AnimalType{}, err
+	}
+	return g.next.AnimalTypeUpdate(ctx, a, l)
+}
+
+func (g animalTypeWriter) AnimalTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	if err := g.p.Check(ctx, AnimalTypeWrite, l); err != nil {
+		return err
+	}
+	return g.next.AnimalTypeDelete(ctx, id, l)
+}
+
+// HumanRepo returns next guarded by HumanRead and HumanWrite
+func (p *Policy) HumanRepo(next controllers.HumanRepo) controllers.HumanRepo {
+	return humanRepo{p: p, next: next}
+}
+
+type humanRepo struct {
+	p    *Policy
+	next controllers.HumanRepo
+}
+
+func (g humanRepo) HumanCreate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	if err := g.p.Check(ctx, HumanWrite, l); err != nil {
+		return controllers.Human{}, err
+	}
+	return g.next.HumanCreate(ctx, h, l)
+}
+
+func (g humanRepo) HumanGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Human, error) {
+	if err := g.p.Check(ctx, HumanRead, l); err != nil {
+		return controllers.Human{}, err
+	}
+	return g.next.HumanGet(ctx, docId, l)
+}
+
+func (g humanRepo) HumanUpdate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	if err := g.p.Check(ctx, HumanWrite, l); err != nil {
+		return controllers.Human{}, err
+	}
+	return g.next.HumanUpdate(ctx, h, l)
+}
+
+func (g humanRepo) HumanDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	if err := g.p.Check(ctx, HumanWrite, l); err != nil {
+		return err
+	}
+	return g.next.HumanDelete(ctx, docId, l)
+}
+
+func (g humanRepo) HumanSearch(ctx context.Context, f controllers.HumanFilter, l *slog.Logger) ([]controllers.Human, error) {
+	if err := g.p.Check(ctx, HumanRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.HumanSearch(ctx, f, l)
+}
+
+func (g humanRepo) HumanList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.Human, []string, error) {
+	if err := g.p.Check(ctx, HumanRead, l); err != nil {
+		return nil, nil, err
+	}
+	return g.next.HumanList(ctx, q, l)
+}
+
+// AnimalRepo returns next guarded by AnimalRead and AnimalWrite
+func (p *Policy) AnimalRepo(next controllers.AnimalRepo) controllers.AnimalRepo {
+	return animalRepo{p: p, next: next}
+}
+
+type animalRepo struct {
+	p    *Policy
+	next controllers.AnimalRepo
+}
+
+func (g animalRepo) AnimalCreate(ctx context.Context, a controllers.Animal, l *slog.Logger) (controllers.Animal, error) {
+	if err := g.p.Check(ctx, AnimalWrite, l); err != nil {
+		return controllers.Animal{}, err
+	}
+	return g.next.AnimalCreate(ctx, a, l)
+}
+
+func (g animalRepo) AnimalGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Animal, error) {
+	if err := g.p.Check(ctx, AnimalRead, l); err != nil {
+		return controllers.Animal{}, err
+	}
+	return g.next.AnimalGet(ctx, docId, l)
+}
+
+func (g animalRepo) AnimalGetByOwner(ctx context.Context, ownerDocId int, l *slog.Logger) ([]controllers.Animal, error) {
+	if err := g.p.Check(ctx, AnimalRead, l); err != nil {
+		return nil, err
+	}
+	return g.next.AnimalGetByOwner(ctx, ownerDocId, l)
+}
+
+func (g animalRepo) AnimalList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.Animal, []string, error) {
+	if err := g.p.Check(ctx, AnimalRead, l); err != nil {
+		return nil, nil, err
+	}
+	return g.next.AnimalList(ctx, q, l)
+}
+
+func (g animalRepo) AnimalUpdate(ctx context.Context, a controllers.Animal, l *slog.Logger) (controllers.Animal, error) {
+	if err := g.p.Check(ctx, AnimalWrite, l); err != nil {
+		return controllers.Animal{}, err
+	}
+	return g.next.AnimalUpdate(ctx, a, l)
+}
+
+func (g animalRepo) AnimalDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	if err := g.p.Check(ctx, AnimalWrite, l); err != nil {
+		return err
+	}
+	return g.next.AnimalDelete(ctx, docId, l)
+}
+
+// AuditReader returns next guarded by AuditRead
+func (p *Policy) AuditReader(next controllers.AuditReader) controllers.AuditReader {
+	return auditReader{p: p, next: next}
+}
+
+type auditReader struct {
+	p    *Policy
+	next controllers.AuditReader
+}
+
+func (g auditReader) AuditList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AuditEntry, []string, error) {
+	if err := g.p.Check(ctx, AuditRead, l); err != nil {
+		return nil, nil, err
+	}
+	return g.next.AuditList(ctx, q, l)
+}
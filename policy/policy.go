@@ -0,0 +1,56 @@
+// Package policy decides whether the authenticated caller may run an operation. It sits between
+// handlers and the controllers interfaces, so every path to the data is checked the same way
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"slices"
+)
+
+// permissions granted to roles. Read permissions cover every lookup, write ones cover create, update and delete.
+// Animal records hold no medical fields, so there is no vet-only permission, see the rbac migration
+const (
+	DocTypeRead     = "doc_type:read"
+	DocTypeWrite    = "doc_type:write"
+	AnimalTypeRead  = "animal_type:read"
+	AnimalTypeWrite = "animal_type:write"
+	HumanRead       = "human:read"
+	HumanWrite      = "human:write"
+	AnimalRead      = "animal:read"
+	AnimalWrite     = "animal:write"
+	AuditRead       = "audit:read"
+)
+
+// Policy checks permissions of the principal stored in the request context against roles of its subject
+type Policy struct {
+	roles controllers.RoleRepo
+}
+
+// New returns Policy of roles stored in roles
+func New(roles controllers.RoleRepo) (*Policy, error) {
+	if roles == nil {
+		return nil, fmt.Errorf("role repo must be set")
+	}
+	return &Policy{roles: roles}, nil
+}
+
+// Check returns nil in case the caller holds perm. Anonymous callers and callers lacking perm get an error
+// wrapping controllers.ErrForbidden, errors of the role repo are returned as they are
+func (p *Policy) Check(ctx context.Context, perm string, l *slog.Logger) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("anonymous caller lacks %s: %w", perm, controllers.ErrForbidden)
+	}
+	perms, err := p.roles.SubjectPermissions(ctx, principal.Subject, l)
+	if err != nil {
+		return fmt.Errorf("failed to get permissions of %s: %w", principal.Subject, err)
+	}
+	if !slices.Contains(perms, perm) {
+		return fmt.Errorf("%s lacks %s: %w", principal.Subject, perm, controllers.ErrForbidden)
+	}
+	return nil
+}
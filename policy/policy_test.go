@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mis-catanddog/auth"
+	"mis-catanddog/controllers"
+	"strings"
+	"testing"
+)
+
+// roleRepo grants permissions by subject
+type roleRepo struct {
+	perms map[string][]string
+	err   error
+}
+
+func (r roleRepo) SubjectPermissions(ctx context.Context, subject string, l *slog.Logger) ([]string, error) {
+	return r.perms[subject], r.err
+}
+
+func (r roleRepo) RoleGrant(ctx context.Context, subject string, role string, l *slog.Logger) error {
+	return nil
+}
+
+func (r roleRepo) RoleRevoke(ctx context.Context, subject string, role string, l *slog.Logger) error {
+	return nil
+}
+
+// humans counts calls that got through the policy
+type humans struct {
+	controllers.HumanRepo
+	calls int
+}
+
+func (h *humans) HumanGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Human, error) {
+	h.calls++
+	return controllers.Human{DocId: docId}, nil
+}
+
+func (h *humans) HumanDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	h.calls++
+	return nil
+}
+
+// animalTypes counts calls that got through the policy
+type animalTypes struct {
+	controllers.AnimalTypeGetter
+	calls int
+}
+
+func (a *animalTypes) AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.AnimalType, error) {
+	a.calls++
+	return controllers.AnimalType{Id: id, Type: "dog"}, nil
+}
+
+func TestCheck(t *testing.T) {
+	type checkTest struct {
+		Repo      roleRepo
+		Principal *auth.Principal
+		Perm      string
+		Err       error
+		Message   string
+		Crit      bool
+	}
+
+	var fail bool
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var repo = roleRepo{perms: map[string][]string{
+		"front desk": {DocTypeRead, HumanRead, HumanWrite},
+		"dr who":     {AnimalRead, AnimalWrite},
+	}}
+
+	var arr = []checkTest{
+		{Repo: repo, Principal: &auth.Principal{Subject: "front desk"}, Perm: HumanWrite, Message: "positive test [granted] failed", Crit: true},
+		{Repo: repo, Principal: &auth.Principal{Subject: "dr who", Method: auth.MethodJWT}, Perm: AnimalWrite, Message: "positive test [token subject] failed", Crit: true},
+		{Repo: repo, Principal: &auth.Principal{Subject: "front desk"}, Perm: DocTypeWrite, Err: controllers.ErrForbidden, Message: "negative test [dictionary write] failed", Crit: true},
+		{Repo: repo, Principal: &auth.Principal{Subject: "stranger"}, Perm: HumanRead, Err: controllers.ErrForbidden, Message: "negative test [no roles] failed", Crit: true},
+		{Repo: repo, Perm: DocTypeRead, Err: controllers.ErrForbidden, Message: "negative test [anonymous] failed", Crit: true},
+		{Repo: roleRepo{err: controllers.ErrUnavailable}, Principal: &auth.Principal{Subject: "front desk"}, Perm: HumanRead, Err: controllers.ErrUnavailable, Message: "negative test [repo unavailable] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		var ctx = context.Background()
+		if val.Principal != nil {
+			ctx = auth.WithPrincipal(ctx, *val.Principal)
+		}
+		p, _ := New(val.Repo)
+		err := p.Check(ctx, val.Perm, l)
+		if !errors.Is(err, val.Err) || val.Err == nil && err != nil {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	var l = slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+	var repo = roleRepo{perms: map[string][]string{"front desk": {HumanRead}}}
+	var ctx = auth.WithPrincipal(context.Background(), auth.Principal{Subject: "front desk"})
+	p, _ := New(repo)
+
+	next := &humans{}
+	guarded := p.HumanRepo(next)
+	if h, err := guarded.HumanGet(ctx, 7, l); err != nil || h.DocId != 7 {
+		t.Fatalf("granted call: got %+v, %v", h, err)
+	}
+	if err := guarded.HumanDelete(ctx, 7, l); !errors.Is(err, controllers.ErrForbidden) {
+		t.Fatalf("denied call: got %v, expected ErrForbidden", err)
+	}
+	if next.calls != 1 {
+		t.Fatalf("denied call must not reach the repo; got %d calls", next.calls)
+	}
+
+	types := &animalTypes{}
+	if a, err := p.AnimalTypeGetter(types).AnimalTypeGetById(ctx, 1, l); !errors.Is(err, controllers.ErrForbidden) || a.Id != 0 || types.calls != 0 {
+		t.Fatalf("denied animal type lookup: got %+v, %v after %d calls", a, err, types.calls)
+	}
+}
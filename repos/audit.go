@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mis-catanddog/controllers"
+	"reflect"
+	"strconv"
+)
+
+// actions of the audit log
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// SystemActor is recorded for changes made without an actor in the context, like seeding on start
+const SystemActor = "system"
+
+// Change is a change of a single record, backends write it to audit_log in the transaction of the change.
+// Entity is the table name. Records which ids are assigned by the DB leave EntityId empty
+// and name the unique LookupColumn holding LookupValue instead, so the id is read after the insert.
+// Before is nil for created records, After for deleted ones
+type Change struct {
+	Entity       string
+	EntityId     string
+	LookupColumn string
+	LookupValue  any
+	Action       string
+	Before       any
+	After        any
+}
+
+// AuditArgs returns actor, request_id, entity, action, before and after of the audit_log record of c.
+// before and after hold fields that differ only, they are NULL when the record is missing on that side
+func AuditArgs(ctx context.Context, c Change) ([]any, error) {
+	actor, _ := controllers.ActorFromContext(ctx)
+	if actor.Subject == "" {
+		actor.Subject = SystemActor
+	}
+
+	before, after, err := auditDiff(c.Before, c.After)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: cannot encode audit record: %w", c.Entity, c.Action, err)
+	}
+	return append(make([]any, 0), actor.Subject, actor.RequestId, c.Entity, c.Action, before, after), nil
+}
+
+// auditDiff encodes fields of before and after, dropping the ones that are equal on both sides
+func auditDiff(before, after any) (any, any, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b != nil && a != nil {
+		for key, val := range b {
+			if reflect.DeepEqual(val, a[key]) {
+				delete(b, key)
+				delete(a, key)
+			}
+		}
+	}
+	return encode(b), encode(a), nil
+}
+
+// fields converts v to a map of its JSON fields, nil for nil v
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encode returns JSON of m as a string argument, NULL for nil m
+func encode(m map[string]any) any {
+	if m == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(m)
+	return string(raw)
+}
+
+// AuditListValues returns list field values of e
+func AuditListValues(e controllers.AuditEntry) map[string]string {
+	var date = e.At
+	if len(date) > len(controllers.DateLayout) {
+		date = date[:len(controllers.DateLayout)]
+	}
+	return map[string]string{
+		"id":         strconv.Itoa(e.Id),
+		"date":       date,
+		"actor":      e.Actor,
+		"request_id": e.RequestId,
+		"entity":     e.Entity,
+		"entity_id":  e.EntityId,
+		"action":     e.Action,
+	}
+}
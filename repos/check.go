@@ -0,0 +1,33 @@
+package repos
+
+import (
+	"context"
+	"log/slog"
+)
+
+// problems found by Checker
+const (
+	ProblemOrphanedAnimal    = "orphaned_animal"     // owner_doc_id refers to a missing human
+	ProblemUnknownDocType    = "unknown_doc_type"    // doc_type refers to a missing doc_type record
+	ProblemUnknownAnimalType = "unknown_animal_type" // animal_type refers to a missing animal_type record
+	ProblemTypeMismatch      = "type_mismatch"       // column holds a value of another type than declared
+)
+
+// Issue is an inconsistency of a single record. Value is the offending column value as text.
+// Repair tells what Repair does about the issue, empty means it needs a manual fix
+type Issue struct {
+	Problem  string
+	Entity   string
+	EntityId string
+	Column   string
+	Value    string
+	Repair   string
+}
+
+// Checker scans data for inconsistencies the db has let in, like records written before foreign keys
+// were enforced or edited by hand. RepairConsistency fixes the issues that have a repair through audited writes
+// and returns them, the rest is left for a manual fix
+type Checker interface {
+	CheckConsistency(ctx context.Context, l *slog.Logger) ([]Issue, error)
+	RepairConsistency(ctx context.Context, l *slog.Logger) ([]Issue, error)
+}
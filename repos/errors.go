@@ -0,0 +1,21 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"mis-catanddog/controllers"
+	"net"
+)
+
+// Unavailable wraps err with controllers.ErrUnavailable in case err means the DB can't serve requests
+// right now, unlike a broken query. Backends check their driver specific errors before calling it
+func Unavailable(err error) error {
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %w", controllers.ErrUnavailable, err)
+	}
+	return err
+}
@@ -11,9 +11,14 @@ type DbReq struct {
 	Args  []any
 }
 
+// DB is the connection of a backend. It is read-only on purpose: records are written by the repos of the backends,
+// which audit every change, and schema changes are up to the migrate package
 type DB interface {
 	New(uri string, timeout time.Duration) error
 	Get(ctx context.Context, r DbReq) (*sql.Rows, error)
-	Exec(ctx context.Context, rs []DbReq) error
+	// Ping verifies the connection is still alive
+	Ping(ctx context.Context) error
+	// Version returns version of the db server
+	Version(ctx context.Context) (string, error)
 	Close()
 }
@@ -0,0 +1,205 @@
+package repos
+
+import (
+	"fmt"
+	"mis-catanddog/controllers"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListDialect holds backend specific parts of the list query
+type ListDialect struct {
+	// Placeholder returns placeholder of the n-th argument, starting from 1
+	Placeholder func(n int) string
+	// Date wraps placeholder of a date argument, so it compares with the date column
+	Date func(ph string) string
+}
+
+// listArgs collects query arguments and hands out placeholders for them
+type listArgs struct {
+	args []any
+	d    ListDialect
+}
+
+// add converts value according to the field type and returns its placeholder
+func (a *listArgs) add(f controllers.ListField, value string) (string, error) {
+	switch f.Type {
+	case controllers.ListInt:
+		intVal, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("field %s: [%s] is not an integer", f.Name, value)
+		}
+		a.args = append(a.args, intVal)
+	case controllers.ListDate:
+		if _, err := time.Parse(controllers.DateLayout, value); err != nil {
+			return "", fmt.Errorf("field %s: [%s] is not a %s date", f.Name, value, controllers.DateLayout)
+		}
+		a.args = append(a.args, value)
+		return a.d.Date(a.d.Placeholder(len(a.args))), nil
+	default:
+		a.args = append(a.args, value)
+	}
+	return a.d.Placeholder(len(a.args)), nil
+}
+
+// likePattern escapes LIKE wildcards in s and turns '*' into '%'. Use with ESCAPE '\'
+func likePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return r.Replace(s)
+}
+
+// ListQuery builds the page query. sel is SELECT ... FROM ... without WHERE, columns maps every spec field
+// to its SQL column. Field names and values never reach the query text, only columns from the map do.
+// One record more than q.Limit is asked for, so the caller can tell whether there is a next page
+func ListQuery(sel string, columns map[string]string, spec controllers.ListSpec, q controllers.ListQuery, d ListDialect) (DbReq, error) {
+	var where []string
+	var order []string
+	var a = listArgs{d: d}
+
+	field := func(name string) (controllers.ListField, string, error) {
+		f, ok := spec.Field(name)
+		col, okCol := columns[name]
+		if !ok || !okCol {
+			return f, "", fmt.Errorf("unknown list field [%s]", name)
+		}
+		return f, col, nil
+	}
+
+	if q.Limit <= 0 {
+		return DbReq{}, fmt.Errorf("list limit must be positive")
+	}
+	if len(q.Sort) == 0 || q.Sort[len(q.Sort)-1].Field != spec.Key {
+		return DbReq{}, fmt.Errorf("list sort must end with the key field [%s]", spec.Key)
+	}
+	if len(q.After) != 0 && len(q.After) != len(q.Sort) {
+		return DbReq{}, fmt.Errorf("list cursor holds %d values, %d expected", len(q.After), len(q.Sort))
+	}
+
+	// filters
+	for _, val := range q.Filters {
+		f, col, err := field(val.Field)
+		if err != nil {
+			return DbReq{}, err
+		}
+		switch {
+		case val.Op == controllers.OpEq:
+			ph, err := a.add(f, val.Value)
+			if err != nil {
+				return DbReq{}, err
+			}
+			where = append(where, col+" = "+ph)
+		case val.Op == controllers.OpLike && f.Type == controllers.ListText:
+			ph, _ := a.add(f, likePattern(val.Value))
+			where = append(where, col+" LIKE "+ph+` ESCAPE '\'`)
+		case (val.Op == controllers.OpGte || val.Op == controllers.OpLte) && f.Type != controllers.ListText:
+			ph, err := a.add(f, val.Value)
+			if err != nil {
+				return DbReq{}, err
+			}
+			cmp := " >= "
+			if val.Op == controllers.OpLte {
+				cmp = " <= "
+			}
+			where = append(where, col+cmp+ph)
+		default:
+			return DbReq{}, fmt.Errorf("operation [%s] is not supported by field [%s]", val.Op, val.Field)
+		}
+	}
+
+	// keyset: (s1 > v1) OR (s1 = v1 AND s2 > v2) OR ..., '<' for descending fields
+	var keyset []string
+	for i := 0; i < len(q.After); i++ {
+		var and []string
+		for j := 0; j <= i; j++ {
+			f, col, err := field(q.Sort[j].Field)
+			if err != nil {
+				return DbReq{}, err
+			}
+			ph, err := a.add(f, q.After[j])
+			if err != nil {
+				return DbReq{}, err
+			}
+			cmp := " = "
+			if j == i && q.Sort[j].Desc {
+				cmp = " < "
+			} else if j == i {
+				cmp = " > "
+			}
+			and = append(and, col+cmp+ph)
+		}
+		keyset = append(keyset, "("+strings.Join(and, " AND ")+")")
+	}
+	if len(keyset) > 0 {
+		where = append(where, "("+strings.Join(keyset, " OR ")+")")
+	}
+
+	// order
+	for _, val := range q.Sort {
+		_, col, err := field(val.Field)
+		if err != nil {
+			return DbReq{}, err
+		}
+		if val.Desc {
+			col += " DESC"
+		}
+		order = append(order, col)
+	}
+
+	query := sel
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + strings.Join(order, ", ") + fmt.Sprintf(" LIMIT %d", q.Limit+1)
+
+	return DbReq{Query: query, Args: a.args}, nil
+}
+
+// ListPage trims items fetched by ListQuery to q.Limit. Returns sort field values of the last item
+// in case there is a next page, nil otherwise
+func ListPage[T any](items []T, q controllers.ListQuery, values func(T) map[string]string) ([]T, []string) {
+	if len(items) <= q.Limit {
+		return items, nil
+	}
+	items = items[:q.Limit]
+	last := values(items[len(items)-1])
+	after := make([]string, 0, len(q.Sort))
+	for _, val := range q.Sort {
+		after = append(after, last[val.Field])
+	}
+	return items, after
+}
+
+// DocTypeListValues returns list field values of d
+func DocTypeListValues(d controllers.DocType) map[string]string {
+	return map[string]string{"id": strconv.Itoa(d.Id), "doc": d.Doc}
+}
+
+// AnimalTypeListValues returns list field values of a
+func AnimalTypeListValues(a controllers.AnimalType) map[string]string {
+	return map[string]string{"id": strconv.Itoa(a.Id), "type": a.Type}
+}
+
+// HumanListValues returns list field values of h
+func HumanListValues(h controllers.Human) map[string]string {
+	return map[string]string{
+		"doc_id":     strconv.Itoa(h.DocId),
+		"doc_type":   strconv.Itoa(h.DocType),
+		"first_name": h.FirstName,
+		"last_name":  h.LastName,
+		"birth_date": h.BirthDate,
+	}
+}
+
+// AnimalListValues returns list field values of a
+func AnimalListValues(a controllers.Animal) map[string]string {
+	return map[string]string{
+		"doc_id":       strconv.Itoa(a.DocId),
+		"doc_type":     strconv.Itoa(a.DocType),
+		"name":         a.Name,
+		"birth_date":   a.BirthDate,
+		"animal_type":  strconv.Itoa(a.AnimalType),
+		"breed":        a.Breed,
+		"owner_doc_id": strconv.Itoa(a.OwnerDocId),
+	}
+}
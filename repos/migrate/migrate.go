@@ -0,0 +1,227 @@
+// Package migrate applies numbered schema migrations to any repos.DB backend.
+// Every backend ships its own dialect of the same numbered migrations and exposes them via Source.
+// Applied versions are recorded in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mis-catanddog/repos"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrOutdated means the db schema lacks migrations known to this build
+var ErrOutdated = errors.New("db schema is not up to date")
+
+// fileName matches migration files, e.g. 0001_init.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// schemaTable is written in a dialect understood by every backend
+const schemaTable = "CREATE TABLE IF NOT EXISTS schema_migrations ( version integer PRIMARY KEY NOT NULL, name text NOT NULL, applied_at text NOT NULL DEFAULT CURRENT_TIMESTAMP );"
+
+// Source is implemented by every repos.DB backend supporting migrations
+type Source interface {
+	repos.DB
+	// ExecMigration runs queries in a single transaction bypassing the audit of the repos
+	ExecMigration(ctx context.Context, rs []repos.DbReq) error
+	// Migrations returns the directory with backend specific migration files
+	Migrations() fs.FS
+}
+
+// Migration is a single numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// State tells whether the migration is applied to the DB
+type State struct {
+	Migration
+	Applied   bool
+	AppliedAt string
+}
+
+// Load reads migrations from fsys. Versions must start from 1 and go without gaps,
+// each version must have both up and down files
+func Load(fsys fs.FS) ([]Migration, error) {
+	var byVersion = make(map[int]*Migration)
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration %s: %w", e.Name(), err)
+		}
+
+		val, ok := byVersion[version]
+		if !ok {
+			val = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = val
+		}
+		if val.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s, %s", version, val.Name, m[2])
+		}
+		if m[3] == "up" {
+			val.Up = string(body)
+		} else {
+			val.Down = string(body)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, val := range byVersion {
+		result = append(result, *val)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	for i, val := range result {
+		if val.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if val.Up == "" || val.Down == "" {
+			return nil, fmt.Errorf("migration %d must have both up and down files", val.Version)
+		}
+	}
+	return result, nil
+}
+
+// noop reports whether the migration body holds comments only. Such migrations keep versions of the backends
+// in line, they are recorded without running anything
+func noop(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns all known migrations with their state. schema_migrations is created in case it is missing
+func Status(ctx context.Context, db Source) ([]State, error) {
+	if err := db.ExecMigration(ctx, []repos.DbReq{{Query: schemaTable}}); err != nil {
+		return nil, fmt.Errorf("cannot create schema_migrations: %w", err)
+	}
+	return status(ctx, db)
+}
+
+// status returns all known migrations with their state without changing the db
+func status(ctx context.Context, db Source) ([]State, error) {
+	migrations, err := Load(db.Migrations())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Get(ctx, repos.DbReq{Query: "SELECT version, applied_at FROM schema_migrations"})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	var applied = make(map[int]string)
+	for rows.Next() {
+		var version int
+		var at string
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("cannot read schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read schema_migrations: %w", err)
+	}
+
+	result := make([]State, 0, len(migrations))
+	for _, val := range migrations {
+		at, ok := applied[val.Version]
+		result = append(result, State{Migration: val, Applied: ok, AppliedAt: at})
+		delete(applied, val.Version)
+	}
+	for version := range applied {
+		return nil, fmt.Errorf("db has migration %d unknown to this build", version)
+	}
+	return result, nil
+}
+
+// Up applies all pending migrations in order. Each migration runs in its own transaction
+// together with its schema_migrations record. Returns versions applied
+func Up(ctx context.Context, db Source, l *slog.Logger) ([]int, error) {
+	var result []int
+
+	states, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, val := range states {
+		if val.Applied {
+			continue
+		}
+		rs := []repos.DbReq{{Query: fmt.Sprintf("INSERT INTO schema_migrations (version, name) VALUES (%d, '%s');", val.Version, val.Name)}}
+		if !noop(val.Up) {
+			rs = append([]repos.DbReq{{Query: val.Up}}, rs...)
+		}
+		if err := db.ExecMigration(ctx, rs); err != nil {
+			return result, fmt.Errorf("migration %d_%s failed: %w", val.Version, val.Name, err)
+		}
+		l.Info("migration applied", "version", val.Version, "name", val.Name)
+		result = append(result, val.Version)
+	}
+	return result, nil
+}
+
+// Down reverts the latest applied migration. Returns version reverted or 0 if nothing is applied
+func Down(ctx context.Context, db Source, l *slog.Logger) (int, error) {
+	states, err := Status(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(states) - 1; i >= 0; i-- {
+		val := states[i]
+		if !val.Applied {
+			continue
+		}
+		rs := []repos.DbReq{{Query: fmt.Sprintf("DELETE FROM schema_migrations WHERE version=%d;", val.Version)}}
+		if !noop(val.Down) {
+			rs = append([]repos.DbReq{{Query: val.Down}}, rs...)
+		}
+		if err := db.ExecMigration(ctx, rs); err != nil {
+			return 0, fmt.Errorf("migration %d_%s revert failed: %w", val.Version, val.Name, err)
+		}
+		l.Info("migration reverted", "version", val.Version, "name", val.Name)
+		return val.Version, nil
+	}
+	return 0, nil
+}
+
+// Check returns ErrOutdated in case any migration is not applied. It only reads the db, so it is cheap
+// enough for probes. A db without schema_migrations is not migrated at all
+func Check(ctx context.Context, db Source) error {
+	states, err := status(ctx, db)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrOutdated, err)
+	}
+	var pending int
+	for _, val := range states {
+		if !val.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d of %d migrations pending", ErrOutdated, pending, len(states))
+	}
+	return nil
+}
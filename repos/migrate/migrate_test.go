@@ -0,0 +1,235 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mis-catanddog/repos"
+	"mis-catanddog/repos/pgsql"
+	"mis-catanddog/repos/sqlite3"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	type loadTest struct {
+		Fs      fstest.MapFS
+		Len     int
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var file = func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	var arr = []loadTest{
+		{
+			Fs: fstest.MapFS{
+				"0002_b.up.sql": file("2"), "0002_b.down.sql": file("-2"),
+				"0001_a.up.sql": file("1"), "0001_a.down.sql": file("-1"),
+			},
+			Len:     2,
+			Message: "positive test [two migrations] failed",
+			Crit:    true,
+		},
+		{
+			Fs:      fstest.MapFS{"0001_a.up.sql": file("1")},
+			IsErr:   true,
+			Message: "negative test [no down file] failed",
+			Crit:    true,
+		},
+		{
+			Fs: fstest.MapFS{
+				"0001_a.up.sql": file("1"), "0001_a.down.sql": file("-1"),
+				"0003_c.up.sql": file("3"), "0003_c.down.sql": file("-3"),
+			},
+			IsErr:   true,
+			Message: "negative test [version gap] failed",
+			Crit:    true,
+		},
+		{
+			Fs:      fstest.MapFS{"0001_a.up.sql": file("1"), "0001_b.down.sql": file("-1")},
+			IsErr:   true,
+			Message: "negative test [names differ] failed",
+			Crit:    true,
+		},
+		{
+			Fs:      fstest.MapFS{"init.sql": file("1")},
+			IsErr:   true,
+			Message: "negative test [unexpected file] failed",
+			Crit:    true,
+		},
+	}
+
+	for _, val := range arr {
+		result, err := Load(val.Fs)
+		if (err != nil) != val.IsErr || len(result) != val.Len {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+// TestBackendMigrations makes sure every backend ships the same versions
+func TestBackendMigrations(t *testing.T) {
+	sqliteList, err := Load((&sqlite3.SqLiteDB{}).Migrations())
+	if err != nil {
+		t.Fatalf("sqlite migrations: %s", err.Error())
+	}
+	pgsqlList, err := Load((&pgsql.PgSqlDB{}).Migrations())
+	if err != nil {
+		t.Fatalf("pgsql migrations: %s", err.Error())
+	}
+	if len(sqliteList) != len(pgsqlList) {
+		t.Fatalf("sqlite has %d migrations, pgsql has %d", len(sqliteList), len(pgsqlList))
+	}
+	for i := range sqliteList {
+		if sqliteList[i].Name != pgsqlList[i].Name {
+			t.Fatalf("migration %d differs: sqlite %s, pgsql %s", i+1, sqliteList[i].Name, pgsqlList[i].Name)
+		}
+	}
+}
+
+func TestNoop(t *testing.T) {
+	type noopTest struct {
+		Body    string
+		Result  bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []noopTest{
+		{Body: "-- nothing to do\n\n-- on this backend\n", Result: true, Message: "positive test [comments only] failed", Crit: true},
+		{Body: "", Result: true, Message: "positive test [empty] failed", Crit: true},
+		{Body: "-- rename\nALTER TABLE a RENAME TO b;\n", Result: false, Message: "negative test [statement after comment] failed", Crit: true},
+		{Body: "SELECT 1; -- trailing comment", Result: false, Message: "negative test [statement with comment] failed", Crit: true},
+	}
+
+	for _, val := range arr {
+		if result := noop(val.Body); result != val.Result {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; got %t", val.Crit, val.Message, result)
+		}
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	var ctx = context.Background()
+	var l = slog.Default()
+	var db = &sqlite3.SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+
+	// Check is read-only, an empty db stays empty
+	if err := Check(ctx, db); !errors.Is(err, ErrOutdated) {
+		t.Fatalf("Check on empty db: got %v, expected ErrOutdated", err)
+	}
+	rows, err := db.Get(ctx, repos.DbReq{Query: "SELECT name FROM sqlite_master"})
+	if err != nil {
+		t.Fatalf("select: %s", err.Error())
+	}
+	if rows.Next() {
+		t.Fatalf("Check must not create tables")
+	}
+	rows.Close()
+	applied, err := Up(ctx, db, l)
+	if err != nil || len(applied) == 0 {
+		t.Fatalf("Up: got %v, %v", applied, err)
+	}
+	if err := Check(ctx, db); err != nil {
+		t.Fatalf("Check after Up: %s", err.Error())
+	}
+	// migrations must not break references, so the animal needs its references
+	if err := db.ExecMigration(ctx, []repos.DbReq{
+		{Query: "INSERT INTO doc_type (id, doc) VALUES (1, 'passport')"},
+		{Query: "INSERT INTO animal_type (id, type) VALUES (1, 'dog')"},
+		{Query: "INSERT INTO human (doc_id, doc_type, first_name, last_name, birth_date) VALUES (5, 1, 'Ann', 'Lee', 0)"},
+		{Query: "INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (1, 1, 'Rex', 0, 1, 'husky', 5)"},
+	}); err != nil {
+		t.Fatalf("insert: %s", err.Error())
+	}
+
+	// revert everything and apply again, data of rebuilt tables must survive while they exist
+	for i := len(applied); i > 1; i-- {
+		if version, err := Down(ctx, db, l); err != nil || version != i {
+			t.Fatalf("Down: got %d, %v; expected %d", version, err, i)
+		}
+	}
+	if _, err := Up(ctx, db, l); err != nil {
+		t.Fatalf("second Up: %s", err.Error())
+	}
+	rows, err = db.Get(ctx, repos.DbReq{Query: "SELECT typeof(owner_doc_id) FROM animal WHERE doc_id=1"})
+	if err != nil {
+		t.Fatalf("select: %s", err.Error())
+	}
+	defer rows.Close()
+	var typ string
+	if !rows.Next() || rows.Scan(&typ) != nil || typ != "integer" {
+		t.Fatalf("owner_doc_id must survive migrations as integer; got [%s]", typ)
+	}
+}
+
+func TestSubjectNamespace(t *testing.T) {
+	var ctx = context.Background()
+	var l = slog.Default()
+	var db = &sqlite3.SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+
+	// grants made before subjects were namespaced
+	applied, err := Up(ctx, db, l)
+	if err != nil {
+		t.Fatalf("Up: %s", err.Error())
+	}
+	for i := len(applied); i > 5; i-- {
+		if _, err := Down(ctx, db, l); err != nil {
+			t.Fatalf("Down: %s", err.Error())
+		}
+	}
+	if err := db.ExecMigration(ctx, []repos.DbReq{
+		{Query: "INSERT INTO api_key (name, key_hash) VALUES ('billing', 'hash')"},
+		{Query: "INSERT INTO subject_role (subject, role) VALUES ('billing', 'admin'), ('svc-1', 'vet')"},
+	}); err != nil {
+		t.Fatalf("insert: %s", err.Error())
+	}
+	if _, err := Up(ctx, db, l); err != nil {
+		t.Fatalf("second Up: %s", err.Error())
+	}
+
+	rows, err := db.Get(ctx, repos.DbReq{Query: "SELECT subject || ' ' || role FROM subject_role ORDER BY subject"})
+	if err != nil {
+		t.Fatalf("select: %s", err.Error())
+	}
+	defer rows.Close()
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			t.Fatalf("scan: %s", err.Error())
+		}
+		grants = append(grants, grant)
+	}
+	// the issuer of token subjects is unknown, their grants are dropped
+	if len(grants) != 1 || grants[0] != "apikey:billing admin" {
+		t.Fatalf("grants of key names must move to the keys; got %v", grants)
+	}
+}
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS doc_type (
+	id integer PRIMARY KEY,
+	doc text NOT NULL
+);
+CREATE TABLE IF NOT EXISTS animal_type (
+	id integer PRIMARY KEY,
+	type text NOT NULL
+);
+CREATE TABLE IF NOT EXISTS human (
+	doc_id integer PRIMARY KEY,
+	doc_type integer NOT NULL REFERENCES doc_type(id),
+	first_name text NOT NULL,
+	middle_name text,
+	last_name text NOT NULL,
+	birth_date date NOT NULL
+);
+CREATE TABLE IF NOT EXISTS animal (
+	doc_id integer PRIMARY KEY,
+	doc_type integer NOT NULL REFERENCES doc_type(id),
+	name text NOT NULL,
+	birth_date date NOT NULL,
+	animal_type integer NOT NULL REFERENCES animal_type(id),
+	breed text NOT NULL,
+	owner_doc_id integer NOT NULL REFERENCES human(doc_id)
+);
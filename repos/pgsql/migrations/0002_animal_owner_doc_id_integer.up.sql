@@ -0,0 +1,2 @@
+-- no-op: owner_doc_id is integer in postgres from the start, see 0001_init.
+-- The version exists to keep the numbering of migrations the same on every backend
@@ -0,0 +1,7 @@
+-- only sha-256 of the key is stored, the key itself is shown once on creation
+CREATE TABLE IF NOT EXISTS api_key (
+	id integer GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	name text NOT NULL UNIQUE,
+	key_hash text NOT NULL UNIQUE,
+	revoked boolean NOT NULL DEFAULT false
+);
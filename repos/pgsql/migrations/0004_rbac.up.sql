@@ -0,0 +1,31 @@
+-- subjects are api key names and sub claims of tokens. Roles and their permissions are seeded here,
+-- granting them to subjects is up to operators
+CREATE TABLE IF NOT EXISTS role (
+	name text PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS role_permission (
+	role text NOT NULL,
+	permission text NOT NULL,
+	PRIMARY KEY (role, permission),
+	FOREIGN KEY (role) REFERENCES role(name)
+);
+CREATE TABLE IF NOT EXISTS subject_role (
+	subject text NOT NULL,
+	role text NOT NULL,
+	PRIMARY KEY (subject, role),
+	FOREIGN KEY (role) REFERENCES role(name)
+);
+-- vet and receptionist hold the same animal permissions on purpose: the animal record has no medical fields,
+-- every column of it is registration data the desk enters. A vet-only permission comes with the first medical field
+INSERT INTO role (name) VALUES ('admin'), ('vet'), ('receptionist');
+INSERT INTO role_permission (role, permission) VALUES
+	('admin', 'doc_type:read'), ('admin', 'doc_type:write'),
+	('admin', 'animal_type:read'), ('admin', 'animal_type:write'),
+	('admin', 'human:read'), ('admin', 'human:write'),
+	('admin', 'animal:read'), ('admin', 'animal:write'),
+	('vet', 'doc_type:read'), ('vet', 'animal_type:read'),
+	('vet', 'human:read'),
+	('vet', 'animal:read'), ('vet', 'animal:write'),
+	('receptionist', 'doc_type:read'), ('receptionist', 'animal_type:read'),
+	('receptionist', 'human:read'), ('receptionist', 'human:write'),
+	('receptionist', 'animal:read'), ('receptionist', 'animal:write');
@@ -0,0 +1,23 @@
+-- every create, update and delete of the repos is recorded here in the transaction of the change.
+-- before and after hold JSON of the changed fields, the log is append-only
+CREATE TABLE IF NOT EXISTS audit_log (
+	id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	at timestamptz NOT NULL DEFAULT now(),
+	actor text NOT NULL,
+	request_id text NOT NULL DEFAULT '',
+	entity text NOT NULL,
+	entity_id text NOT NULL,
+	action text NOT NULL,
+	before jsonb,
+	after jsonb
+);
+CREATE INDEX IF NOT EXISTS audit_log_entity ON audit_log (entity, entity_id);
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+INSERT INTO role (name) VALUES ('auditor');
+INSERT INTO role_permission (role, permission) VALUES ('admin', 'audit:read'), ('auditor', 'audit:read');
@@ -0,0 +1,2 @@
+ALTER TABLE animal_type ALTER COLUMN id DROP IDENTITY IF EXISTS;
+ALTER TABLE doc_type ALTER COLUMN id DROP IDENTITY IF EXISTS;
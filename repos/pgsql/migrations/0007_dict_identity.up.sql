@@ -0,0 +1,6 @@
+-- ids of dictionary records are assigned by the identity of the column, MAX(id) + 1 raced between
+-- concurrent inserts. Explicit ids are still allowed, the identity starts past the existing ones
+ALTER TABLE doc_type ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY;
+SELECT setval(pg_get_serial_sequence('doc_type', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM doc_type;
+ALTER TABLE animal_type ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY;
+SELECT setval(pg_get_serial_sequence('animal_type', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM animal_type;
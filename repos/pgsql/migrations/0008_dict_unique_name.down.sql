@@ -0,0 +1,2 @@
+ALTER TABLE animal_type DROP CONSTRAINT IF EXISTS animal_type_type_unique;
+ALTER TABLE doc_type DROP CONSTRAINT IF EXISTS doc_type_doc_unique;
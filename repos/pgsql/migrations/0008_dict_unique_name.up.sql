@@ -0,0 +1,4 @@
+-- names of dictionary records are lookup keys, the db keeps them unique on its own now.
+-- The migration fails in case duplicates exist already, they are to be merged by hand first
+ALTER TABLE doc_type ADD CONSTRAINT doc_type_doc_unique UNIQUE (doc);
+ALTER TABLE animal_type ADD CONSTRAINT animal_type_type_unique UNIQUE (type);
@@ -0,0 +1,17 @@
+package pgsql
+
+import (
+	"database/sql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// pool settings. Postgres handles simultaneous writes itself, so unlike sqlite no lock is needed
+const (
+	maxOpenConns    = 20
+	maxIdleConns    = 5
+	connMaxLifetime = 30 // minutes
+)
+
+type PgSqlDB struct {
+	db *sql.DB
+}
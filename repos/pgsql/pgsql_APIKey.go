@@ -0,0 +1,74 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// APIKeyGetByHash searches active keys by the hash
+func (p *PgSqlDB) APIKeyGetByHash(ctx context.Context, hash string, l *slog.Logger) (controllers.APIKey, error) {
+	var result controllers.APIKey
+
+	rows, err := p.Get(ctx, repos.DbReq{Query: "SELECT id, name, key_hash, revoked FROM api_key WHERE key_hash=$1 AND revoked=false", Args: append(make([]any, 0), hash)})
+	if err != nil {
+		return result, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&result.Id, &result.Name, &result.Hash, &result.Revoked); err != nil {
+			return controllers.APIKey{}, fmt.Errorf("cannot read query result: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return controllers.APIKey{}, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	if result.Id == 0 {
+		return result, fmt.Errorf("api_key: %w", controllers.ErrNotFound)
+	}
+	return result, nil
+}
+
+// APIKeyCreate inserts new key, id is assigned by the DB
+func (p *PgSqlDB) APIKeyCreate(ctx context.Context, k controllers.APIKey, l *slog.Logger) (controllers.APIKey, error) {
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM api_key WHERE name=$1 OR key_hash=$2", Args: append(make([]any, 0), k.Name, k.Hash)})
+	if err != nil {
+		return controllers.APIKey{}, err
+	}
+	if found {
+		return controllers.APIKey{}, fmt.Errorf("api_key %s: %w", k.Name, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "INSERT INTO api_key (name, key_hash) VALUES ($1, $2)", Args: append(make([]any, 0), k.Name, k.Hash)}
+	// the hash stays out of the log, the name identifies the key
+	change := repos.Change{Entity: "api_key", EntityId: k.Name, Action: repos.ActionCreate, After: map[string]any{"Name": k.Name, "Revoked": false}}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.APIKey{}, fmt.Errorf("failed to create api_key: %w", err)
+	}
+	l.Debug("api_key created", "name", k.Name)
+
+	return p.APIKeyGetByHash(ctx, k.Hash, l)
+}
+
+// APIKeyRevoke disables the key by name. Revoked keys are kept, so their names can't be reused by accident
+func (p *PgSqlDB) APIKeyRevoke(ctx context.Context, name string, l *slog.Logger) error {
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM api_key WHERE name=$1 AND revoked=false", Args: append(make([]any, 0), name)})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("api_key %s: %w", name, controllers.ErrNotFound)
+	}
+
+	req := repos.DbReq{Query: "UPDATE api_key SET revoked=true WHERE name=$1", Args: append(make([]any, 0), name)}
+	change := repos.Change{Entity: "api_key", EntityId: name, Action: repos.ActionUpdate, Before: map[string]any{"Revoked": false}, After: map[string]any{"Revoked": true}}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to revoke api_key: %w", err)
+	}
+	l.Debug("api_key revoked", "name", name)
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+)
+
+// animalSelect is shared by all animal queries. birth_date is a date column
+const animalSelect = "SELECT doc_id, doc_type, name, to_char(birth_date, 'YYYY-MM-DD'), animal_type, breed, owner_doc_id FROM animal"
+
+// AnimalCreate inserts new record into animal table
+func (p *PgSqlDB) AnimalCreate(ctx context.Context, a controllers.Animal, l *slog.Logger) (controllers.Animal, error) {
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM animal WHERE doc_id=$1", Args: append(make([]any, 0), a.DocId)})
+	if err != nil {
+		return controllers.Animal{}, err
+	}
+	if found {
+		return controllers.Animal{}, fmt.Errorf("animal doc_id %d: %w", a.DocId, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{
+		Query: "INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES ($1, $2, $3, $4::date, $5, $6, $7)",
+		Args:  append(make([]any, 0), a.DocId, a.DocType, a.Name, a.BirthDate, a.AnimalType, a.Breed, a.OwnerDocId),
+	}
+	// expansions are not stored
+	stored := a
+	stored.Owner, stored.Type = nil, nil
+	change := repos.Change{Entity: "animal", EntityId: strconv.Itoa(a.DocId), Action: repos.ActionCreate, After: stored}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.Animal{}, fmt.Errorf("failed to create animal: %w", err)
+	}
+	l.Debug("animal created", "doc_id", a.DocId)
+
+	return p.AnimalGet(ctx, a.DocId, l)
+}
+
+// AnimalGet searches animal table by doc_id
+func (p *PgSqlDB) AnimalGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Animal, error) {
+	req := repos.DbReq{Query: animalSelect + " WHERE doc_id=$1", Args: append(make([]any, 0), docId)}
+
+	result, err := p.animalQuery(ctx, req, l)
+	if err != nil {
+		return controllers.Animal{}, err
+	}
+	if len(result) == 0 {
+		return controllers.Animal{}, fmt.Errorf("animal doc_id %d: %w", docId, controllers.ErrNotFound)
+	}
+
+	return result[0], nil
+}
+
+// AnimalGetByOwner returns all animals of the owner ordered by name
+func (p *PgSqlDB) AnimalGetByOwner(ctx context.Context, ownerDocId int, l *slog.Logger) ([]controllers.Animal, error) {
+	req := repos.DbReq{Query: animalSelect + " WHERE owner_doc_id=$1 ORDER BY name, doc_id", Args: append(make([]any, 0), ownerDocId)}
+
+	return p.animalQuery(ctx, req, l)
+}
+
+// AnimalUpdate overwrites all fields of an existing animal record
+func (p *PgSqlDB) AnimalUpdate(ctx context.Context, a controllers.Animal, l *slog.Logger) (controllers.Animal, error) {
+	before, err := p.AnimalGet(ctx, a.DocId, l)
+	if err != nil {
+		return controllers.Animal{}, err
+	}
+
+	req := repos.DbReq{
+		Query: "UPDATE animal SET doc_type=$1, name=$2, birth_date=$3::date, animal_type=$4, breed=$5, owner_doc_id=$6 WHERE doc_id=$7",
+		Args:  append(make([]any, 0), a.DocType, a.Name, a.BirthDate, a.AnimalType, a.Breed, a.OwnerDocId, a.DocId),
+	}
+	// expansions are not stored
+	stored := a
+	stored.Owner, stored.Type = nil, nil
+	change := repos.Change{Entity: "animal", EntityId: strconv.Itoa(a.DocId), Action: repos.ActionUpdate, Before: before, After: stored}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.Animal{}, fmt.Errorf("failed to update animal: %w", err)
+	}
+	l.Debug("animal updated", "doc_id", a.DocId)
+
+	return p.AnimalGet(ctx, a.DocId, l)
+}
+
+// AnimalDelete removes animal record by doc_id
+func (p *PgSqlDB) AnimalDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	before, err := p.AnimalGet(ctx, docId, l)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM animal WHERE doc_id=$1", Args: append(make([]any, 0), docId)}
+	change := repos.Change{Entity: "animal", EntityId: strconv.Itoa(docId), Action: repos.ActionDelete, Before: before}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to delete animal: %w", err)
+	}
+	l.Debug("animal deleted", "doc_id", docId)
+
+	return nil
+}
+
+func (p *PgSqlDB) animalQuery(ctx context.Context, req repos.DbReq, l *slog.Logger) ([]controllers.Animal, error) {
+	var result []controllers.Animal
+
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a controllers.Animal
+		if err := rows.Scan(&a.DocId, &a.DocType, &a.Name, &a.BirthDate, &a.AnimalType, &a.Breed, &a.OwnerDocId); err != nil {
+			return nil, fmt.Errorf("cannot read query result %w", err)
+		}
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read query result %w", err)
+	}
+	l.Debug("query result", "animal", len(result))
+
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// AnimalTypeGetById searches AnimalType table by id and returns AnimalType object
+func (p *PgSqlDB) AnimalTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.AnimalType, error) {
+	req := repos.DbReq{Query: "SELECT id, type from animal_type WHERE id=$1", Args: append(make([]any, 0), id)}
+
+	result, err := invokeAnimalTypeRequest(ctx, req, p, l)
+	if err != nil {
+		return result, fmt.Errorf("animal_type id %d: %w", id, err)
+	}
+	return result, nil
+}
+
+// AnimalTypeGetByType searches AnimalType table by type and returns AnimalType object
+func (p *PgSqlDB) AnimalTypeGetByType(ctx context.Context, animalType string, l *slog.Logger) (controllers.AnimalType, error) {
+	req := repos.DbReq{Query: "SELECT id, type from animal_type WHERE type=$1", Args: append(make([]any, 0), animalType)}
+
+	result, err := invokeAnimalTypeRequest(ctx, req, p, l)
+	if err != nil {
+		return result, fmt.Errorf("animal_type %s: %w", animalType, err)
+	}
+	return result, nil
+}
+
+// AnimalTypeGetByIds searches AnimalType table by every id with a single query
+func (p *PgSqlDB) AnimalTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.AnimalType, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(ids))
+	for _, val := range ids {
+		args = append(args, val)
+	}
+	req := repos.DbReq{Query: "SELECT id, type from animal_type WHERE id IN (" + placeholders(len(ids)) + ")", Args: args}
+
+	found, err := invokeAnimalTypeBatchRequest(ctx, req, p, l)
+	if err != nil {
+		return nil, fmt.Errorf("animal_type ids %v: %w", ids, err)
+	}
+	byId := make(map[int]controllers.AnimalType, len(found))
+	for _, val := range found {
+		byId[val.Id] = val
+	}
+	result := make([]controllers.AnimalType, 0, len(ids))
+	for _, val := range ids {
+		result = append(result, byId[val])
+	}
+	return result, nil
+}
+
+// AnimalTypeGetByTypes searches AnimalType table by every type with a single query
+func (p *PgSqlDB) AnimalTypeGetByTypes(ctx context.Context, types []string, l *slog.Logger) ([]controllers.AnimalType, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(types))
+	for _, val := range types {
+		args = append(args, val)
+	}
+	req := repos.DbReq{Query: "SELECT id, type from animal_type WHERE type IN (" + placeholders(len(types)) + ")", Args: args}
+
+	found, err := invokeAnimalTypeBatchRequest(ctx, req, p, l)
+	if err != nil {
+		return nil, fmt.Errorf("animal_type types %v: %w", types, err)
+	}
+	byType := make(map[string]controllers.AnimalType, len(found))
+	for _, val := range found {
+		byType[val.Type] = val
+	}
+	result := make([]controllers.AnimalType, 0, len(types))
+	for _, val := range types {
+		result = append(result, byType[val])
+	}
+	return result, nil
+}
+
+// invokeAnimalTypeRequest runs query expected to yield a single AnimalType. Returns controllers.ErrNotFound on empty result
+func invokeAnimalTypeRequest(ctx context.Context, req repos.DbReq, p *PgSqlDB, l *slog.Logger) (controllers.AnimalType, error) {
+	var result controllers.AnimalType
+
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		if i > 0 {
+			return controllers.AnimalType{}, fmt.Errorf("query to dict table yielded more than one result")
+		}
+		if err := rows.Scan(&result.Id, &result.Type); err != nil {
+			return controllers.AnimalType{}, fmt.Errorf("cannot read query result: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return controllers.AnimalType{}, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "id", result.Id, "animal_type", result.Type)
+
+	if result.Id == 0 {
+		return result, controllers.ErrNotFound
+	}
+	return result, nil
+}
+
+// invokeAnimalTypeBatchRequest runs query yielding any number of AnimalType records
+func invokeAnimalTypeBatchRequest(ctx context.Context, req repos.DbReq, p *PgSqlDB, l *slog.Logger) ([]controllers.AnimalType, error) {
+	var result []controllers.AnimalType
+
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a controllers.AnimalType
+		if err := rows.Scan(&a.Id, &a.Type); err != nil {
+			return nil, fmt.Errorf("cannot read query result: %w", err)
+		}
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "animal_type", len(result))
+
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+)
+
+// AnimalTypeCreate inserts new record into AnimalType table. In case Id is 0 it is assigned by the identity of the column
+func (p *PgSqlDB) AnimalTypeCreate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	// type is used as a lookup key, so it must stay unique
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM animal_type WHERE type=$1 OR id=$2", Args: append(make([]any, 0), a.Type, a.Id)})
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	if found {
+		return controllers.AnimalType{}, fmt.Errorf("animal_type [%d %s]: %w", a.Id, a.Type, controllers.ErrAlreadyExists)
+	}
+
+	if a.Id == 0 {
+		req := repos.DbReq{Query: "INSERT INTO animal_type (type) VALUES ($1) RETURNING id", Args: append(make([]any, 0), a.Type)}
+		change := repos.Change{Entity: "animal_type", Action: repos.ActionCreate, After: map[string]any{"Type": a.Type}}
+		id, err := p.create(ctx, req, change)
+		if err != nil {
+			return controllers.AnimalType{}, fmt.Errorf("failed to create animal_type: %w", err)
+		}
+		l.Debug("animal_type created", "id", id, "animal_type", a.Type)
+		return p.AnimalTypeGetById(ctx, id, l)
+	}
+
+	// explicit ids do not advance the identity, so it is moved past them
+	rs := []repos.DbReq{
+		{Query: "INSERT INTO animal_type (id, type) VALUES ($1, $2)", Args: append(make([]any, 0), a.Id, a.Type)},
+		{Query: "SELECT setval(pg_get_serial_sequence('animal_type', 'id'), (SELECT MAX(id) FROM animal_type))"},
+	}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(a.Id), Action: repos.ActionCreate, After: a}
+	if err := p.change(ctx, rs, change); err != nil {
+		return controllers.AnimalType{}, fmt.Errorf("failed to create animal_type: %w", err)
+	}
+	l.Debug("animal_type created", "animal_type", a.Type)
+
+	return p.AnimalTypeGetByType(ctx, a.Type, l)
+}
+
+// AnimalTypeUpdate overwrites type of an existing AnimalType record
+func (p *PgSqlDB) AnimalTypeUpdate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	before, err := p.AnimalTypeGetById(ctx, a.Id, l)
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM animal_type WHERE type=$1 AND id<>$2", Args: append(make([]any, 0), a.Type, a.Id)})
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	if found {
+		return controllers.AnimalType{}, fmt.Errorf("animal_type %s: %w", a.Type, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "UPDATE animal_type SET type=$1 WHERE id=$2", Args: append(make([]any, 0), a.Type, a.Id)}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(a.Id), Action: repos.ActionUpdate, Before: before, After: a}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.AnimalType{}, fmt.Errorf("failed to update animal_type: %w", err)
+	}
+	l.Debug("animal_type updated", "id", a.Id, "animal_type", a.Type)
+
+	return p.AnimalTypeGetById(ctx, a.Id, l)
+}
+
+// AnimalTypeDelete removes AnimalType record by id
+func (p *PgSqlDB) AnimalTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	before, err := p.AnimalTypeGetById(ctx, id, l)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM animal_type WHERE id=$1", Args: append(make([]any, 0), id)}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(id), Action: repos.ActionDelete, Before: before}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to delete animal_type: %w", err)
+	}
+	l.Debug("animal_type deleted", "id", id)
+
+	return nil
+}
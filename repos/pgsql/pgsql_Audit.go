@@ -0,0 +1,111 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/lg"
+	"mis-catanddog/repos"
+	"strconv"
+	"time"
+)
+
+// auditInsert starts the audit_log insert, entity_id goes last
+const auditInsert = "INSERT INTO audit_log (actor, request_id, entity, action, before, after, entity_id)"
+
+// auditSelect is shared by all audit_log queries. at is formatted the way SQLite stores it
+const auditSelect = `SELECT id, to_char(at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.MS"Z"'), actor, request_id, entity, entity_id, action, before::text, after::text FROM audit_log`
+
+// auditListColumns are list columns of the audit log
+var auditListColumns = map[string]string{
+	"id":         "id",
+	"date":       "(at AT TIME ZONE 'UTC')::date",
+	"actor":      "actor",
+	"request_id": "request_id",
+	"entity":     "entity",
+	"entity_id":  "entity_id",
+	"action":     "action",
+}
+
+// change runs rs along with the audit_log record of c in one transaction. Every write of the repos goes
+// through it, so the data never changes without a trace
+func (p *PgSqlDB) change(ctx context.Context, rs []repos.DbReq, c repos.Change) error {
+	args, err := repos.AuditArgs(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: auditInsert + " VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7)", Args: append(args, c.EntityId)}
+	if c.EntityId == "" {
+		// the id is assigned by the insert that runs earlier in the same transaction
+		req = repos.DbReq{
+			Query: auditInsert + " SELECT $1, $2, $3, $4, $5::jsonb, $6::jsonb, CAST(id AS text) FROM " + c.Entity + " WHERE " + c.LookupColumn + "=$7",
+			Args:  append(args, c.LookupValue),
+		}
+	}
+	return p.exec(ctx, append(rs, req))
+}
+
+// create runs req inserting a record which id is assigned by the db along with the audit_log record of c
+// in a single statement. req must end with RETURNING id. Returns the assigned id
+func (p *PgSqlDB) create(ctx context.Context, req repos.DbReq, c repos.Change) (int, error) {
+	args, err := repos.AuditArgs(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+
+	var n = len(req.Args)
+	var query = "WITH created AS (" + req.Query + ") " + auditInsert +
+		fmt.Sprintf(" SELECT $%d, $%d, $%d, $%d, $%d::jsonb, $%d::jsonb, CAST(id AS text) FROM created RETURNING entity_id", n+1, n+2, n+3, n+4, n+5, n+6)
+	start := time.Now()
+	var id string
+	err = p.db.QueryRowContext(ctx, query, append(req.Args, args...)...).Scan(&id)
+	lg.FromContext(ctx, slog.Default()).Debug("query", "Query", query, "Duration", time.Since(start))
+	if err != nil {
+		return 0, fmt.Errorf("failed query [%s]: %w", query, violation(unavailable(err)))
+	}
+	return strconv.Atoi(id)
+}
+
+// AuditList returns a page of audit_log records
+func (p *PgSqlDB) AuditList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AuditEntry, []string, error) {
+	var result []controllers.AuditEntry
+
+	req, err := repos.ListQuery(auditSelect, auditListColumns, controllers.AuditListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("audit_log list: bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e controllers.AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.Id, &e.At, &e.Actor, &e.RequestId, &e.Entity, &e.EntityId, &e.Action, &before, &after); err != nil {
+			return nil, nil, fmt.Errorf("audit_log list: cannot read query result: %w", err)
+		}
+		e.Before, e.After = rawJSON(before), rawJSON(after)
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("audit_log list: cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "audit_log", len(result))
+
+	items, after := repos.ListPage(result, q, repos.AuditListValues)
+	return items, after, nil
+}
+
+// rawJSON converts nullable JSON column to the raw message, NULL becomes nil
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
@@ -0,0 +1,95 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// listDialect uses numbered placeholders and casts date arguments
+var listDialect = repos.ListDialect{
+	Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	Date:        func(ph string) string { return ph + "::date" },
+}
+
+// list columns of every list endpoint
+var (
+	docTypeListColumns    = map[string]string{"id": "id", "doc": "doc"}
+	animalTypeListColumns = map[string]string{"id": "id", "type": "type"}
+	humanListColumns      = map[string]string{"doc_id": "doc_id", "doc_type": "doc_type", "first_name": "first_name", "last_name": "last_name", "birth_date": "birth_date"}
+	animalListColumns     = map[string]string{"doc_id": "doc_id", "doc_type": "doc_type", "name": "name", "birth_date": "birth_date", "animal_type": "animal_type", "breed": "breed", "owner_doc_id": "owner_doc_id"}
+)
+
+// DocTypeList returns a page of doc_type records
+func (p *PgSqlDB) DocTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.DocType, []string, error) {
+	req, err := repos.ListQuery("SELECT id, doc FROM doc_type", docTypeListColumns, controllers.DocTypeListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := invokeBatchRequest(ctx, req, p, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("doc_type list: %w", err)
+	}
+	items, after := repos.ListPage(result, q, repos.DocTypeListValues)
+	return items, after, nil
+}
+
+// AnimalTypeList returns a page of animal_type records
+func (p *PgSqlDB) AnimalTypeList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AnimalType, []string, error) {
+	var result []controllers.AnimalType
+
+	req, err := repos.ListQuery("SELECT id, type FROM animal_type", animalTypeListColumns, controllers.AnimalTypeListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("animal_type list: bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a controllers.AnimalType
+		if err := rows.Scan(&a.Id, &a.Type); err != nil {
+			return nil, nil, fmt.Errorf("animal_type list: cannot read query result: %w", err)
+		}
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("animal_type list: cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "animal_type", len(result))
+
+	items, after := repos.ListPage(result, q, repos.AnimalTypeListValues)
+	return items, after, nil
+}
+
+// HumanList returns a page of human records
+func (p *PgSqlDB) HumanList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.Human, []string, error) {
+	req, err := repos.ListQuery(humanSelect, humanListColumns, controllers.HumanListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := p.humanQuery(ctx, req, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("human list: %w", err)
+	}
+	items, after := repos.ListPage(result, q, repos.HumanListValues)
+	return items, after, nil
+}
+
+// AnimalList returns a page of animal records
+func (p *PgSqlDB) AnimalList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.Animal, []string, error) {
+	req, err := repos.ListQuery(animalSelect, animalListColumns, controllers.AnimalListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := p.animalQuery(ctx, req, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("animal list: %w", err)
+	}
+	items, after := repos.ListPage(result, q, repos.AnimalListValues)
+	return items, after, nil
+}
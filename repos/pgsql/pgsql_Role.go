@@ -0,0 +1,81 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// SubjectPermissions returns distinct permissions of every role granted to the subject
+func (p *PgSqlDB) SubjectPermissions(ctx context.Context, subject string, l *slog.Logger) ([]string, error) {
+	var result []string
+	req := repos.DbReq{Query: "SELECT DISTINCT rp.permission FROM subject_role sr JOIN role_permission rp ON rp.role=sr.role WHERE sr.subject=$1 ORDER BY rp.permission", Args: append(make([]any, 0), subject)}
+
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return nil, fmt.Errorf("cannot read query result: %w", err)
+		}
+		result = append(result, val)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "subject", subject, "permissions", result)
+	return result, nil
+}
+
+// RoleGrant grants the existing role to the subject
+func (p *PgSqlDB) RoleGrant(ctx context.Context, subject string, role string, l *slog.Logger) error {
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM role WHERE name=$1", Args: append(make([]any, 0), role)})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("role %s: %w", role, controllers.ErrNotFound)
+	}
+	found, err = p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM subject_role WHERE subject=$1 AND role=$2", Args: append(make([]any, 0), subject, role)})
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("role %s of %s: %w", role, subject, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "INSERT INTO subject_role (subject, role) VALUES ($1, $2)", Args: append(make([]any, 0), subject, role)}
+	change := repos.Change{Entity: "subject_role", EntityId: subject, Action: repos.ActionCreate, After: map[string]any{"Subject": subject, "Role": role}}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+	l.Debug("role granted", "subject", subject, "role", role)
+
+	return nil
+}
+
+// RoleRevoke takes the role away from the subject
+func (p *PgSqlDB) RoleRevoke(ctx context.Context, subject string, role string, l *slog.Logger) error {
+	found, err := p.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM subject_role WHERE subject=$1 AND role=$2", Args: append(make([]any, 0), subject, role)})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("role %s of %s: %w", role, subject, controllers.ErrNotFound)
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM subject_role WHERE subject=$1 AND role=$2", Args: append(make([]any, 0), subject, role)}
+	change := repos.Change{Entity: "subject_role", EntityId: subject, Action: repos.ActionDelete, Before: map[string]any{"Subject": subject, "Role": role}}
+	if err := p.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	l.Debug("role revoked", "subject", subject, "role", role)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package pgsql
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"io/fs"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrations returns schema migrations for the migrate package
+func (p *PgSqlDB) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrations, "migrations")
+	return sub
+}
+
+// SeedDictTables fills dictionary tables that never held a record. Tables having records or a history
+// in audit_log are skipped as a whole, so records deleted or renamed since are not brought back
+// and entries added to seed later are not applied either.
+// Records are created the way the API does, so every one of them is audited
+func (p *PgSqlDB) SeedDictTables(ctx context.Context, seed repos.DictSeed, l *slog.Logger) error {
+	ok, err := p.seedable(ctx, "doc_type")
+	if err != nil {
+		return fmt.Errorf("failed to seed doc_type: %w", err)
+	}
+	if !ok {
+		l.Info("doc_type holds records or had them, not seeded")
+	}
+	for i := 0; ok && i < len(seed.DocType); i++ {
+		val := seed.DocType[i]
+		// a concurrent seeding may have created the record already
+		_, err := p.DocTypeCreate(ctx, controllers.DocType{Id: val.Id, Doc: val.Doc}, l)
+		if err != nil && !errors.Is(err, controllers.ErrAlreadyExists) {
+			return fmt.Errorf("failed to seed doc_type [%d %s]: %w", val.Id, val.Doc, err)
+		}
+	}
+
+	ok, err = p.seedable(ctx, "animal_type")
+	if err != nil {
+		return fmt.Errorf("failed to seed animal_type: %w", err)
+	}
+	if !ok {
+		l.Info("animal_type holds records or had them, not seeded")
+	}
+	for i := 0; ok && i < len(seed.AnimalType); i++ {
+		val := seed.AnimalType[i]
+		_, err := p.AnimalTypeCreate(ctx, controllers.AnimalType{Id: val.Id, Type: val.Type}, l)
+		if err != nil && !errors.Is(err, controllers.ErrAlreadyExists) {
+			return fmt.Errorf("failed to seed animal_type [%d %s]: %w", val.Id, val.Type, err)
+		}
+	}
+	return nil
+}
+
+// seedable reports whether table never held a record. table is one of the dictionary tables
+func (p *PgSqlDB) seedable(ctx context.Context, table string) (bool, error) {
+	found, err := p.exists(ctx, repos.DbReq{
+		Query: "SELECT 1 FROM " + table + " UNION ALL SELECT 1 FROM audit_log WHERE entity=$1",
+		Args:  append(make([]any, 0), table),
+	})
+	return !found, err
+}
+
+// unavailable marks connection failures and server shutdowns as temporary errors
+func unavailable(err error) error {
+	var pgErr *pgconn.PgError
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) || pgconn.Timeout(err) {
+		return fmt.Errorf("%w: %w", controllers.ErrUnavailable, err)
+	}
+	// class 08 is connection exception, 57P0x is operator intervention, 53300 is too many connections
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P0") || pgErr.Code == "53300") {
+		return fmt.Errorf("%w: %w", controllers.ErrUnavailable, err)
+	}
+	return repos.Unavailable(err)
+}
+
+// violation marks errors of broken foreign keys and duplicated keys,
+// 23503 is foreign_key_violation, 23505 is unique_violation
+func violation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return fmt.Errorf("%w: %w", controllers.ErrReference, err)
+	}
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%w: %w", controllers.ErrAlreadyExists, err)
+	}
+	return err
+}
+
+// exists reports whether the query yields at least one row
+func (p *PgSqlDB) exists(ctx context.Context, req repos.DbReq) (bool, error) {
+	rows, err := p.Get(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	return rows.Next(), rows.Err()
+}
+
+// nullString converts empty string to NULL
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// placeholders returns n comma separated query placeholders $1..$n for the IN clause
+func placeholders(n int) string {
+	var ph = make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		ph = append(ph, fmt.Sprintf("$%d", i))
+	}
+	return strings.Join(ph, ", ")
+}
+
+// likePrefix escapes LIKE wildcards in s and turns it into a prefix pattern
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
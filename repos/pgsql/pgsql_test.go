@@ -0,0 +1,40 @@
+package pgsql
+
+import (
+	"context"
+	"log/slog"
+	"mis-catanddog/repos/migrate"
+	"mis-catanddog/repos/repotest"
+	"os"
+	"testing"
+	"time"
+)
+
+// testUriEnv names the variable holding URI of a disposable database. The test drops all tables in it.
+// CI provides it from a postgres service, there the suite fails instead of being skipped
+const testUriEnv = "PGSQL_TEST_URI"
+
+func TestPgSqlDB(t *testing.T) {
+	var db = &PgSqlDB{}
+
+	uri := os.Getenv(testUriEnv)
+	if uri == "" && os.Getenv("CI") != "" {
+		t.Fatalf("%s is not set, the pgsql suite must run in CI", testUriEnv)
+	}
+	if uri == "" {
+		t.Skipf("%s is not set", testUriEnv)
+	}
+
+	if err := db.New(uri, 5*time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+	if _, err := db.db.ExecContext(context.Background(), "DROP TABLE IF EXISTS audit_log, subject_role, role_permission, role, api_key, animal, human, animal_type, doc_type, schema_migrations CASCADE"); err != nil {
+		t.Fatalf("failed to clean db: %s", err.Error())
+	}
+	if _, err := migrate.Up(context.Background(), db, slog.Default()); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+
+	repotest.Run(t, db)
+}
@@ -0,0 +1,605 @@
+// Package repotest holds behavioral tests shared by every repos.DB backend
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"mis-catanddog/repos/migrate"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Backend lists everything the suite expects from a backend under test
+type Backend interface {
+	migrate.Source
+	controllers.DocTypeGetter
+	controllers.DocTypeWriter
+	controllers.AnimalTypeGetter
+	controllers.AnimalTypeWriter
+	controllers.HumanRepo
+	controllers.AnimalRepo
+	controllers.APIKeyRepo
+	controllers.RoleRepo
+	controllers.AuditReader
+	repos.Seeder
+}
+
+// Seed is the dictionary content the suite expects
+var Seed = repos.DictSeed{
+	DocType: []repos.SeedDocType{
+		{Id: 1, Doc: "passport"},
+		{Id: 2, Doc: "veterinary passport"},
+		{Id: 3, Doc: "military passport"},
+	},
+	AnimalType: []repos.SeedAnimalType{
+		{Id: 1, Type: "dog"},
+		{Id: 2, Type: "cat"},
+	},
+}
+
+// Run runs the suite against db. db must hold the migrated schema only
+func Run(t *testing.T, db Backend) {
+	var l = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("Seed", func(t *testing.T) { testSeed(t, db, l) })
+	t.Run("DocType", func(t *testing.T) { testDocType(t, db, l) })
+	t.Run("AnimalType", func(t *testing.T) { testAnimalType(t, db, l) })
+	t.Run("Human", func(t *testing.T) { testHuman(t, db, l) })
+	t.Run("Animal", func(t *testing.T) { testAnimal(t, db, l) })
+	t.Run("List", func(t *testing.T) { testList(t, db, l) })
+	t.Run("APIKey", func(t *testing.T) { testAPIKey(t, db, l) })
+	t.Run("Role", func(t *testing.T) { testRole(t, db, l) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, db, l) })
+}
+
+func testSeed(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+
+	if err := db.SeedDictTables(ctx, Seed, l); err != nil {
+		t.Fatalf("SeedDictTables: %v", err)
+	}
+	// seeded records are audited as any other change
+	q := controllers.ListQuery{Limit: 100, Sort: []controllers.SortField{{Field: "id"}}, Filters: []controllers.Filter{{Field: "action", Op: controllers.OpEq, Value: repos.ActionCreate}}}
+	if page, _, err := db.AuditList(ctx, q, l); err != nil || len(page) != len(Seed.DocType)+len(Seed.AnimalType) || page[0].Actor != repos.SystemActor {
+		t.Fatalf("audit of SeedDictTables: got %+v, %v", page, err)
+	}
+	if _, err := db.DocTypeUpdate(ctx, controllers.DocType{Id: 3, Doc: "military id"}, l); err != nil {
+		t.Fatalf("DocTypeUpdate: %v", err)
+	}
+
+	// seeding again must neither fail nor overwrite edited records
+	if err := db.SeedDictTables(ctx, Seed, l); err != nil {
+		t.Fatalf("second SeedDictTables: %v", err)
+	}
+	if d, err := db.DocTypeGetById(ctx, 3, l); err != nil || d.Doc != "military id" {
+		t.Fatalf("SeedDictTables overwrote edited record: got %+v, %v", d, err)
+	}
+
+	// nor bring deleted records back
+	if err := db.AnimalTypeDelete(ctx, 2, l); err != nil {
+		t.Fatalf("AnimalTypeDelete: %v", err)
+	}
+	if err := db.SeedDictTables(ctx, Seed, l); err != nil {
+		t.Fatalf("third SeedDictTables: %v", err)
+	}
+	if a, err := db.AnimalTypeGetById(ctx, 2, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("SeedDictTables resurrected deleted record: got %+v, %v", a, err)
+	}
+	if _, err := db.AnimalTypeCreate(ctx, controllers.AnimalType{Id: 2, Type: "cat"}, l); err != nil {
+		t.Fatalf("AnimalTypeCreate: %v", err)
+	}
+
+	if _, err := db.DocTypeUpdate(ctx, controllers.DocType{Id: 3, Doc: "military passport"}, l); err != nil {
+		t.Fatalf("DocTypeUpdate: %v", err)
+	}
+}
+
+func testDocType(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+
+	if d, err := db.DocTypeGetById(ctx, 1, l); err != nil || d.Id != 1 || d.Doc != "passport" {
+		t.Fatalf("DocTypeGetById(1): got %+v, %v, expected passport", d, err)
+	}
+	if d, err := db.DocTypeGetByDoc(ctx, "veterinary passport", l); err != nil || d.Id != 2 {
+		t.Fatalf("DocTypeGetByDoc(veterinary passport): got %+v, %v, expected id 2", d, err)
+	}
+	if d, err := db.DocTypeGetById(ctx, 100, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("DocTypeGetById(100): got %+v, %v, expected ErrNotFound", d, err)
+	}
+	if d, err := db.DocTypeGetByDoc(ctx, "driving licence", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("DocTypeGetByDoc(driving licence): got %+v, %v, expected ErrNotFound", d, err)
+	}
+
+	// batch keeps request order, duplicates and misses
+	ds, err := db.DocTypeGetByIds(ctx, []int{3, 100, 1, 3}, l)
+	if err != nil || len(ds) != 4 || ds[0].Doc != "military passport" || ds[1].Id != 0 || ds[2].Doc != "passport" || ds[3].Id != 3 {
+		t.Fatalf("DocTypeGetByIds(3, 100, 1, 3): got %+v, %v", ds, err)
+	}
+	ds, err = db.DocTypeGetByDocs(ctx, []string{"veterinary passport", "driving licence", "passport"}, l)
+	if err != nil || len(ds) != 3 || ds[0].Id != 2 || ds[1].Id != 0 || ds[2].Id != 1 {
+		t.Fatalf("DocTypeGetByDocs(veterinary passport, driving licence, passport): got %+v, %v", ds, err)
+	}
+	if ds, err = db.DocTypeGetByIds(ctx, nil, l); err != nil || len(ds) != 0 {
+		t.Fatalf("DocTypeGetByIds(nil): got %+v, %v", ds, err)
+	}
+
+	created, err := db.DocTypeCreate(ctx, controllers.DocType{Doc: "foreign passport"}, l)
+	if err != nil || created.Id == 0 || created.Doc != "foreign passport" {
+		t.Fatalf("DocTypeCreate: got %+v, %v", created, err)
+	}
+	if _, err := db.DocTypeCreate(ctx, controllers.DocType{Doc: "foreign passport"}, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("DocTypeCreate duplicate: got %v, expected ErrAlreadyExists", err)
+	}
+	if d, err := db.DocTypeCreate(ctx, controllers.DocType{Id: 10, Doc: "birth certificate"}, l); err != nil || d.Id != 10 {
+		t.Fatalf("DocTypeCreate with id: got %+v, %v", d, err)
+	}
+
+	updated, err := db.DocTypeUpdate(ctx, controllers.DocType{Id: created.Id, Doc: "international passport"}, l)
+	if err != nil || updated.Doc != "international passport" {
+		t.Fatalf("DocTypeUpdate: got %+v, %v", updated, err)
+	}
+	if _, err := db.DocTypeUpdate(ctx, controllers.DocType{Id: created.Id, Doc: "passport"}, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("DocTypeUpdate to existing doc: got %v, expected ErrAlreadyExists", err)
+	}
+	if _, err := db.DocTypeUpdate(ctx, controllers.DocType{Id: 100, Doc: "x"}, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("DocTypeUpdate missing: got %v, expected ErrNotFound", err)
+	}
+
+	for _, id := range []int{created.Id, 10} {
+		if err := db.DocTypeDelete(ctx, id, l); err != nil {
+			t.Fatalf("DocTypeDelete(%d): %v", id, err)
+		}
+	}
+	if err := db.DocTypeDelete(ctx, created.Id, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("DocTypeDelete deleted: got %v, expected ErrNotFound", err)
+	}
+
+	// ids assigned by the db do not collide under concurrent inserts
+	var wg sync.WaitGroup
+	var results = make(chan controllers.DocType, 8)
+	var errs = make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d, err := db.DocTypeCreate(ctx, controllers.DocType{Doc: "permit " + strconv.Itoa(i)}, l)
+			results <- d
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent DocTypeCreate: %v", err)
+		}
+	}
+	var ids = map[int]bool{}
+	for d := range results {
+		ids[d.Id] = true
+		if err := db.DocTypeDelete(ctx, d.Id, l); err != nil {
+			t.Fatalf("DocTypeDelete(%d): %v", d.Id, err)
+		}
+	}
+	if len(ids) != 8 {
+		t.Fatalf("concurrent DocTypeCreate: got ids %v", ids)
+	}
+}
+
+func testAnimalType(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+
+	if a, err := db.AnimalTypeGetById(ctx, 1, l); err != nil || a.Id != 1 || a.Type != "dog" {
+		t.Fatalf("AnimalTypeGetById(1): got %+v, %v; expected dog", a, err)
+	}
+	if a, err := db.AnimalTypeGetByType(ctx, "cat", l); err != nil || a.Id != 2 {
+		t.Fatalf("AnimalTypeGetByType(cat): got %+v, %v; expected id 2", a, err)
+	}
+
+	// batch keeps request order, duplicates and misses
+	as, err := db.AnimalTypeGetByIds(ctx, []int{2, 100, 1, 2}, l)
+	if err != nil || len(as) != 4 || as[0].Type != "cat" || as[1].Id != 0 || as[2].Type != "dog" || as[3].Id != 2 {
+		t.Fatalf("AnimalTypeGetByIds(2, 100, 1, 2): got %+v, %v", as, err)
+	}
+	as, err = db.AnimalTypeGetByTypes(ctx, []string{"dog", "parrot", "cat"}, l)
+	if err != nil || len(as) != 3 || as[0].Id != 1 || as[1].Id != 0 || as[2].Id != 2 {
+		t.Fatalf("AnimalTypeGetByTypes(dog, parrot, cat): got %+v, %v", as, err)
+	}
+
+	created, err := db.AnimalTypeCreate(ctx, controllers.AnimalType{Type: "parrot"}, l)
+	if err != nil || created.Id == 0 {
+		t.Fatalf("AnimalTypeCreate: got %+v, %v", created, err)
+	}
+	if _, err := db.AnimalTypeCreate(ctx, controllers.AnimalType{Type: "dog"}, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("AnimalTypeCreate duplicate: got %v, expected ErrAlreadyExists", err)
+	}
+	if a, err := db.AnimalTypeUpdate(ctx, controllers.AnimalType{Id: created.Id, Type: "hamster"}, l); err != nil || a.Type != "hamster" {
+		t.Fatalf("AnimalTypeUpdate: got %+v, %v", a, err)
+	}
+	if err := db.AnimalTypeDelete(ctx, created.Id, l); err != nil {
+		t.Fatalf("AnimalTypeDelete: %v", err)
+	}
+	if _, err := db.AnimalTypeGetById(ctx, created.Id, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalTypeGetById deleted: got %v, expected ErrNotFound", err)
+	}
+}
+
+func testHuman(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+	var h = controllers.Human{DocId: 1001, DocType: 1, FirstName: "Alexey", MiddleName: "Alexandrovich", LastName: "Ivanov", BirthDate: "1992-03-30"}
+
+	created, err := db.HumanCreate(ctx, h, l)
+	if err != nil || created != h {
+		t.Fatalf("HumanCreate: got %+v, %v; expected %+v", created, err, h)
+	}
+	if _, err := db.HumanCreate(ctx, h, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("HumanCreate duplicate: got %v, expected ErrAlreadyExists", err)
+	}
+	other := controllers.Human{DocId: 1002, DocType: 1, FirstName: "Maria", LastName: "Iv_nova", BirthDate: "1994-10-12"}
+	if _, err := db.HumanCreate(ctx, other, l); err != nil {
+		t.Fatalf("HumanCreate: %v", err)
+	}
+
+	if got, err := db.HumanGet(ctx, h.DocId, l); err != nil || got != h {
+		t.Fatalf("HumanGet: got %+v, %v; expected %+v", got, err, h)
+	}
+	if _, err := db.HumanGet(ctx, 9999, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("HumanGet missing: got %v, expected ErrNotFound", err)
+	}
+
+	found, err := db.HumanSearch(ctx, controllers.HumanFilter{LastName: "Iv"}, l)
+	if err != nil || len(found) != 2 {
+		t.Fatalf("HumanSearch(Iv): got %+v, %v; expected 2 records", found, err)
+	}
+	// wildcards in the filter must be matched literally
+	found, err = db.HumanSearch(ctx, controllers.HumanFilter{LastName: "Iv_"}, l)
+	if err != nil || len(found) != 1 || found[0].DocId != other.DocId {
+		t.Fatalf("HumanSearch(Iv_): got %+v, %v; expected %d only", found, err, other.DocId)
+	}
+	found, err = db.HumanSearch(ctx, controllers.HumanFilter{BirthDate: "1992-03-30", DocType: 1}, l)
+	if err != nil || len(found) != 1 || found[0].DocId != h.DocId {
+		t.Fatalf("HumanSearch(birth date): got %+v, %v; expected %d only", found, err, h.DocId)
+	}
+
+	h.MiddleName = ""
+	h.LastName = "Petrov"
+	if got, err := db.HumanUpdate(ctx, h, l); err != nil || got != h {
+		t.Fatalf("HumanUpdate: got %+v, %v; expected %+v", got, err, h)
+	}
+	if _, err := db.HumanUpdate(ctx, controllers.Human{DocId: 9999, DocType: 1, FirstName: "a", LastName: "b", BirthDate: "2000-01-01"}, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("HumanUpdate missing: got %v, expected ErrNotFound", err)
+	}
+
+	if err := db.HumanDelete(ctx, other.DocId, l); err != nil {
+		t.Fatalf("HumanDelete: %v", err)
+	}
+	if err := db.HumanDelete(ctx, other.DocId, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("HumanDelete deleted: got %v, expected ErrNotFound", err)
+	}
+}
+
+func testAnimal(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+	var owner = controllers.Human{DocId: 2001, DocType: 1, FirstName: "Ivan", LastName: "Sidorov", BirthDate: "1980-01-01"}
+	var a = controllers.Animal{DocId: 3001, DocType: 2, Name: "Rex", BirthDate: "2020-05-01", AnimalType: 1, Breed: "husky", OwnerDocId: owner.DocId}
+
+	if _, err := db.HumanCreate(ctx, owner, l); err != nil {
+		t.Fatalf("HumanCreate: %v", err)
+	}
+
+	created, err := db.AnimalCreate(ctx, a, l)
+	if err != nil || created != a {
+		t.Fatalf("AnimalCreate: got %+v, %v; expected %+v", created, err, a)
+	}
+	if _, err := db.AnimalCreate(ctx, a, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("AnimalCreate duplicate: got %v, expected ErrAlreadyExists", err)
+	}
+	second := controllers.Animal{DocId: 3002, DocType: 2, Name: "Barsik", BirthDate: "2021-07-15", AnimalType: 2, Breed: "siamese", OwnerDocId: owner.DocId}
+	if _, err := db.AnimalCreate(ctx, second, l); err != nil {
+		t.Fatalf("AnimalCreate: %v", err)
+	}
+
+	if got, err := db.AnimalGet(ctx, a.DocId, l); err != nil || got != a {
+		t.Fatalf("AnimalGet: got %+v, %v; expected %+v", got, err, a)
+	}
+	if _, err := db.AnimalGet(ctx, 9999, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalGet missing: got %v, expected ErrNotFound", err)
+	}
+
+	// ordered by name
+	list, err := db.AnimalGetByOwner(ctx, owner.DocId, l)
+	if err != nil || len(list) != 2 || list[0].DocId != second.DocId || list[1].DocId != a.DocId {
+		t.Fatalf("AnimalGetByOwner: got %+v, %v", list, err)
+	}
+	if list, err := db.AnimalGetByOwner(ctx, 9999, l); err != nil || len(list) != 0 {
+		t.Fatalf("AnimalGetByOwner unknown owner: got %+v, %v", list, err)
+	}
+
+	// references are enforced both ways
+	if _, err := db.AnimalCreate(ctx, controllers.Animal{DocId: 3003, DocType: 2, Name: "Stray", BirthDate: "2022-01-01", AnimalType: 1, Breed: "mongrel", OwnerDocId: 9999}, l); !errors.Is(err, controllers.ErrReference) {
+		t.Fatalf("AnimalCreate of unknown owner: got %v, expected ErrReference", err)
+	}
+	if err := db.HumanDelete(ctx, owner.DocId, l); !errors.Is(err, controllers.ErrReference) {
+		t.Fatalf("HumanDelete of owner: got %v, expected ErrReference", err)
+	}
+	if err := db.AnimalTypeDelete(ctx, a.AnimalType, l); !errors.Is(err, controllers.ErrReference) {
+		t.Fatalf("AnimalTypeDelete in use: got %v, expected ErrReference", err)
+	}
+
+	a.Name = "Max"
+	a.Breed = "malamute"
+	if got, err := db.AnimalUpdate(ctx, a, l); err != nil || got != a {
+		t.Fatalf("AnimalUpdate: got %+v, %v; expected %+v", got, err, a)
+	}
+
+	for _, docId := range []int{a.DocId, second.DocId} {
+		if err := db.AnimalDelete(ctx, docId, l); err != nil {
+			t.Fatalf("AnimalDelete(%d): %v", docId, err)
+		}
+	}
+	if err := db.AnimalDelete(ctx, a.DocId, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalDelete deleted: got %v, expected ErrNotFound", err)
+	}
+}
+
+func testList(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+	var humans = []controllers.Human{
+		{DocId: 4001, DocType: 1, FirstName: "Anna", LastName: "Smirnova", BirthDate: "1990-01-01"},
+		{DocId: 4002, DocType: 1, FirstName: "Boris", LastName: "Kuznetsov", BirthDate: "1985-06-15"},
+		{DocId: 4003, DocType: 3, FirstName: "Vera", LastName: "Smirnova", BirthDate: "2001-11-30"},
+		{DocId: 4004, DocType: 1, FirstName: "Gleb", LastName: "Sm_rnov", BirthDate: "1999-12-31"},
+		{DocId: 4005, DocType: 1, FirstName: "Daria", LastName: "Popova", BirthDate: "1990-01-01"},
+	}
+	var own = controllers.Filter{Field: "doc_id", Op: controllers.OpGte, Value: "4001"}
+
+	for _, val := range humans {
+		if _, err := db.HumanCreate(ctx, val, l); err != nil {
+			t.Fatalf("HumanCreate: %v", err)
+		}
+	}
+
+	// pages of 2 by last_name never skip nor repeat records sharing the sort value
+	var got []int
+	var q = controllers.ListQuery{Limit: 2, Sort: []controllers.SortField{{Field: "last_name"}, {Field: "doc_id"}}, Filters: []controllers.Filter{own}}
+	for pages := 1; ; pages++ {
+		page, after, err := db.HumanList(ctx, q, l)
+		if err != nil || len(page) > 2 || pages > 3 {
+			t.Fatalf("HumanList page %d: got %+v, %v", pages, page, err)
+		}
+		for _, val := range page {
+			got = append(got, val.DocId)
+		}
+		if after == nil {
+			break
+		}
+		q.After = after
+	}
+	if !slices.Equal(got, []int{4002, 4005, 4004, 4001, 4003}) {
+		t.Fatalf("HumanList by last_name: got %v", got)
+	}
+
+	list := func(q controllers.ListQuery) []int {
+		t.Helper()
+		page, _, err := db.HumanList(ctx, q, l)
+		if err != nil {
+			t.Fatalf("HumanList(%+v): %v", q, err)
+		}
+		var result []int
+		for _, val := range page {
+			result = append(result, val.DocId)
+		}
+		return result
+	}
+	desc := []controllers.SortField{{Field: "birth_date", Desc: true}, {Field: "doc_id", Desc: true}}
+	if got := list(controllers.ListQuery{Limit: 10, Sort: desc, Filters: []controllers.Filter{own}}); !slices.Equal(got, []int{4003, 4004, 4005, 4001, 4002}) {
+		t.Fatalf("HumanList by -birth_date: got %v", got)
+	}
+	if got := list(controllers.ListQuery{Limit: 10, Sort: desc, Filters: []controllers.Filter{own}, After: []string{"1990-01-01", "4005"}}); !slices.Equal(got, []int{4001, 4002}) {
+		t.Fatalf("HumanList by -birth_date after 4005: got %v", got)
+	}
+	byKey := []controllers.SortField{{Field: "doc_id"}}
+	filters := []controllers.Filter{own, {Field: "last_name", Op: controllers.OpLike, Value: "Sm*"}, {Field: "birth_date", Op: controllers.OpLte, Value: "1999-12-31"}}
+	if got := list(controllers.ListQuery{Limit: 10, Sort: byKey, Filters: filters}); !slices.Equal(got, []int{4001, 4004}) {
+		t.Fatalf("HumanList filtered: got %v", got)
+	}
+	// wildcards other than '*' must be matched literally
+	filters = []controllers.Filter{own, {Field: "last_name", Op: controllers.OpLike, Value: "Sm_*"}}
+	if got := list(controllers.ListQuery{Limit: 10, Sort: byKey, Filters: filters}); !slices.Equal(got, []int{4004}) {
+		t.Fatalf("HumanList like Sm_*: got %v", got)
+	}
+	filters = []controllers.Filter{own, {Field: "doc_type", Op: controllers.OpEq, Value: "3"}}
+	if got := list(controllers.ListQuery{Limit: 10, Sort: byKey, Filters: filters}); !slices.Equal(got, []int{4003}) {
+		t.Fatalf("HumanList doc_type 3: got %v", got)
+	}
+	if _, _, err := db.HumanList(ctx, controllers.ListQuery{Limit: 10, Sort: []controllers.SortField{{Field: "last_name"}}}, l); err == nil {
+		t.Fatalf("HumanList without key in sort: expected error")
+	}
+
+	ds, after, err := db.DocTypeList(ctx, controllers.ListQuery{Limit: 2, Sort: []controllers.SortField{{Field: "doc"}, {Field: "id"}}}, l)
+	if err != nil || len(ds) != 2 || ds[0].Doc != "military passport" || ds[1].Doc != "passport" || !slices.Equal(after, []string{"passport", "1"}) {
+		t.Fatalf("DocTypeList by doc: got %+v, %v, %v", ds, after, err)
+	}
+	as, after, err := db.AnimalTypeList(ctx, controllers.ListQuery{Limit: 10, Sort: []controllers.SortField{{Field: "id", Desc: true}}}, l)
+	if err != nil || len(as) != 2 || as[0].Type != "cat" || after != nil {
+		t.Fatalf("AnimalTypeList by -id: got %+v, %v, %v", as, after, err)
+	}
+	animals, _, err := db.AnimalList(ctx, controllers.ListQuery{Limit: 10, Sort: []controllers.SortField{{Field: "doc_id"}}, Filters: []controllers.Filter{{Field: "owner_doc_id", Op: controllers.OpEq, Value: "4001"}}}, l)
+	if err != nil || len(animals) != 0 {
+		t.Fatalf("AnimalList of owner without animals: got %+v, %v", animals, err)
+	}
+
+	for _, val := range humans {
+		if err := db.HumanDelete(ctx, val.DocId, l); err != nil {
+			t.Fatalf("HumanDelete: %v", err)
+		}
+	}
+}
+
+func testAPIKey(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+
+	k, err := db.APIKeyCreate(ctx, controllers.APIKey{Name: "billing", Hash: "b1"}, l)
+	if err != nil || k.Id == 0 || k.Name != "billing" || k.Revoked {
+		t.Fatalf("APIKeyCreate: got %+v, %v", k, err)
+	}
+	if _, err := db.APIKeyCreate(ctx, controllers.APIKey{Name: "billing", Hash: "b2"}, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("APIKeyCreate of a taken name: got %v, expected ErrAlreadyExists", err)
+	}
+	if got, err := db.APIKeyGetByHash(ctx, "b1", l); err != nil || got != k {
+		t.Fatalf("APIKeyGetByHash: got %+v, %v, expected %+v", got, err, k)
+	}
+	if _, err := db.APIKeyGetByHash(ctx, "b2", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("APIKeyGetByHash of an unknown hash: got %v, expected ErrNotFound", err)
+	}
+
+	if err := db.APIKeyRevoke(ctx, "billing", l); err != nil {
+		t.Fatalf("APIKeyRevoke: %v", err)
+	}
+	if _, err := db.APIKeyGetByHash(ctx, "b1", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("APIKeyGetByHash of a revoked key: got %v, expected ErrNotFound", err)
+	}
+	if err := db.APIKeyRevoke(ctx, "billing", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("second APIKeyRevoke: got %v, expected ErrNotFound", err)
+	}
+}
+
+func testRole(t *testing.T, db Backend, l *slog.Logger) {
+	var ctx = context.Background()
+
+	if perms, err := db.SubjectPermissions(ctx, "nurse", l); err != nil || len(perms) != 0 {
+		t.Fatalf("SubjectPermissions of a subject without roles: got %v, %v", perms, err)
+	}
+	if err := db.RoleGrant(ctx, "nurse", "vet", l); err != nil {
+		t.Fatalf("RoleGrant: %v", err)
+	}
+	if err := db.RoleGrant(ctx, "nurse", "receptionist", l); err != nil {
+		t.Fatalf("RoleGrant: %v", err)
+	}
+	if err := db.RoleGrant(ctx, "nurse", "vet", l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("RoleGrant of a granted role: got %v, expected ErrAlreadyExists", err)
+	}
+	if err := db.RoleGrant(ctx, "nurse", "surgeon", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("RoleGrant of an unknown role: got %v, expected ErrNotFound", err)
+	}
+
+	// permissions shared by both roles are reported once
+	perms, err := db.SubjectPermissions(ctx, "nurse", l)
+	if err != nil || !slices.Contains(perms, "human:write") || !slices.Contains(perms, "animal:write") || slices.Contains(perms, "doc_type:write") || len(slices.Compact(slices.Clone(perms))) != len(perms) {
+		t.Fatalf("SubjectPermissions: got %v, %v", perms, err)
+	}
+
+	if err := db.RoleRevoke(ctx, "nurse", "receptionist", l); err != nil {
+		t.Fatalf("RoleRevoke: %v", err)
+	}
+	if perms, err := db.SubjectPermissions(ctx, "nurse", l); err != nil || slices.Contains(perms, "human:write") {
+		t.Fatalf("SubjectPermissions after RoleRevoke: got %v, %v", perms, err)
+	}
+	if err := db.RoleRevoke(ctx, "nurse", "receptionist", l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("second RoleRevoke: got %v, expected ErrNotFound", err)
+	}
+}
+
+func testAudit(t *testing.T, db Backend, l *slog.Logger) {
+	var actor = controllers.Actor{Subject: "registrar", RequestId: "audit-1"}
+	var ctx = controllers.WithActor(context.Background(), actor)
+	var human = controllers.Human{DocId: 5001, DocType: 1, FirstName: "Ivan", LastName: "Petrov", BirthDate: "1980-03-04"}
+	var list = func(filters ...controllers.Filter) []controllers.AuditEntry {
+		q := controllers.ListQuery{Limit: 100, Sort: []controllers.SortField{{Field: "id"}}, Filters: filters}
+		page, _, err := db.AuditList(ctx, q, l)
+		if err != nil {
+			t.Fatalf("AuditList: %v", err)
+		}
+		return page
+	}
+
+	if _, err := db.HumanCreate(ctx, human, l); err != nil {
+		t.Fatalf("HumanCreate: %v", err)
+	}
+	if _, err := db.HumanCreate(ctx, human, l); !errors.Is(err, controllers.ErrAlreadyExists) {
+		t.Fatalf("second HumanCreate: got %v, expected ErrAlreadyExists", err)
+	}
+	human.FirstName = "Ioann"
+	if _, err := db.HumanUpdate(ctx, human, l); err != nil {
+		t.Fatalf("HumanUpdate: %v", err)
+	}
+	if err := db.HumanDelete(ctx, human.DocId, l); err != nil {
+		t.Fatalf("HumanDelete: %v", err)
+	}
+
+	// failed writes leave no trace, the rest is recorded in order with the changed fields only
+	got := list(controllers.Filter{Field: "entity", Op: controllers.OpEq, Value: "human"}, controllers.Filter{Field: "entity_id", Op: controllers.OpEq, Value: "5001"})
+	if len(got) != 3 || got[0].Action != repos.ActionCreate || got[1].Action != repos.ActionUpdate || got[2].Action != repos.ActionDelete {
+		t.Fatalf("audit of human: got %+v", got)
+	}
+	for _, val := range got {
+		if val.Actor != actor.Subject || val.RequestId != actor.RequestId || len(val.At) < len(controllers.DateLayout) {
+			t.Fatalf("audit entry of human: got %+v", val)
+		}
+	}
+	if string(got[0].Before) != "" || !strings.Contains(string(got[0].After), `"LastName":"Petrov"`) {
+		t.Fatalf("audit of HumanCreate: got before %s, after %s", got[0].Before, got[0].After)
+	}
+	if !jsonEqual(got[1].Before, `{"FirstName":"Ivan"}`) || !jsonEqual(got[1].After, `{"FirstName":"Ioann"}`) {
+		t.Fatalf("audit of HumanUpdate: got before %s, after %s", got[1].Before, got[1].After)
+	}
+	if !strings.Contains(string(got[2].Before), `"FirstName":"Ioann"`) || string(got[2].After) != "" {
+		t.Fatalf("audit of HumanDelete: got before %s, after %s", got[2].Before, got[2].After)
+	}
+
+	// ids assigned by the DB are recorded
+	d, err := db.DocTypeCreate(ctx, controllers.DocType{Doc: "audit card"}, l)
+	if err != nil {
+		t.Fatalf("DocTypeCreate: %v", err)
+	}
+	got = list(controllers.Filter{Field: "entity", Op: controllers.OpEq, Value: "doc_type"}, controllers.Filter{Field: "request_id", Op: controllers.OpEq, Value: actor.RequestId})
+	if len(got) != 1 || got[0].EntityId != strconv.Itoa(d.Id) || !jsonEqual(got[0].After, `{"Doc":"audit card"}`) {
+		t.Fatalf("audit of DocTypeCreate with id %d: got %+v", d.Id, got)
+	}
+
+	// changes without an actor are made by the system
+	if err := db.RoleGrant(context.Background(), "audit clerk", "auditor", l); err != nil {
+		t.Fatalf("RoleGrant: %v", err)
+	}
+	if got := list(controllers.Filter{Field: "entity_id", Op: controllers.OpEq, Value: "audit clerk"}); len(got) != 1 || got[0].Actor != repos.SystemActor || got[0].RequestId != "" {
+		t.Fatalf("audit of RoleGrant without actor: got %+v", got)
+	}
+
+	today := time.Now().UTC().Format(controllers.DateLayout)
+	if got := list(controllers.Filter{Field: "date", Op: controllers.OpGte, Value: today}, controllers.Filter{Field: "request_id", Op: controllers.OpEq, Value: actor.RequestId}); len(got) != 4 {
+		t.Fatalf("audit of today: got %d entries, expected 4", len(got))
+	}
+	if got := list(controllers.Filter{Field: "date", Op: controllers.OpLte, Value: "2000-01-01"}); len(got) != 0 {
+		t.Fatalf("audit of 2000: got %+v", got)
+	}
+
+	// the log is append-only even for migrations
+	for _, query := range []string{"UPDATE audit_log SET actor='nobody'", "DELETE FROM audit_log"} {
+		if err := db.ExecMigration(ctx, []repos.DbReq{{Query: query}}); err == nil {
+			t.Fatalf("%s: got no error", query)
+		}
+	}
+	if got := list(controllers.Filter{Field: "actor", Op: controllers.OpEq, Value: "nobody"}); len(got) != 0 {
+		t.Fatalf("audit after rejected update: got %+v", got)
+	}
+}
+
+// jsonEqual reports whether raw holds the same JSON value as expected
+func jsonEqual(raw json.RawMessage, expected string) bool {
+	var a, b any
+	if json.Unmarshal(raw, &a) != nil || json.Unmarshal([]byte(expected), &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
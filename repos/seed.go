@@ -0,0 +1,83 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type SeedDocType struct {
+	Id  int    `yaml:"id"`
+	Doc string `yaml:"doc"`
+}
+
+type SeedAnimalType struct {
+	Id   int    `yaml:"id"`
+	Type string `yaml:"type"`
+}
+
+// DictSeed holds default content of dictionary tables
+type DictSeed struct {
+	DocType    []SeedDocType    `yaml:"doc_type"`
+	AnimalType []SeedAnimalType `yaml:"animal_type"`
+}
+
+// Seeder is implemented by backends able to fill dictionary tables.
+// Only tables that never held a record are seeded, so seeding again changes nothing
+// and records deleted or edited since stay as they are. Seeded records are audited as any other change
+type Seeder interface {
+	SeedDictTables(ctx context.Context, s DictSeed, l *slog.Logger) error
+}
+
+// LoadSeed reads DictSeed from YAML or JSON file
+func LoadSeed(path string) (DictSeed, error) {
+	var s DictSeed
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, fmt.Errorf("cannot read seed file: %w", err)
+	}
+	// JSON is a subset of YAML
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("cannot parse seed file %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return s, fmt.Errorf("bad seed file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Validate checks ids are positive and both ids and names are unique within a table
+func (s DictSeed) Validate() error {
+	var ids = make(map[int]bool)
+	var names = make(map[string]bool)
+	for _, val := range s.DocType {
+		if err := validateSeedRecord("doc_type", val.Id, val.Doc, ids, names); err != nil {
+			return err
+		}
+	}
+
+	ids = make(map[int]bool)
+	names = make(map[string]bool)
+	for _, val := range s.AnimalType {
+		if err := validateSeedRecord("animal_type", val.Id, val.Type, ids, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSeedRecord(table string, id int, name string, ids map[int]bool, names map[string]bool) error {
+	if id <= 0 || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s record [%d %s] must have positive id and non-empty name", table, id, name)
+	}
+	if ids[id] || names[name] {
+		return fmt.Errorf("%s record [%d %s] is duplicated", table, id, name)
+	}
+	ids[id] = true
+	names[name] = true
+	return nil
+}
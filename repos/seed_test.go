@@ -0,0 +1,70 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSeed(t *testing.T) {
+	type seedTest struct {
+		Data    string
+		IsErr   bool
+		Message string
+		Crit    bool
+	}
+	var fail bool
+	var arr = []seedTest{
+		{
+			Data:    "doc_type:\n  - id: 1\n    doc: passport\nanimal_type:\n  - id: 1\n    type: dog\n",
+			Message: "positive test [yaml] failed",
+			Crit:    true,
+		},
+		{
+			Data:    `{"doc_type": [{"id": 1, "doc": "passport"}], "animal_type": [{"id": 1, "type": "dog"}]}`,
+			Message: "positive test [json] failed",
+			Crit:    true,
+		},
+		{
+			Data:    `{"doc_type": [{"id": 1, "doc": "passport"}, {"id": 1, "doc": "military passport"}]}`,
+			IsErr:   true,
+			Message: "negative test [duplicated id] failed",
+			Crit:    true,
+		},
+		{
+			Data:    `{"animal_type": [{"id": 0, "type": "dog"}]}`,
+			IsErr:   true,
+			Message: "negative test [zero id] failed",
+			Crit:    true,
+		},
+		{
+			Data:    `{"animal_type": [{"id": 1, "type": ""}]}`,
+			IsErr:   true,
+			Message: "negative test [empty name] failed",
+			Crit:    true,
+		},
+	}
+
+	for i, val := range arr {
+		path := filepath.Join(t.TempDir(), "seed"+string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(val.Data), 0o600); err != nil {
+			t.Fatalf("cannot write seed file: %s", err.Error())
+		}
+		_, err := LoadSeed(path)
+		if (err != nil) != val.IsErr {
+			if val.Crit {
+				fail = true
+			}
+			t.Logf("crit: %t; %s; %v", val.Crit, val.Message, err)
+		}
+	}
+
+	if _, err := LoadSeed(filepath.Join("..", "config", "seed.yaml")); err != nil {
+		fail = true
+		t.Logf("crit: true; shipped seed file is broken; %s", err.Error())
+	}
+
+	if fail {
+		t.Fatalf("Critical tests failed")
+	}
+}
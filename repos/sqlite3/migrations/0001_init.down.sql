@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `animal`;
+DROP TABLE IF EXISTS `human`;
+DROP TABLE IF EXISTS `animal_type`;
+DROP TABLE IF EXISTS `doc_type`;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS `doc_type` (
+	`id` integer primary key NOT NULL UNIQUE,
+	`doc` TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS `animal_type` (
+	`id` integer primary key NOT NULL UNIQUE,
+	`type` TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS `human` (
+	`doc_id` integer primary key NOT NULL UNIQUE,
+	`doc_type` INTEGER NOT NULL,
+	`first_name` TEXT NOT NULL,
+	`middle_name` TEXT,
+	`last_name` TEXT NOT NULL,
+	`birth_date` REAL NOT NULL,
+FOREIGN KEY(`doc_type`) REFERENCES `doc_type`(`id`)
+);
+CREATE TABLE IF NOT EXISTS `animal` (
+	`doc_id` integer primary key NOT NULL UNIQUE,
+	`doc_type` INTEGER NOT NULL,
+	`name` TEXT NOT NULL,
+	`birth_date` REAL NOT NULL,
+	`animal_type` INTEGER NOT NULL,
+	`breed` TEXT NOT NULL,
+	`owner_doc_id` TEXT NOT NULL,
+FOREIGN KEY(`doc_type`) REFERENCES `doc_type`(`id`),
+FOREIGN KEY(`animal_type`) REFERENCES `animal_type`(`id`),
+FOREIGN KEY(`owner_doc_id`) REFERENCES `human`(`doc_id`)
+);
@@ -0,0 +1,16 @@
+-- foreign keys are suspended by ExecMigration, so broken references are copied as they are
+CREATE TABLE `animal_old` (
+	`doc_id` integer primary key NOT NULL UNIQUE,
+	`doc_type` INTEGER NOT NULL,
+	`name` TEXT NOT NULL,
+	`birth_date` REAL NOT NULL,
+	`animal_type` INTEGER NOT NULL,
+	`breed` TEXT NOT NULL,
+	`owner_doc_id` TEXT NOT NULL,
+FOREIGN KEY(`doc_type`) REFERENCES `doc_type`(`id`),
+FOREIGN KEY(`animal_type`) REFERENCES `animal_type`(`id`),
+FOREIGN KEY(`owner_doc_id`) REFERENCES `human`(`doc_id`)
+);
+INSERT INTO `animal_old` SELECT `doc_id`, `doc_type`, `name`, `birth_date`, `animal_type`, `breed`, CAST(`owner_doc_id` AS TEXT) FROM `animal`;
+DROP TABLE `animal`;
+ALTER TABLE `animal_old` RENAME TO `animal`;
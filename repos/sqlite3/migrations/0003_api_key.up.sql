@@ -0,0 +1,7 @@
+-- only sha-256 of the key is stored, the key itself is shown once on creation
+CREATE TABLE IF NOT EXISTS `api_key` (
+	`id` integer primary key AUTOINCREMENT NOT NULL UNIQUE,
+	`name` TEXT NOT NULL UNIQUE,
+	`key_hash` TEXT NOT NULL UNIQUE,
+	`revoked` INTEGER NOT NULL DEFAULT 0
+);
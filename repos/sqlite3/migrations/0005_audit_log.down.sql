@@ -0,0 +1,6 @@
+DELETE FROM `subject_role` WHERE `role`='auditor';
+DELETE FROM `role_permission` WHERE `permission`='audit:read';
+DELETE FROM `role` WHERE `name`='auditor';
+DROP TRIGGER IF EXISTS `audit_log_no_delete`;
+DROP TRIGGER IF EXISTS `audit_log_no_update`;
+DROP TABLE IF EXISTS `audit_log`;
@@ -0,0 +1,24 @@
+-- every create, update and delete of the repos is recorded here in the transaction of the change.
+-- before and after hold JSON of the changed fields, the log is append-only
+CREATE TABLE IF NOT EXISTS `audit_log` (
+	`id` integer primary key AUTOINCREMENT NOT NULL UNIQUE,
+	`at` TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+	`actor` TEXT NOT NULL,
+	`request_id` TEXT NOT NULL DEFAULT '',
+	`entity` TEXT NOT NULL,
+	`entity_id` TEXT NOT NULL,
+	`action` TEXT NOT NULL,
+	`before` TEXT,
+	`after` TEXT
+);
+CREATE INDEX IF NOT EXISTS `audit_log_entity` ON `audit_log` (`entity`, `entity_id`);
+CREATE TRIGGER IF NOT EXISTS `audit_log_no_update` BEFORE UPDATE ON `audit_log`
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS `audit_log_no_delete` BEFORE DELETE ON `audit_log`
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+INSERT INTO `role` (`name`) VALUES ('auditor');
+INSERT INTO `role_permission` (`role`, `permission`) VALUES ('admin', 'audit:read'), ('auditor', 'audit:read');
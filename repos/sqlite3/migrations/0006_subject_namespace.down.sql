@@ -0,0 +1,3 @@
+-- issuer and sub of token subjects cannot be told apart reliably, their grants are dropped
+DELETE FROM `subject_role` WHERE `subject` LIKE 'jwt:%';
+UPDATE `subject_role` SET `subject`=substr(`subject`, length('apikey:') + 1) WHERE `subject` LIKE 'apikey:%';
@@ -0,0 +1,5 @@
+-- subjects are namespaced by the authentication method: apikey:<name> and jwt:<iss>/<sub>.
+-- Grants to names of existing api keys move to the keys. The rest were sub claims of tokens,
+-- their issuer is unknown, so they are dropped and have to be granted again
+UPDATE `subject_role` SET `subject`='apikey:' || `subject` WHERE `subject` IN (SELECT `name` FROM `api_key`);
+DELETE FROM `subject_role` WHERE `subject` NOT LIKE 'apikey:%' AND `subject` NOT LIKE 'jwt:%';
@@ -0,0 +1,2 @@
+-- no-op: integer primary keys of sqlite are assigned by the db already.
+-- The version exists to keep the numbering of migrations the same on every backend
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `animal_type_type_unique`;
+DROP INDEX IF EXISTS `doc_type_doc_unique`;
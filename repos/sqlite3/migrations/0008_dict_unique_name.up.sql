@@ -0,0 +1,4 @@
+-- names of dictionary records are lookup keys, the db keeps them unique on its own now.
+-- The migration fails in case duplicates exist already, they are to be merged by hand first
+CREATE UNIQUE INDEX IF NOT EXISTS `doc_type_doc_unique` ON `doc_type` (`doc`);
+CREATE UNIQUE INDEX IF NOT EXISTS `animal_type_type_unique` ON `animal_type` (`type`);
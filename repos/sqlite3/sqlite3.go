@@ -4,9 +4,23 @@ import (
 	"database/sql"
 	_ "github.com/mattn/go-sqlite3"
 	"sync"
+	"time"
 )
 
 type SqLiteDB struct {
-	db *sql.DB
-	m  sync.Mutex // sqlite poorly handles simultaneous writes
+	Opts Options // applied by New
+	db   *sql.DB
+	m    sync.Mutex // sqlite poorly handles simultaneous writes
+}
+
+// Options tune the connections of the pool. Zero values keep the defaults of the driver and database/sql.
+// Options given in the db uri take precedence
+type Options struct {
+	JournalMode     string        // delete, truncate, persist, memory, wal or off
+	Synchronous     string        // off, normal, full or extra
+	BusyTimeout     time.Duration // wait for locks held by other connections and processes, 5s by default
+	CacheSize       int           // page cache per connection, negative value is in KiB
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
 }
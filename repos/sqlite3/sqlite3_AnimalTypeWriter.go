@@ -0,0 +1,79 @@
+package sqlite3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+)
+
+// AnimalTypeCreate inserts new record into AnimalType table. In case Id is 0 it is assigned by the DB
+func (s *SqLiteDB) AnimalTypeCreate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	// type is used as a lookup key, so it must stay unique
+	found, err := s.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM animal_type WHERE type=? OR id=?", Args: append(make([]any, 0), a.Type, a.Id)})
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	if found {
+		return controllers.AnimalType{}, fmt.Errorf("animal_type [%d %s]: %w", a.Id, a.Type, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "INSERT INTO animal_type (id, type) VALUES (?, ?)", Args: append(make([]any, 0), a.Id, a.Type)}
+	if a.Id == 0 {
+		req = repos.DbReq{Query: "INSERT INTO animal_type (type) VALUES (?)", Args: append(make([]any, 0), a.Type)}
+	}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(a.Id), Action: repos.ActionCreate, After: a}
+	if a.Id == 0 {
+		change.EntityId, change.LookupColumn, change.LookupValue = "", "type", a.Type
+		change.After = map[string]any{"Type": a.Type}
+	}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.AnimalType{}, fmt.Errorf("failed to create animal_type: %w", err)
+	}
+	l.Debug("animal_type created", "animal_type", a.Type)
+
+	return s.AnimalTypeGetByType(ctx, a.Type, l)
+}
+
+// AnimalTypeUpdate overwrites type of an existing AnimalType record
+func (s *SqLiteDB) AnimalTypeUpdate(ctx context.Context, a controllers.AnimalType, l *slog.Logger) (controllers.AnimalType, error) {
+	before, err := s.AnimalTypeGetById(ctx, a.Id, l)
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	found, err := s.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM animal_type WHERE type=? AND id<>?", Args: append(make([]any, 0), a.Type, a.Id)})
+	if err != nil {
+		return controllers.AnimalType{}, err
+	}
+	if found {
+		return controllers.AnimalType{}, fmt.Errorf("animal_type %s: %w", a.Type, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "UPDATE animal_type SET type=? WHERE id=?", Args: append(make([]any, 0), a.Type, a.Id)}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(a.Id), Action: repos.ActionUpdate, Before: before, After: a}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.AnimalType{}, fmt.Errorf("failed to update animal_type: %w", err)
+	}
+	l.Debug("animal_type updated", "id", a.Id, "animal_type", a.Type)
+
+	return s.AnimalTypeGetById(ctx, a.Id, l)
+}
+
+// AnimalTypeDelete removes AnimalType record by id
+func (s *SqLiteDB) AnimalTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	before, err := s.AnimalTypeGetById(ctx, id, l)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM animal_type WHERE id=?", Args: append(make([]any, 0), id)}
+	change := repos.Change{Entity: "animal_type", EntityId: strconv.Itoa(id), Action: repos.ActionDelete, Before: before}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to delete animal_type: %w", err)
+	}
+	l.Debug("animal_type deleted", "id", id)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+)
+
+// auditInsert starts the audit_log insert, entity_id goes last
+const auditInsert = "INSERT INTO audit_log (actor, request_id, entity, action, before, after, entity_id)"
+
+// auditSelect is shared by all audit_log queries
+const auditSelect = "SELECT id, at, actor, request_id, entity, entity_id, action, before, after FROM audit_log"
+
+// auditListColumns are list columns of the audit log. at is stored as RFC 3339 text
+var auditListColumns = map[string]string{
+	"id":         "id",
+	"date":       "julianday(date(at))",
+	"actor":      "actor",
+	"request_id": "request_id",
+	"entity":     "entity",
+	"entity_id":  "entity_id",
+	"action":     "action",
+}
+
+// change runs rs along with the audit_log record of c in one transaction. Every write of the repos goes
+// through it, so the data never changes without a trace
+func (s *SqLiteDB) change(ctx context.Context, rs []repos.DbReq, c repos.Change) error {
+	args, err := repos.AuditArgs(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: auditInsert + " VALUES (?, ?, ?, ?, ?, ?, ?)", Args: append(args, c.EntityId)}
+	if c.EntityId == "" {
+		// the id is assigned by the insert that runs earlier in the same transaction
+		req = repos.DbReq{
+			Query: auditInsert + " SELECT ?, ?, ?, ?, ?, ?, CAST(id AS TEXT) FROM " + c.Entity + " WHERE " + c.LookupColumn + "=?",
+			Args:  append(args, c.LookupValue),
+		}
+	}
+	return s.exec(ctx, append(rs, req))
+}
+
+// AuditList returns a page of audit_log records
+func (s *SqLiteDB) AuditList(ctx context.Context, q controllers.ListQuery, l *slog.Logger) ([]controllers.AuditEntry, []string, error) {
+	var result []controllers.AuditEntry
+
+	req, err := repos.ListQuery(auditSelect, auditListColumns, controllers.AuditListSpec, q, listDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := s.Get(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("audit_log list: bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e controllers.AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.Id, &e.At, &e.Actor, &e.RequestId, &e.Entity, &e.EntityId, &e.Action, &before, &after); err != nil {
+			return nil, nil, fmt.Errorf("audit_log list: cannot read query result: %w", err)
+		}
+		e.Before, e.After = rawJSON(before), rawJSON(after)
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("audit_log list: cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "audit_log", len(result))
+
+	items, after := repos.ListPage(result, q, repos.AuditListValues)
+	return items, after, nil
+}
+
+// rawJSON converts nullable JSON column to the raw message, NULL becomes nil
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
@@ -0,0 +1,204 @@
+package sqlite3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+)
+
+// typeChecks are columns whose stored values must be of the declared type. SQLite keeps whatever it is given
+// when the value does not convert, so text may end up in integer columns of records edited by hand.
+// Only dates have a repair: the text is converted to julian day in case SQLite understands it as a date
+var typeChecks = []struct {
+	table, column, typ, repairable string
+}{
+	{"human", "doc_type", "integer", "0"},
+	{"human", "birth_date", "real", "julianday(birth_date) IS NOT NULL"},
+	{"animal", "doc_type", "integer", "0"},
+	{"animal", "birth_date", "real", "julianday(birth_date) IS NOT NULL"},
+	{"animal", "animal_type", "integer", "0"},
+	{"animal", "owner_doc_id", "integer", "0"},
+}
+
+// refChecks are the foreign keys of the schema. Broken references are only possible in records
+// written while the enforcement was off
+var refChecks = []struct {
+	problem, table, column, parent string
+}{
+	{repos.ProblemOrphanedAnimal, "animal", "owner_doc_id", "human"},
+	{repos.ProblemUnknownDocType, "human", "doc_type", "doc_type"},
+	{repos.ProblemUnknownDocType, "animal", "doc_type", "doc_type"},
+	{repos.ProblemUnknownAnimalType, "animal", "animal_type", "animal_type"},
+}
+
+// parentKeys are the referenced columns
+var parentKeys = map[string]string{"human": "doc_id", "doc_type": "id", "animal_type": "id"}
+
+// CheckConsistency returns issues of human and animal records ordered by check, then by doc_id
+func (s *SqLiteDB) CheckConsistency(ctx context.Context, l *slog.Logger) ([]repos.Issue, error) {
+	var result []repos.Issue
+
+	for _, val := range typeChecks {
+		req := repos.DbReq{Query: fmt.Sprintf("SELECT CAST(doc_id AS TEXT), CAST(%[2]s AS TEXT), %[4]s FROM %[1]s WHERE typeof(%[2]s) <> '%[3]s' ORDER BY doc_id",
+			val.table, val.column, val.typ, val.repairable)}
+		issues, err := s.issueQuery(ctx, req, repos.ProblemTypeMismatch, val.table, val.column, "convert to julian day")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, issues...)
+	}
+
+	for _, val := range refChecks {
+		repair := "create placeholder " + val.parent
+		if val.problem == repos.ProblemOrphanedAnimal {
+			repair = "delete animal"
+		}
+		// references holding text cannot be matched to a parent at all, they need a manual fix
+		req := repos.DbReq{Query: fmt.Sprintf("SELECT CAST(c.doc_id AS TEXT), CAST(c.%[2]s AS TEXT), typeof(c.%[2]s) = 'integer' FROM %[1]s c LEFT JOIN %[3]s p ON p.%[4]s = c.%[2]s WHERE p.%[4]s IS NULL ORDER BY c.doc_id",
+			val.table, val.column, val.parent, parentKeys[val.parent])}
+		issues, err := s.issueQuery(ctx, req, val.problem, val.table, val.column, repair)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, issues...)
+	}
+
+	// records with a value nothing can be done about are left for the manual fix as a whole,
+	// e.g. an orphaned animal with text in doc_type cannot be read to keep it in the audit log
+	manual := map[string]bool{}
+	for _, val := range result {
+		if val.Problem == repos.ProblemTypeMismatch && val.Repair == "" {
+			manual[val.Entity+" "+val.EntityId] = true
+		}
+	}
+	for i, val := range result {
+		if manual[val.Entity+" "+val.EntityId] {
+			result[i].Repair = ""
+		}
+	}
+	l.Debug("consistency checked", "issues", len(result))
+
+	return result, nil
+}
+
+// RepairConsistency fixes the issues found by CheckConsistency that have a repair and returns them.
+// Placeholder dictionary records go first, so animals are deleted for missing owners only.
+// Every fix is a regular audited write made on behalf of the actor of ctx
+func (s *SqLiteDB) RepairConsistency(ctx context.Context, l *slog.Logger) ([]repos.Issue, error) {
+	issues, err := s.CheckConsistency(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	var repaired []repos.Issue
+	done := map[string]bool{}
+	for _, phase := range []string{repos.ProblemUnknownDocType, repos.ProblemUnknownAnimalType, repos.ProblemOrphanedAnimal, repos.ProblemTypeMismatch} {
+		for _, val := range issues {
+			// a deleted record needs no other fixes
+			if val.Problem != phase || val.Repair == "" || done[val.Entity+" "+val.EntityId] {
+				continue
+			}
+			if err := s.repair(ctx, val, done, l); err != nil {
+				return repaired, fmt.Errorf("%s %s %s: %w", val.Problem, val.Entity, val.EntityId, err)
+			}
+			repaired = append(repaired, val)
+		}
+	}
+	l.Info("consistency repaired", "issues", len(repaired))
+
+	return repaired, nil
+}
+
+// repair fixes a single issue. done holds placeholders created and records deleted so far,
+// several records may refer to the same placeholder
+func (s *SqLiteDB) repair(ctx context.Context, i repos.Issue, done map[string]bool, l *slog.Logger) error {
+	switch i.Problem {
+	case repos.ProblemUnknownDocType, repos.ProblemUnknownAnimalType:
+		if done[i.Problem+" "+i.Value] {
+			return nil
+		}
+		id, err := strconv.Atoi(i.Value)
+		if err != nil {
+			return err
+		}
+		if i.Problem == repos.ProblemUnknownDocType {
+			_, err = s.DocTypeCreate(ctx, controllers.DocType{Id: id, Doc: "unknown " + i.Value}, l)
+		} else {
+			_, err = s.AnimalTypeCreate(ctx, controllers.AnimalType{Id: id, Type: "unknown " + i.Value}, l)
+		}
+		done[i.Problem+" "+i.Value] = true
+		return err
+	case repos.ProblemOrphanedAnimal:
+		docId, err := strconv.Atoi(i.EntityId)
+		if err != nil {
+			return err
+		}
+		done[i.Entity+" "+i.EntityId] = true
+		return s.AnimalDelete(ctx, docId, l)
+	case repos.ProblemTypeMismatch:
+		var storage, date string
+		rows, err := s.Get(ctx, repos.DbReq{
+			Query: "SELECT typeof(" + i.Column + "), date(" + i.Column + ") FROM " + i.Entity + " WHERE doc_id=?",
+			Args:  append(make([]any, 0), i.EntityId),
+		})
+		if err != nil {
+			return fmt.Errorf("bad DB query: %w", err)
+		}
+		if rows.Next() {
+			err = rows.Scan(&storage, &date)
+		}
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("cannot read query result: %w", err)
+		}
+
+		// only the column is updated, so foreign keys of the record are not checked again
+		req := repos.DbReq{
+			Query: "UPDATE " + i.Entity + " SET " + i.Column + "=julianday(" + i.Column + ") WHERE doc_id=?",
+			Args:  append(make([]any, 0), i.EntityId),
+		}
+		change := repos.Change{
+			Entity:   i.Entity,
+			EntityId: i.EntityId,
+			Action:   repos.ActionUpdate,
+			Before:   map[string]any{i.Column: i.Value, "storage": storage},
+			After:    map[string]any{i.Column: date, "storage": "real"},
+		}
+		if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+			return fmt.Errorf("failed to update %s: %w", i.Entity, err)
+		}
+		l.Debug(i.Entity+" column converted", "doc_id", i.EntityId, "column", i.Column)
+	}
+	return nil
+}
+
+// issueQuery reads doc_id, value and whether the issue is repairable from req
+func (s *SqLiteDB) issueQuery(ctx context.Context, req repos.DbReq, problem, entity, column, repair string) ([]repos.Issue, error) {
+	var result []repos.Issue
+
+	rows, err := s.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s check: bad DB query: %w", entity, column, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i = repos.Issue{Problem: problem, Entity: entity, Column: column}
+		var repairable bool
+		if err := rows.Scan(&i.EntityId, &i.Value, &repairable); err != nil {
+			return nil, fmt.Errorf("%s %s check: cannot read query result: %w", entity, column, err)
+		}
+		if repairable {
+			i.Repair = repair
+		}
+		result = append(result, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s %s check: cannot read query result: %w", entity, column, repos.Unavailable(err))
+	}
+
+	return result, nil
+}
@@ -3,19 +3,34 @@ package sqlite3
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/lg"
 	"mis-catanddog/repos"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// New initializes DB connection
+// New initializes DB connection tuned by s.Opts. Foreign keys are enforced on every connection of the pool
 func (s *SqLiteDB) New(uri string, timeout time.Duration) error {
 	var err error
 
-	s.db, err = sql.Open("sqlite3", uri)
+	s.db, err = sql.Open("sqlite3", dsn(uri, s.Opts))
 	if err != nil {
 		return fmt.Errorf("failed to create db object: %w", err)
 	}
+	if s.Opts.MaxOpenConns > 0 {
+		s.db.SetMaxOpenConns(s.Opts.MaxOpenConns)
+	}
+	if s.Opts.MaxIdleConns > 0 {
+		s.db.SetMaxIdleConns(s.Opts.MaxIdleConns)
+	}
+	if s.Opts.ConnMaxLifetime > 0 {
+		s.db.SetConnMaxLifetime(s.Opts.ConnMaxLifetime)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -24,52 +39,170 @@ func (s *SqLiteDB) New(uri string, timeout time.Duration) error {
 		return fmt.Errorf("failed to ping repos: %w", err)
 	}
 
+	// uri may turn enforcement off explicitly, the schema relies on it
+	var fk bool
+	if err := s.db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fk); err != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	if !fk {
+		return fmt.Errorf("foreign keys are disabled by the db uri")
+	}
+
 	return nil
 }
 
+// dsn adds driver options to uri, the driver runs the matching pragmas on every new connection.
+// Pragmas are per connection, so running them once after Open would leave the rest of the pool untuned.
+// Write transactions take the lock on begin, so waiting for it is bound by the busy timeout
+// instead of failing with SQLITE_BUSY on the first write of a deferred transaction
+func dsn(uri string, o Options) string {
+	params := []string{"_foreign_keys=1", "_txlock=immediate"}
+	if o.JournalMode != "" {
+		params = append(params, "_journal_mode="+o.JournalMode)
+	}
+	if o.Synchronous != "" {
+		params = append(params, "_synchronous="+o.Synchronous)
+	}
+	if o.BusyTimeout > 0 {
+		params = append(params, "_busy_timeout="+strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	}
+	if o.CacheSize != 0 {
+		params = append(params, "_cache_size="+strconv.Itoa(o.CacheSize))
+	}
+
+	// the driver reads the first value of an option, so the ones of uri win
+	if strings.Contains(uri, "?") {
+		return uri + "&" + strings.Join(params, "&")
+	}
+	return uri + "?" + strings.Join(params, "&")
+}
+
 // Get runs SELECT queries
 func (s *SqLiteDB) Get(ctx context.Context, r repos.DbReq) (*sql.Rows, error) {
+	start := time.Now()
 	result, err := s.db.QueryContext(ctx, r.Query, r.Args...)
+	// request-scoped logger carries the request id, so slow queries can be traced back to the caller
+	lg.FromContext(ctx, slog.Default()).Debug("query", "Query", r.Query, "Duration", time.Since(start))
 	if err != nil {
-		return nil, fmt.Errorf("failed query: %w", err)
+		return nil, fmt.Errorf("failed query: %w", unavailable(err))
 	}
 	return result, nil
 }
 
-// Exec runs query in transaction and does not return any result
-func (s *SqLiteDB) Exec(ctx context.Context, rs []repos.DbReq) error {
+// ExecMigration runs queries of a schema migration in a single transaction. It is meant for the migrate
+// package only, the changes are not audited.
+// Tables are rebuilt to change their columns, so foreign keys are not enforced during the migration: a legacy db
+// may hold broken references already, and every statement copying them would fail. The migration runs on a connection
+// of its own, which is dropped afterwards, so enforcement stays on for the rest of the pool. The migration
+// must not break references itself, violations found after it that were not there before roll it back.
+// The ones found before are left for the check subcommand
+func (s *SqLiteDB) ExecMigration(ctx context.Context, rs []repos.DbReq) error {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	// begin transaction
-	tx, err := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", unavailable(err))
+	}
+	defer conn.Close()
+	// the driver error makes the pool close the connection instead of reusing it
+	defer conn.Raw(func(any) error { return driver.ErrBadConn })
+
+	// the pragma is a no-op inside a transaction
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		return fmt.Errorf("failed to suspend foreign keys: %w", unavailable(err))
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to init transaction: %w", err)
+		return fmt.Errorf("failed to init transaction: %w", unavailable(err))
 	}
+	defer tx.Rollback()
 
-	// run all queries inside tx
+	before, err := fkViolations(ctx, tx)
+	if err != nil {
+		return err
+	}
 	for _, val := range rs {
-		// prepare statement ot exec
-		stmt, err := tx.Prepare(val.Query)
-		if err != nil {
-			return fmt.Errorf("failed to prepare query %s: %w", val.Query, err)
+		if _, err := tx.ExecContext(ctx, val.Query, val.Args...); err != nil {
+			return fmt.Errorf("failed query [%s]. Rolling back: %w", val.Query, violation(unavailable(err)))
 		}
+	}
+	after, err := fkViolations(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if after > before {
+		return fmt.Errorf("%w: migration breaks %d references. Rolling back", controllers.ErrReference, after-before)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit a transaction: %w", unavailable(err))
+	}
+	if after > 0 {
+		lg.FromContext(ctx, slog.Default()).Warn("db holds broken references, run 'check report'", "violations", after)
+	}
+	return nil
+}
+
+// fkViolations returns number of rows breaking foreign keys
+func fkViolations(ctx context.Context, tx *sql.Tx) (int, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM pragma_foreign_key_check").Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to check foreign keys: %w", unavailable(err))
+	}
+	return n, nil
+}
+
+// exec runs queries in a single transaction and does not return any result. Changes of records go through change,
+// which adds the audit entry to the transaction
+func (s *SqLiteDB) exec(ctx context.Context, rs []repos.DbReq) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	start := time.Now()
+	defer func() {
+		lg.FromContext(ctx, slog.Default()).Debug("transaction", "Queries", len(rs), "Duration", time.Since(start))
+	}()
 
-		// exec statement
-		if _, err := stmt.Exec(val.Args...); err != nil {
-			return fmt.Errorf("failed query [%s]. Rolling back: %w", val.Query, err)
+	// begin transaction
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to init transaction: %w", unavailable(err))
+	}
+	defer tx.Rollback()
+
+	// run all queries inside tx. A query may hold several statements
+	for _, val := range rs {
+		if _, err := tx.ExecContext(ctx, val.Query, val.Args...); err != nil {
+			return fmt.Errorf("failed query [%s]. Rolling back: %w", val.Query, violation(unavailable(err)))
 		}
-		defer stmt.Close()
 	}
 
 	// commit a transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit a transaction: %w", err)
+		return fmt.Errorf("failed to commit a transaction: %w", unavailable(err))
 	}
 	return nil
 }
 
+// Ping verifies the db is reachable
+func (s *SqLiteDB) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping repos: %w", unavailable(err))
+	}
+	return nil
+}
+
+// Version returns version of the db server
+func (s *SqLiteDB) Version(ctx context.Context) (string, error) {
+	var version string
+	if err := s.db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to get db version: %w", unavailable(err))
+	}
+	return version, nil
+}
+
 // Close closes DB connection
 func (s *SqLiteDB) Close() {
 	s.db.Close()
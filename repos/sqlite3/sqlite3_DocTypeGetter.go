@@ -9,41 +9,129 @@ import (
 )
 
 // DocTypeGetById searches DocType table by id and returns DocType object
-func (s *SqLiteDB) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) controllers.DocType {
+func (s *SqLiteDB) DocTypeGetById(ctx context.Context, id int, l *slog.Logger) (controllers.DocType, error) {
 	req := repos.DbReq{Query: "SELECT id, doc from doc_type WHERE id=?", Args: append(make([]any, 0), id)}
 
-	return invokeRequest(ctx, req, s, l)
+	result, err := invokeRequest(ctx, req, s, l)
+	if err != nil {
+		return result, fmt.Errorf("doc_type id %d: %w", id, err)
+	}
+	return result, nil
 }
 
 // DocTypeGetByDoc searches DocType table by doc and returns DocType object
-func (s *SqLiteDB) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) controllers.DocType {
+func (s *SqLiteDB) DocTypeGetByDoc(ctx context.Context, doc string, l *slog.Logger) (controllers.DocType, error) {
 	req := repos.DbReq{Query: "SELECT id, doc from doc_type WHERE doc=?", Args: append(make([]any, 0), doc)}
 
-	return invokeRequest(ctx, req, s, l)
+	result, err := invokeRequest(ctx, req, s, l)
+	if err != nil {
+		return result, fmt.Errorf("doc_type %s: %w", doc, err)
+	}
+	return result, nil
+}
+
+// DocTypeGetByIds searches DocType table by every id with a single query
+func (s *SqLiteDB) DocTypeGetByIds(ctx context.Context, ids []int, l *slog.Logger) ([]controllers.DocType, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(ids))
+	for _, val := range ids {
+		args = append(args, val)
+	}
+	req := repos.DbReq{Query: "SELECT id, doc from doc_type WHERE id IN (" + placeholders(len(ids)) + ")", Args: args}
+
+	found, err := invokeBatchRequest(ctx, req, s, l)
+	if err != nil {
+		return nil, fmt.Errorf("doc_type ids %v: %w", ids, err)
+	}
+	byId := make(map[int]controllers.DocType, len(found))
+	for _, val := range found {
+		byId[val.Id] = val
+	}
+	result := make([]controllers.DocType, 0, len(ids))
+	for _, val := range ids {
+		result = append(result, byId[val])
+	}
+	return result, nil
 }
 
-func invokeRequest(ctx context.Context, req repos.DbReq, s *SqLiteDB, l *slog.Logger) controllers.DocType {
+// DocTypeGetByDocs searches DocType table by every doc with a single query
+func (s *SqLiteDB) DocTypeGetByDocs(ctx context.Context, docs []string, l *slog.Logger) ([]controllers.DocType, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(docs))
+	for _, val := range docs {
+		args = append(args, val)
+	}
+	req := repos.DbReq{Query: "SELECT id, doc from doc_type WHERE doc IN (" + placeholders(len(docs)) + ")", Args: args}
+
+	found, err := invokeBatchRequest(ctx, req, s, l)
+	if err != nil {
+		return nil, fmt.Errorf("doc_type docs %v: %w", docs, err)
+	}
+	byDoc := make(map[string]controllers.DocType, len(found))
+	for _, val := range found {
+		byDoc[val.Doc] = val
+	}
+	result := make([]controllers.DocType, 0, len(docs))
+	for _, val := range docs {
+		result = append(result, byDoc[val])
+	}
+	return result, nil
+}
+
+// invokeRequest runs query expected to yield a single DocType. Returns controllers.ErrNotFound on empty result
+func invokeRequest(ctx context.Context, req repos.DbReq, s *SqLiteDB, l *slog.Logger) (controllers.DocType, error) {
 	var result controllers.DocType
 
 	rows, err := s.Get(ctx, req)
 	if err != nil {
-		tmp := controllers.DocType{Id: 0, Doc: "", Err: fmt.Errorf("bad DB query: %w", err).Error()}
-		l.Error(tmp.Err)
-		return result
+		return result, fmt.Errorf("bad DB query: %w", err)
 	}
+	defer rows.Close()
 
 	for i := 0; rows.Next(); i++ {
 		if i > 0 {
-			l.Error("query to dict table yielded more than one result")
-			result = controllers.DocType{Id: 0, Doc: "", Err: "query to dict table yielded more than one result"}
-			break
+			return controllers.DocType{}, fmt.Errorf("query to dict table yielded more than one result")
 		}
 		if err := rows.Scan(&result.Id, &result.Doc); err != nil {
-			result.Err = fmt.Errorf("cannot read query result %w", err).Error()
-			continue
+			return controllers.DocType{}, fmt.Errorf("cannot read query result: %w", err)
 		}
 	}
-	l.Debug("query result", "id", result.Id, "doc_type", result.Doc, "error", result.Err)
+	if err := rows.Err(); err != nil {
+		return controllers.DocType{}, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "id", result.Id, "doc_type", result.Doc)
+
+	if result.Id == 0 {
+		return result, controllers.ErrNotFound
+	}
+	return result, nil
+}
+
+// invokeBatchRequest runs query yielding any number of DocType records
+func invokeBatchRequest(ctx context.Context, req repos.DbReq, s *SqLiteDB, l *slog.Logger) ([]controllers.DocType, error) {
+	var result []controllers.DocType
+
+	rows, err := s.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d controllers.DocType
+		if err := rows.Scan(&d.Id, &d.Doc); err != nil {
+			return nil, fmt.Errorf("cannot read query result: %w", err)
+		}
+		result = append(result, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read query result: %w", repos.Unavailable(err))
+	}
+	l.Debug("query result", "doc_type", len(result))
 
-	return result
+	return result, nil
 }
@@ -0,0 +1,86 @@
+package sqlite3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+)
+
+// DocTypeCreate inserts new record into DocType table. In case Id is 0 it is assigned by the DB
+func (s *SqLiteDB) DocTypeCreate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	// doc is used as a lookup key, so it must stay unique
+	found, err := s.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM doc_type WHERE doc=? OR id=?", Args: append(make([]any, 0), d.Doc, d.Id)})
+	if err != nil {
+		return controllers.DocType{}, err
+	}
+	if found {
+		return controllers.DocType{}, fmt.Errorf("doc_type [%d %s]: %w", d.Id, d.Doc, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "INSERT INTO doc_type (id, doc) VALUES (?, ?)", Args: append(make([]any, 0), d.Id, d.Doc)}
+	if d.Id == 0 {
+		req = repos.DbReq{Query: "INSERT INTO doc_type (doc) VALUES (?)", Args: append(make([]any, 0), d.Doc)}
+	}
+	change := repos.Change{Entity: "doc_type", EntityId: strconv.Itoa(d.Id), Action: repos.ActionCreate, After: d}
+	if d.Id == 0 {
+		change.EntityId, change.LookupColumn, change.LookupValue = "", "doc", d.Doc
+		change.After = map[string]any{"Doc": d.Doc}
+	}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.DocType{}, fmt.Errorf("failed to create doc_type: %w", err)
+	}
+	l.Debug("doc_type created", "doc_type", d.Doc)
+
+	return s.DocTypeGetByDoc(ctx, d.Doc, l)
+}
+
+// DocTypeUpdate overwrites doc of an existing DocType record
+func (s *SqLiteDB) DocTypeUpdate(ctx context.Context, d controllers.DocType, l *slog.Logger) (controllers.DocType, error) {
+	before, err := s.DocTypeGetById(ctx, d.Id, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return controllers.DocType{}, fmt.Errorf("doc_type id %d: %w", d.Id, controllers.ErrNotFound)
+	}
+	if err != nil {
+		return controllers.DocType{}, err
+	}
+	found, err := s.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM doc_type WHERE doc=? AND id<>?", Args: append(make([]any, 0), d.Doc, d.Id)})
+	if err != nil {
+		return controllers.DocType{}, err
+	}
+	if found {
+		return controllers.DocType{}, fmt.Errorf("doc_type %s: %w", d.Doc, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{Query: "UPDATE doc_type SET doc=? WHERE id=?", Args: append(make([]any, 0), d.Doc, d.Id)}
+	change := repos.Change{Entity: "doc_type", EntityId: strconv.Itoa(d.Id), Action: repos.ActionUpdate, Before: before, After: d}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.DocType{}, fmt.Errorf("failed to update doc_type: %w", err)
+	}
+	l.Debug("doc_type updated", "id", d.Id, "doc_type", d.Doc)
+
+	return s.DocTypeGetById(ctx, d.Id, l)
+}
+
+// DocTypeDelete removes DocType record by id
+func (s *SqLiteDB) DocTypeDelete(ctx context.Context, id int, l *slog.Logger) error {
+	before, err := s.DocTypeGetById(ctx, id, l)
+	if errors.Is(err, controllers.ErrNotFound) {
+		return fmt.Errorf("doc_type id %d: %w", id, controllers.ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM doc_type WHERE id=?", Args: append(make([]any, 0), id)}
+	change := repos.Change{Entity: "doc_type", EntityId: strconv.Itoa(id), Action: repos.ActionDelete, Before: before}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to delete doc_type: %w", err)
+	}
+	l.Debug("doc_type deleted", "id", id)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strconv"
+	"strings"
+)
+
+// humanSelect is shared by all human queries. birth_date is stored as julian day
+const humanSelect = "SELECT doc_id, doc_type, first_name, middle_name, last_name, date(birth_date) FROM human"
+
+// humanSearchLimit caps the number of records HumanSearch returns
+const humanSearchLimit = 100
+
+// HumanCreate inserts new record into human table
+func (s *SqLiteDB) HumanCreate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	found, err := s.exists(ctx, repos.DbReq{Query: "SELECT 1 FROM human WHERE doc_id=?", Args: append(make([]any, 0), h.DocId)})
+	if err != nil {
+		return controllers.Human{}, err
+	}
+	if found {
+		return controllers.Human{}, fmt.Errorf("human doc_id %d: %w", h.DocId, controllers.ErrAlreadyExists)
+	}
+
+	req := repos.DbReq{
+		Query: "INSERT INTO human (doc_id, doc_type, first_name, middle_name, last_name, birth_date) VALUES (?, ?, ?, ?, ?, julianday(?))",
+		Args:  append(make([]any, 0), h.DocId, h.DocType, h.FirstName, nullString(h.MiddleName), h.LastName, h.BirthDate),
+	}
+	change := repos.Change{Entity: "human", EntityId: strconv.Itoa(h.DocId), Action: repos.ActionCreate, After: h}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.Human{}, fmt.Errorf("failed to create human: %w", err)
+	}
+	l.Debug("human created", "doc_id", h.DocId)
+
+	return s.HumanGet(ctx, h.DocId, l)
+}
+
+// HumanGet searches human table by doc_id
+func (s *SqLiteDB) HumanGet(ctx context.Context, docId int, l *slog.Logger) (controllers.Human, error) {
+	req := repos.DbReq{Query: humanSelect + " WHERE doc_id=?", Args: append(make([]any, 0), docId)}
+
+	result, err := s.humanQuery(ctx, req, l)
+	if err != nil {
+		return controllers.Human{}, err
+	}
+	if len(result) == 0 {
+		return controllers.Human{}, fmt.Errorf("human doc_id %d: %w", docId, controllers.ErrNotFound)
+	}
+
+	return result[0], nil
+}
+
+// HumanUpdate overwrites all fields of an existing human record
+func (s *SqLiteDB) HumanUpdate(ctx context.Context, h controllers.Human, l *slog.Logger) (controllers.Human, error) {
+	before, err := s.HumanGet(ctx, h.DocId, l)
+	if err != nil {
+		return controllers.Human{}, err
+	}
+
+	req := repos.DbReq{
+		Query: "UPDATE human SET doc_type=?, first_name=?, middle_name=?, last_name=?, birth_date=julianday(?) WHERE doc_id=?",
+		Args:  append(make([]any, 0), h.DocType, h.FirstName, nullString(h.MiddleName), h.LastName, h.BirthDate, h.DocId),
+	}
+	change := repos.Change{Entity: "human", EntityId: strconv.Itoa(h.DocId), Action: repos.ActionUpdate, Before: before, After: h}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return controllers.Human{}, fmt.Errorf("failed to update human: %w", err)
+	}
+	l.Debug("human updated", "doc_id", h.DocId)
+
+	return s.HumanGet(ctx, h.DocId, l)
+}
+
+// HumanDelete removes human record by doc_id
+func (s *SqLiteDB) HumanDelete(ctx context.Context, docId int, l *slog.Logger) error {
+	before, err := s.HumanGet(ctx, docId, l)
+	if err != nil {
+		return err
+	}
+
+	req := repos.DbReq{Query: "DELETE FROM human WHERE doc_id=?", Args: append(make([]any, 0), docId)}
+	change := repos.Change{Entity: "human", EntityId: strconv.Itoa(docId), Action: repos.ActionDelete, Before: before}
+	if err := s.change(ctx, []repos.DbReq{req}, change); err != nil {
+		return fmt.Errorf("failed to delete human: %w", err)
+	}
+	l.Debug("human deleted", "doc_id", docId)
+
+	return nil
+}
+
+// HumanSearch returns human records matching all non-empty filter fields ordered by last name
+func (s *SqLiteDB) HumanSearch(ctx context.Context, f controllers.HumanFilter, l *slog.Logger) ([]controllers.Human, error) {
+	var where []string
+	var args []any
+
+	if f.DocType != 0 {
+		where = append(where, "doc_type=?")
+		args = append(args, f.DocType)
+	}
+	if f.FirstName != "" {
+		where = append(where, `first_name LIKE ? ESCAPE '\'`)
+		args = append(args, likePrefix(f.FirstName))
+	}
+	if f.LastName != "" {
+		where = append(where, `last_name LIKE ? ESCAPE '\'`)
+		args = append(args, likePrefix(f.LastName))
+	}
+	if f.BirthDate != "" {
+		where = append(where, "birth_date=julianday(?)")
+		args = append(args, f.BirthDate)
+	}
+
+	query := humanSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY last_name, first_name, doc_id LIMIT %d", humanSearchLimit)
+
+	return s.humanQuery(ctx, repos.DbReq{Query: query, Args: args}, l)
+}
+
+func (s *SqLiteDB) humanQuery(ctx context.Context, req repos.DbReq, l *slog.Logger) ([]controllers.Human, error) {
+	var result []controllers.Human
+
+	rows, err := s.Get(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h controllers.Human
+		var middleName sql.NullString
+		if err := rows.Scan(&h.DocId, &h.DocType, &h.FirstName, &middleName, &h.LastName, &h.BirthDate); err != nil {
+			return nil, fmt.Errorf("cannot read query result %w", err)
+		}
+		h.MiddleName = middleName.String
+		result = append(result, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read query result %w", err)
+	}
+	l.Debug("query result", "human", len(result))
+
+	return result, nil
+}
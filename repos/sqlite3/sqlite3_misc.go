@@ -2,49 +2,120 @@ package sqlite3
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"fmt"
-	"time"
+	driver "github.com/mattn/go-sqlite3"
+	"io/fs"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"strings"
 )
 
-// Init tries to create necessary tables if they don't exist
-func (s *SqLiteDB) Init(timeout time.Duration) error {
-	if s.db == nil {
-		return fmt.Errorf("db connection is not set")
-	}
-
-	var q string = "CREATE TABLE IF NOT EXISTS `doc_type` ( \t`id` integer primary key NOT NULL UNIQUE, \t`doc` TEXT NOT NULL ); CREATE TABLE IF NOT EXISTS `animal_type` ( \t`id` integer primary key NOT NULL UNIQUE, \t`type` TEXT NOT NULL ); CREATE TABLE IF NOT EXISTS `human` ( \t`doc_id` integer primary key NOT NULL UNIQUE, \t`doc_type` INTEGER NOT NULL, \t`first_name` TEXT NOT NULL, \t`middle_name` TEXT, \t`last_name` TEXT NOT NULL, \t`birth_date` REAL NOT NULL, FOREIGN KEY(`doc_type`) REFERENCES `doc_type`(`id`) ); CREATE TABLE IF NOT EXISTS `animal` ( \t`doc_id` integer primary key NOT NULL UNIQUE, \t`doc_type` INTEGER NOT NULL, \t`name` TEXT NOT NULL, \t`birth_date` REAL NOT NULL, \t`animal_type` INTEGER NOT NULL, \t`breed` TEXT NOT NULL, \t`owner_doc_id` TEXT NOT NULL, FOREIGN KEY(`doc_type`) REFERENCES `doc_type`(`id`), FOREIGN KEY(`animal_type`) REFERENCES `animal_type`(`id`), FOREIGN KEY(`owner_doc_id`) REFERENCES `human`(`doc_id`) );"
+//go:embed migrations/*.sql
+var migrations embed.FS
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Millisecond)
-	defer cancel()
+// Migrations returns schema migrations for the migrate package
+func (s *SqLiteDB) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrations, "migrations")
+	return sub
+}
 
-	if _, err := s.db.ExecContext(ctx, q); err != nil {
-		return fmt.Errorf("init db error: %w", err)
+// SeedDictTables fills dictionary tables that never held a record. Tables having records or a history
+// in audit_log are skipped as a whole, so records deleted or renamed since are not brought back
+// and entries added to seed later are not applied either.
+// Records are created the way the API does, so every one of them is audited
+func (s *SqLiteDB) SeedDictTables(ctx context.Context, seed repos.DictSeed, l *slog.Logger) error {
+	ok, err := s.seedable(ctx, "doc_type")
+	if err != nil {
+		return fmt.Errorf("failed to seed doc_type: %w", err)
+	}
+	if !ok {
+		l.Info("doc_type holds records or had them, not seeded")
+	}
+	for i := 0; ok && i < len(seed.DocType); i++ {
+		val := seed.DocType[i]
+		// a concurrent seeding may have created the record already
+		_, err := s.DocTypeCreate(ctx, controllers.DocType{Id: val.Id, Doc: val.Doc}, l)
+		if err != nil && !errors.Is(err, controllers.ErrAlreadyExists) {
+			return fmt.Errorf("failed to seed doc_type [%d %s]: %w", val.Id, val.Doc, err)
+		}
 	}
 
+	ok, err = s.seedable(ctx, "animal_type")
+	if err != nil {
+		return fmt.Errorf("failed to seed animal_type: %w", err)
+	}
+	if !ok {
+		l.Info("animal_type holds records or had them, not seeded")
+	}
+	for i := 0; ok && i < len(seed.AnimalType); i++ {
+		val := seed.AnimalType[i]
+		_, err := s.AnimalTypeCreate(ctx, controllers.AnimalType{Id: val.Id, Type: val.Type}, l)
+		if err != nil && !errors.Is(err, controllers.ErrAlreadyExists) {
+			return fmt.Errorf("failed to seed animal_type [%d %s]: %w", val.Id, val.Type, err)
+		}
+	}
 	return nil
 }
 
-// ForceInitDictTables deletes everything dictionary tables and writes them with data
-func (s *SqLiteDB) ForceInitDictTables(timeout time.Duration) error {
-	var qList = []string{
-		"DELETE FROM doc_type;",
-		"DELETE FROM animal_type;",
-		"INSERT INTO doc_type (id,doc) VALUES (1, 'passport'),(2, 'veterinary passport'),(3, 'military passport');",
-		"INSERT INTO animal_type (id,type) VALUES (1, 'dog'),(2, 'cat');",
+// seedable reports whether table never held a record. table is one of the dictionary tables
+func (s *SqLiteDB) seedable(ctx context.Context, table string) (bool, error) {
+	found, err := s.exists(ctx, repos.DbReq{
+		Query: "SELECT 1 FROM " + table + " UNION ALL SELECT 1 FROM audit_log WHERE entity=?",
+		Args:  append(make([]any, 0), table),
+	})
+	return !found, err
+}
+
+// unavailable marks errors of a busy or locked database as temporary ones
+func unavailable(err error) error {
+	var sqliteErr driver.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == driver.ErrBusy || sqliteErr.Code == driver.ErrLocked) {
+		return fmt.Errorf("%w: %w", controllers.ErrUnavailable, err)
 	}
+	return repos.Unavailable(err)
+}
 
-	if s.db == nil {
-		return fmt.Errorf("db connection is not set")
+// violation marks errors of broken foreign keys and duplicated keys
+func violation(err error) error {
+	var sqliteErr driver.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == driver.ErrConstraintForeignKey {
+		return fmt.Errorf("%w: %w", controllers.ErrReference, err)
+	}
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == driver.ErrConstraintUnique || sqliteErr.ExtendedCode == driver.ErrConstraintPrimaryKey) {
+		return fmt.Errorf("%w: %w", controllers.ErrAlreadyExists, err)
 	}
+	return err
+}
 
-	for _, val := range qList {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Millisecond)
-		defer cancel()
+// exists reports whether the query yields at least one row
+func (s *SqLiteDB) exists(ctx context.Context, req repos.DbReq) (bool, error) {
+	rows, err := s.Get(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("bad DB query: %w", err)
+	}
+	defer rows.Close()
 
-		if _, err := s.db.ExecContext(ctx, val); err != nil {
-			return fmt.Errorf("failed to init dicts: %w", err)
-		}
+	return rows.Next(), rows.Err()
+}
+
+// nullString converts empty string to NULL
+func nullString(s string) any {
+	if s == "" {
+		return nil
 	}
+	return s
+}
 
-	return nil
+// placeholders returns n comma separated query placeholders for the IN clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// likePrefix escapes LIKE wildcards in s and turns it into a prefix pattern. Use with ESCAPE '\'
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
 }
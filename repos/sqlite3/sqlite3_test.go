@@ -0,0 +1,439 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mis-catanddog/controllers"
+	"mis-catanddog/repos"
+	"mis-catanddog/repos/migrate"
+	"mis-catanddog/repos/repotest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSqLiteDB(t *testing.T) {
+	var db = &SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+	if _, err := migrate.Up(context.Background(), db, slog.Default()); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+
+	repotest.Run(t, db)
+}
+
+// TestUniqueNames checks the db itself rejects duplicated names of dictionary records, concurrent creates
+// may both pass the check made ahead of the insert
+func TestUniqueNames(t *testing.T) {
+	var ctx = context.Background()
+	var l = slog.New(slog.NewTextHandler(io.Discard, nil))
+	var db = &SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+	if _, err := migrate.Up(ctx, db, l); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+	if err := db.SeedDictTables(ctx, repotest.Seed, l); err != nil {
+		t.Fatalf("failed to seed db: %s", err.Error())
+	}
+
+	for _, val := range []string{"INSERT INTO doc_type (id, doc) VALUES (10, 'passport')", "INSERT INTO animal_type (id, type) VALUES (10, 'dog')"} {
+		if err := db.exec(ctx, []repos.DbReq{{Query: val}}); !errors.Is(err, controllers.ErrAlreadyExists) {
+			t.Fatalf("%s: got %v, expected ErrAlreadyExists", val, err)
+		}
+	}
+}
+
+// TestForeignKeys checks enforcement is on for every connection of the pool, not just the first one
+func TestForeignKeys(t *testing.T) {
+	var ctx = context.Background()
+	var uri = "file:" + filepath.Join(t.TempDir(), "test.sqlite")
+	var db = &SqLiteDB{}
+
+	if err := db.New(uri, time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+
+	var conns []*sql.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := db.db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("failed to get connection: %s", err.Error())
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	for i, val := range conns {
+		var fk bool
+		if err := val.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fk); err != nil || !fk {
+			t.Fatalf("connection %d: foreign keys got %t, %v", i, fk, err)
+		}
+	}
+
+	// enforcement can't be turned off by the uri
+	var off = &SqLiteDB{}
+	if err := off.New(uri+"?_foreign_keys=0", time.Second); err == nil {
+		off.Close()
+		t.Fatalf("New with foreign keys disabled: got no error")
+	}
+}
+
+// TestConsistency checks records written with foreign keys off are found and the repairable ones are fixed
+func TestConsistency(t *testing.T) {
+	var ctx = controllers.WithActor(context.Background(), controllers.Actor{Subject: "cli:test"})
+	var l = slog.Default()
+	var db = &SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+	if _, err := migrate.Up(ctx, db, l); err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+
+	// the way records got broken before the enforcement
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %s", err.Error())
+	}
+	for _, query := range []string{
+		"PRAGMA foreign_keys=OFF",
+		"INSERT INTO doc_type (id, doc) VALUES (1, 'passport')",
+		"INSERT INTO animal_type (id, type) VALUES (1, 'dog')",
+		"INSERT INTO human (doc_id, doc_type, first_name, last_name, birth_date) VALUES (1, 1, 'Ivan', 'Petrov', '1990-01-02')",
+		"INSERT INTO human (doc_id, doc_type, first_name, last_name, birth_date) VALUES (2, 7, 'Petr', 'Ivanov', julianday('1985-01-01'))",
+		"INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (10, 1, 'Stray', '2020-01-01', 1, 'mongrel', 99)",
+		"INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (11, 1, 'Kesha', julianday('2021-01-01'), 5, 'parrot', 1)",
+		"INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (12, 'abc', 'Rex', julianday('2022-01-01'), 1, 'husky', 1)",
+		"PRAGMA foreign_keys=ON",
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatalf("%s: %s", query, err.Error())
+		}
+	}
+	conn.Close()
+
+	issues, err := db.CheckConsistency(ctx, l)
+	if err != nil {
+		t.Fatalf("CheckConsistency: %v", err)
+	}
+	var repairable int
+	for _, val := range issues {
+		if val.Repair != "" {
+			repairable++
+		}
+	}
+	if len(issues) != 7 || repairable != 5 {
+		t.Fatalf("CheckConsistency: got %d issues, %d repairable; expected 7, 5: %+v", len(issues), repairable, issues)
+	}
+
+	if _, err := db.RepairConsistency(ctx, l); err != nil {
+		t.Fatalf("RepairConsistency: %v", err)
+	}
+	// animal 12 holds text in doc_type, it is left as is
+	issues, err = db.CheckConsistency(ctx, l)
+	if err != nil || len(issues) != 2 || issues[0].EntityId != "12" || issues[1].EntityId != "12" {
+		t.Fatalf("CheckConsistency after repair: got %+v, %v", issues, err)
+	}
+
+	if _, err := db.AnimalGet(ctx, 10, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalGet of orphaned animal: got %v, expected ErrNotFound", err)
+	}
+	if got, err := db.DocTypeGetById(ctx, 7, l); err != nil || got.Doc != "unknown 7" {
+		t.Fatalf("DocTypeGetById of placeholder: got %+v, %v", got, err)
+	}
+	if got, err := db.HumanGet(ctx, 1, l); err != nil || got.BirthDate != "1990-01-02" {
+		t.Fatalf("HumanGet of converted birth_date: got %+v, %v", got, err)
+	}
+
+	// every fix is audited, deleted animals are kept in the log
+	q := controllers.ListQuery{Limit: 100, Sort: []controllers.SortField{{Field: "id"}}, Filters: []controllers.Filter{{Field: "actor", Op: controllers.OpEq, Value: "cli:test"}}}
+	entries, _, err := db.AuditList(ctx, q, l)
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("AuditList of repairs: got %+v, %v", entries, err)
+	}
+	for _, val := range entries {
+		if val.Entity == "animal" && (val.Action != repos.ActionDelete || !strings.Contains(string(val.Before), `"Name":"Stray"`)) {
+			t.Fatalf("audit of orphaned animal: got %+v", val)
+		}
+	}
+}
+
+// TestLegacyRepair checks a legacy db holding broken references is migrated, so the check subcommand can repair it.
+// A migration breaking references on its own is rolled back
+func TestLegacyRepair(t *testing.T) {
+	var ctx = controllers.WithActor(context.Background(), controllers.Actor{Subject: "cli:test"})
+	var l = slog.New(slog.NewTextHandler(io.Discard, nil))
+	var db = &SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	defer db.Close()
+	// the schema of the first release, owner_doc_id is text there
+	applied, err := migrate.Up(ctx, db, l)
+	if err != nil {
+		t.Fatalf("failed to migrate db: %s", err.Error())
+	}
+	for range applied[1:] {
+		if _, err := migrate.Down(ctx, db, l); err != nil {
+			t.Fatalf("failed to revert db: %s", err.Error())
+		}
+	}
+
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %s", err.Error())
+	}
+	for _, query := range []string{
+		"PRAGMA foreign_keys=OFF",
+		"INSERT INTO doc_type (id, doc) VALUES (1, 'passport')",
+		"INSERT INTO animal_type (id, type) VALUES (1, 'dog')",
+		"INSERT INTO human (doc_id, doc_type, first_name, last_name, birth_date) VALUES (1, 1, 'Ivan', 'Petrov', julianday('1990-01-02'))",
+		"INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (10, 1, 'Stray', julianday('2020-01-01'), 1, 'mongrel', '99')",
+		"INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (11, 1, 'Rex', julianday('2021-01-01'), 1, 'husky', '1')",
+		"PRAGMA foreign_keys=ON",
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatalf("%s: %s", query, err.Error())
+		}
+	}
+	conn.Close()
+
+	// the orphan is copied over by the rebuild of animal
+	if _, err := migrate.Up(ctx, db, l); err != nil {
+		t.Fatalf("Up of legacy db: %v", err)
+	}
+	issues, err := db.CheckConsistency(ctx, l)
+	if err != nil || len(issues) != 1 || issues[0].Problem != repos.ProblemOrphanedAnimal || issues[0].EntityId != "10" {
+		t.Fatalf("CheckConsistency of legacy db: got %+v, %v", issues, err)
+	}
+	if _, err := db.RepairConsistency(ctx, l); err != nil {
+		t.Fatalf("RepairConsistency: %v", err)
+	}
+	if issues, err := db.CheckConsistency(ctx, l); err != nil || len(issues) != 0 {
+		t.Fatalf("CheckConsistency after repair: got %+v, %v", issues, err)
+	}
+	if got, err := db.AnimalGet(ctx, 11, l); err != nil || got.OwnerDocId != 1 {
+		t.Fatalf("AnimalGet of migrated animal: got %+v, %v", got, err)
+	}
+
+	// enforcement is back on for every connection of the pool
+	var fk bool
+	if err := db.db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fk); err != nil || !fk {
+		t.Fatalf("foreign keys after migration: got %t, %v", fk, err)
+	}
+	err = db.ExecMigration(ctx, []repos.DbReq{{Query: "INSERT INTO animal (doc_id, doc_type, name, birth_date, animal_type, breed, owner_doc_id) VALUES (12, 1, 'Kesha', 0, 1, 'parrot', 98)"}})
+	if !errors.Is(err, controllers.ErrReference) {
+		t.Fatalf("migration breaking references: got %v, expected ErrReference", err)
+	}
+	if _, err := db.AnimalGet(ctx, 12, l); !errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("migration breaking references must be rolled back: got %v", err)
+	}
+}
+
+// TestOptions checks the tuning reaches every connection of the pool and options of the uri win
+func TestOptions(t *testing.T) {
+	var ctx = context.Background()
+	var uri = "file:" + filepath.Join(t.TempDir(), "test.sqlite")
+
+	tests := []struct {
+		Crit    bool
+		Message string
+		Uri     string
+		Opts    Options
+		Journal string
+		Sync    int
+		Busy    int
+		Cache   int
+	}{
+		{Crit: true, Message: "driver defaults", Uri: uri, Journal: "delete", Sync: 1, Busy: 5000, Cache: -2000},
+		{Crit: true, Message: "wal", Uri: uri, Opts: Options{JournalMode: "wal", Synchronous: "full", BusyTimeout: 250 * time.Millisecond, CacheSize: -4096, MaxOpenConns: 3, MaxIdleConns: 3}, Journal: "wal", Sync: 2, Busy: 250, Cache: -4096},
+		{Crit: true, Message: "uri wins", Uri: uri + "?_busy_timeout=100", Opts: Options{JournalMode: "wal", BusyTimeout: time.Second}, Journal: "wal", Sync: 1, Busy: 100, Cache: -2000},
+	}
+
+	var critFail bool
+	for _, val := range tests {
+		var db = &SqLiteDB{Opts: val.Opts}
+		if err := db.New(val.Uri, time.Second); err != nil {
+			t.Fatalf("%s: failed to open db: %s", val.Message, err.Error())
+		}
+
+		// hold several connections at once, so the pool has to open new ones
+		var conns []*sql.Conn
+		for i := 0; i < 3; i++ {
+			conn, err := db.db.Conn(ctx)
+			if err != nil {
+				t.Fatalf("%s: failed to get connection: %s", val.Message, err.Error())
+			}
+			conns = append(conns, conn)
+		}
+		for i, conn := range conns {
+			var journal string
+			var sync, busy, cache int
+			err := conn.QueryRowContext(ctx, "SELECT journal_mode, synchronous, timeout, cache_size FROM pragma_journal_mode, pragma_synchronous, pragma_busy_timeout, pragma_cache_size").Scan(&journal, &sync, &busy, &cache)
+			if err != nil || journal != val.Journal || sync != val.Sync || busy != val.Busy || cache != val.Cache {
+				t.Logf("crit: %t; %s; connection %d got %s %d %d %d, %v", val.Crit, val.Message, i, journal, sync, busy, cache, err)
+				critFail = critFail || val.Crit
+			}
+			conn.Close()
+		}
+		if val.Opts.MaxOpenConns > 0 && db.db.Stats().MaxOpenConnections != val.Opts.MaxOpenConns {
+			t.Logf("crit: %t; %s; got max open connections %d", val.Crit, val.Message, db.db.Stats().MaxOpenConnections)
+			critFail = critFail || val.Crit
+		}
+		db.Close()
+	}
+
+	if critFail {
+		t.Fatalf("Critical tests failed")
+	}
+}
+
+// TestConcurrentWriters checks writers of separate pools, like separate processes, wait for each other instead of failing busy
+func TestConcurrentWriters(t *testing.T) {
+	for _, mode := range []string{"delete", "wal"} {
+		if err := concurrentWrites(filepath.Join(t.TempDir(), "test.sqlite"), Options{JournalMode: mode, BusyTimeout: 5 * time.Second}); err != nil {
+			t.Fatalf("journal mode %s: %v", mode, err)
+		}
+	}
+}
+
+// concurrentWrites runs 200 writes from 2 pools of path at once and checks they all land
+func concurrentWrites(path string, opts Options) error {
+	var ctx = context.Background()
+
+	var dbs []*SqLiteDB
+	for i := 0; i < 2; i++ {
+		var db = &SqLiteDB{Opts: opts}
+		if err := db.New("file:"+path, time.Second); err != nil {
+			return fmt.Errorf("failed to open db: %w", err)
+		}
+		defer db.Close()
+		dbs = append(dbs, db)
+	}
+	if err := dbs[0].exec(ctx, []repos.DbReq{{Query: "CREATE TABLE counter (n INTEGER NOT NULL)"}}); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	var errs = make(chan error, 200)
+	for _, db := range dbs {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					// read first, so a deferred transaction would have to upgrade its lock
+					errs <- db.exec(ctx, []repos.DbReq{{Query: "SELECT count(*) FROM counter"}, {Query: "INSERT INTO counter (n) VALUES (?)", Args: append(make([]any, 0), j)}})
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return fmt.Errorf("concurrent write: %w", err)
+		}
+	}
+	var n int
+	if err := dbs[1].db.QueryRowContext(ctx, "SELECT count(*) FROM counter").Scan(&n); err != nil || n != 200 {
+		return fmt.Errorf("rows written: got %d, %v; expected 200", n, err)
+	}
+	return nil
+}
+
+// BenchmarkModes compares read and write throughput of journal modes and synchronous levels
+func BenchmarkModes(b *testing.B) {
+	var ctx = context.Background()
+	var l = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	modes := []Options{
+		{JournalMode: "delete", Synchronous: "full"},
+		{JournalMode: "delete", Synchronous: "normal"},
+		{JournalMode: "wal", Synchronous: "full"},
+		{JournalMode: "wal", Synchronous: "normal"},
+		{JournalMode: "wal", Synchronous: "off"},
+	}
+	for _, val := range modes {
+		var db = &SqLiteDB{Opts: val}
+		if err := db.New("file:"+filepath.Join(b.TempDir(), "bench.sqlite"), time.Second); err != nil {
+			b.Fatalf("failed to open db: %s", err.Error())
+		}
+		if _, err := migrate.Up(ctx, db, l); err != nil {
+			b.Fatalf("failed to migrate db: %s", err.Error())
+		}
+		if _, err := db.DocTypeCreate(ctx, controllers.DocType{Id: 1, Doc: "passport"}, l); err != nil {
+			b.Fatalf("DocTypeCreate: %v", err)
+		}
+		var docId atomic.Int64
+		var create = func() error {
+			h := controllers.Human{DocId: int(docId.Add(1)), DocType: 1, FirstName: "Ivan", LastName: "Petrov", BirthDate: "1980-01-01"}
+			_, err := db.HumanCreate(ctx, h, l)
+			return err
+		}
+		for i := 0; i < 100; i++ {
+			if err := create(); err != nil {
+				b.Fatalf("HumanCreate: %v", err)
+			}
+		}
+
+		name := val.JournalMode + "_" + val.Synchronous
+		b.Run(name+"/read", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 1; pb.Next(); i++ {
+					if _, err := db.HumanGet(ctx, i%100+1, l); err != nil {
+						b.Errorf("HumanGet: %v", err)
+						return
+					}
+				}
+			})
+		})
+		b.Run(name+"/write", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := create(); err != nil {
+						b.Errorf("HumanCreate: %v", err)
+						return
+					}
+				}
+			})
+		})
+		db.Close()
+	}
+}
+
+// TestLookupFailure checks a failing db is not reported as a missing record
+func TestLookupFailure(t *testing.T) {
+	var db = &SqLiteDB{}
+
+	if err := db.New("file:"+filepath.Join(t.TempDir(), "test.sqlite"), time.Second); err != nil {
+		t.Fatalf("failed to open db: %s", err.Error())
+	}
+	db.Close()
+
+	if _, err := db.AnimalTypeGetById(context.Background(), 1, slog.Default()); err == nil || errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalTypeGetById on closed db: got %v, expected a failure", err)
+	}
+	if _, err := db.AnimalTypeGetByType(context.Background(), "dog", slog.Default()); err == nil || errors.Is(err, controllers.ErrNotFound) {
+		t.Fatalf("AnimalTypeGetByType on closed db: got %v, expected a failure", err)
+	}
+}